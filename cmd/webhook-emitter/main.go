@@ -0,0 +1,65 @@
+// Command webhook-emitter sends a signed payment webhook to a running API,
+// standing in for a payment provider during local development. For example:
+//
+//	go run ./cmd/webhook-emitter -secret whsec_local -type payment.succeeded -order <order id>
+//
+// Sending the same -id twice shows that the event is only applied once.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/devbenho/luka-platform/internal/payments/webhooks"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func main() {
+	url := flag.String("url", "http://localhost:2707/api/v1/webhooks/payments/fake", "webhook route of the provider")
+	secret := flag.String("secret", "", "signing secret of the provider")
+	id := flag.String("id", "", "event ID; a new one is generated when empty")
+	eventType := flag.String("type", webhooks.EventPaymentSucceeded, "event type")
+	orderID := flag.String("order", "", "ID of the order the payment is for")
+	providerRef := flag.String("ref", "", "provider reference of the payment")
+	amount := flag.String("amount", "", "amount, e.g. 12.50; defaults to the whole payment")
+	currency := flag.String("currency", "USD", "currency of -amount")
+	reason := flag.String("reason", "", "failure reason")
+	flag.Parse()
+
+	if *secret == "" {
+		log.Fatal("-secret is required")
+	}
+	if *id == "" {
+		*id = "evt_" + primitive.NewObjectID().Hex()
+	}
+
+	event := webhooks.Event{
+		ID:   *id,
+		Type: *eventType,
+		Data: webhooks.EventData{
+			ProviderRef: *providerRef,
+			Reason:      *reason,
+		},
+	}
+	if *orderID != "" {
+		objID, err := primitive.ObjectIDFromHex(*orderID)
+		if err != nil {
+			log.Fatalf("Invalid order ID: %v", err)
+		}
+		event.Data.OrderID = objID
+	}
+	if *amount != "" {
+		value, err := money.Parse(*amount, *currency)
+		if err != nil {
+			log.Fatalf("Invalid amount: %v", err)
+		}
+		event.Data.Amount = &value
+	}
+
+	if err := webhooks.NewEmitter(*url, *secret).Emit(context.Background(), event); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Event %s delivered", event.ID)
+}
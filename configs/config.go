@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,8 +22,50 @@ type Config struct {
 	}
 
 	JWT struct {
-		Secret string
-		Type   string
+		Type string
+		// Algorithm is what generated keys sign with, RS256 or EdDSA. Keys
+		// read from KeysDir sign with the algorithm of their type.
+		Algorithm string
+		// KeysDir holds the PEM private keys tokens are signed with. Keys
+		// are generated in memory when it is empty, outside production.
+		KeysDir string
+		// KeyRotation is how often a new key is generated, and
+		// KeyPublishLead how long a new key is published before it signs.
+		KeyRotation    time.Duration
+		KeyPublishLead time.Duration
+		// Issuer and Audience are set on every token and required when
+		// validating one. ClockSkew is how far clocks may disagree.
+		Issuer    string
+		Audience  string
+		ClockSkew time.Duration
+		// AccessTTL is how long an access token is accepted; RefreshTTL is
+		// how long a refresh token can be exchanged for a new pair.
+		AccessTTL  time.Duration
+		RefreshTTL time.Duration
+	}
+	Reservations struct {
+		TTL           time.Duration
+		SweepInterval time.Duration
+	}
+	Idempotency struct {
+		TTL time.Duration
+	}
+	Payments struct {
+		Provider string
+		// WebhookSecrets holds the signing secret of each provider allowed
+		// to send payment webhooks.
+		WebhookSecrets   map[string]string
+		WebhookTolerance time.Duration
+		// ReversalInterval is how often the payments of cancelled orders
+		// that could not be given back are retried.
+		ReversalInterval time.Duration
+		// RefundClaimTimeout is how long a refund may go unsettled before
+		// it is looked up at the provider.
+		RefundClaimTimeout time.Duration
+	}
+	Analytics struct {
+		// CacheTTL is how long a store's dashboard is served from memory.
+		CacheTTL time.Duration
 	}
 	ALLOWED_ORIGINS string
 }
@@ -41,10 +85,27 @@ func LoadConfig() (*Config, error) {
 	config.App.Port = os.Getenv("PORT")
 	config.Database.URI = os.Getenv("MONGO_URI")
 	config.Database.Name = os.Getenv("DB_NAME")
-	config.JWT.Secret = os.Getenv("JWT_SECRET")
 	config.JWT.Type = os.Getenv("JWT_TYPE")
+	config.JWT.AccessTTL = getDuration("JWT_ACCESS_TTL", 15*time.Minute)
+	config.JWT.RefreshTTL = getDuration("JWT_REFRESH_TTL", 30*24*time.Hour)
+	config.JWT.Algorithm = getString("JWT_ALGORITHM", "EdDSA")
+	config.JWT.KeysDir = os.Getenv("JWT_KEYS_DIR")
+	config.JWT.KeyRotation = getDuration("JWT_KEY_ROTATION", 24*time.Hour)
+	config.JWT.KeyPublishLead = getDuration("JWT_KEY_PUBLISH_LEAD", 10*time.Minute)
+	config.JWT.Issuer = getString("JWT_ISSUER", "luka-platform")
+	config.JWT.Audience = getString("JWT_AUDIENCE", "luka-platform-api")
+	config.JWT.ClockSkew = getDuration("JWT_CLOCK_SKEW", 30*time.Second)
 	config.ALLOWED_ORIGINS = os.Getenv("ALLOWED_ORIGINS")
-	if config.App.Port == "" || config.Database.URI == "" || config.Database.Name == "" || config.JWT.Secret == "" {
+	config.Reservations.TTL = getDuration("RESERVATION_TTL", 15*time.Minute)
+	config.Reservations.SweepInterval = getDuration("RESERVATION_SWEEP_INTERVAL", time.Minute)
+	config.Idempotency.TTL = getDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+	config.Payments.Provider = os.Getenv("PAYMENT_PROVIDER")
+	config.Payments.WebhookSecrets = getPairs("PAYMENT_WEBHOOK_SECRETS")
+	config.Payments.WebhookTolerance = getDuration("PAYMENT_WEBHOOK_TOLERANCE", 5*time.Minute)
+	config.Payments.ReversalInterval = getDuration("PAYMENT_REVERSAL_INTERVAL", time.Minute)
+	config.Payments.RefundClaimTimeout = getDuration("PAYMENT_REFUND_CLAIM_TIMEOUT", 5*time.Minute)
+	config.Analytics.CacheTTL = getDuration("ANALYTICS_CACHE_TTL", time.Minute)
+	if config.App.Port == "" || config.Database.URI == "" || config.Database.Name == "" {
 		return &config, fmt.Errorf("missing required environment variables")
 	}
 
@@ -57,3 +118,41 @@ func GetConfig() *Config {
 	config, _ := LoadConfig()
 	return config
 }
+
+// getString reads a variable from the environment, falling back to the given
+// default when it is unset.
+func getString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getDuration reads a duration such as "15m" from the environment, falling
+// back to the given default when the variable is unset or malformed.
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s, using %s: %s", key, fallback, err)
+		return fallback
+	}
+	return duration
+}
+
+// getPairs reads comma-separated key=value pairs such as
+// "fake=secret1,stripe=secret2" from the environment.
+func getPairs(key string) map[string]string {
+	pairs := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			continue
+		}
+		pairs[name] = value
+	}
+	return pairs
+}
@@ -0,0 +1,12 @@
+package dtos
+
+import "time"
+
+// StoreAnalyticsQuery picks the date range of a dashboard and how finely its
+// sales are bucketed. The range defaults to the last 30 days.
+type StoreAnalyticsQuery struct {
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Interval string    `form:"interval" validate:"omitempty,oneof=day week month"`
+	Top      int       `form:"top" validate:"omitempty,min=1,max=50"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Interval string
+
+const (
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week"
+	IntervalMonth Interval = "month"
+)
+
+// SalesBucket is the revenue earned in one currency during one period. Only
+// orders whose payment was captured are counted as sales.
+type SalesBucket struct {
+	Period   time.Time   `bson:"period" json:"period"`
+	Currency string      `bson:"currency" json:"currency"`
+	Orders   int64       `bson:"orders" json:"orders"`
+	Revenue  money.Money `bson:"-" json:"revenue"`
+	Amount   int64       `bson:"amount" json:"-"`
+}
+
+// CurrencyTotals sums the sales of a store in one currency.
+type CurrencyTotals struct {
+	Currency          string      `bson:"_id" json:"currency"`
+	Orders            int64       `bson:"orders" json:"orders"`
+	Amount            int64       `bson:"amount" json:"-"`
+	Revenue           money.Money `bson:"-" json:"revenue"`
+	AverageOrderValue money.Money `bson:"-" json:"average_order_value"`
+}
+
+// ProductSales is what one product sold in one currency.
+type ProductSales struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Currency  string             `bson:"currency" json:"currency"`
+	Units     int64              `bson:"units" json:"units"`
+	Amount    int64              `bson:"amount" json:"-"`
+	Revenue   money.Money        `bson:"-" json:"revenue"`
+}
+
+// OrderCounts counts the orders placed with a store and how many of them were
+// cancelled.
+type OrderCounts struct {
+	Placed    int64 `bson:"placed" json:"placed"`
+	Cancelled int64 `bson:"cancelled" json:"cancelled"`
+}
+
+// StockLevels counts the inventories of a store that need restocking.
+type StockLevels struct {
+	LowStock   int64 `bson:"low_stock" json:"low_stock"`
+	OutOfStock int64 `bson:"out_of_stock" json:"out_of_stock"`
+}
+
+// StoreAnalytics is the sales dashboard of a store over a date range.
+type StoreAnalytics struct {
+	StoreID          primitive.ObjectID `json:"store_id"`
+	From             time.Time          `json:"from"`
+	To               time.Time          `json:"to"`
+	Interval         Interval           `json:"interval"`
+	Sales            []SalesBucket      `json:"sales"`
+	Totals           []CurrencyTotals   `json:"totals"`
+	Orders           OrderCounts        `json:"orders"`
+	CancellationRate float64            `json:"cancellation_rate"`
+	TopByUnits       []ProductSales     `json:"top_products_by_units"`
+	TopByRevenue     []ProductSales     `json:"top_products_by_revenue"`
+	Stock            StockLevels        `json:"stock"`
+	GeneratedAt      time.Time          `json:"generated_at"`
+}
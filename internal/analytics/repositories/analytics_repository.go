@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/analytics/models"
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// soldStatuses are the statuses of orders whose payment was captured.
+var soldStatuses = []orderModels.OrderStatus{
+	orderModels.OrderStatusProcessing,
+	orderModels.OrderStatusShipped,
+	orderModels.OrderStatusDelivered,
+	orderModels.OrderStatusPartiallyReturned,
+	orderModels.OrderStatusReturned,
+}
+
+type IAnalyticsRepository interface {
+	SalesOverTime(ctx context.Context, storeID primitive.ObjectID, from, to time.Time, interval models.Interval) ([]models.SalesBucket, error)
+	SalesTotals(ctx context.Context, storeID primitive.ObjectID, from, to time.Time) ([]models.CurrencyTotals, error)
+	CountOrders(ctx context.Context, storeID primitive.ObjectID, from, to time.Time) (models.OrderCounts, error)
+	TopProducts(ctx context.Context, storeID primitive.ObjectID, from, to time.Time, limit int) (byUnits, byRevenue []models.ProductSales, err error)
+	StockLevels(ctx context.Context, storeID primitive.ObjectID) (models.StockLevels, error)
+}
+
+type AnalyticsRepository struct {
+	db database.IDatabase
+}
+
+func NewAnalyticsRepository(db database.IDatabase) IAnalyticsRepository {
+	return &AnalyticsRepository{
+		db: db,
+	}
+}
+
+// SalesOverTime sums the sales of each period, starting periods on Mondays
+// for weekly buckets. Periods without sales are left out.
+func (r *AnalyticsRepository) SalesOverTime(ctx context.Context, storeID primitive.ObjectID, from, to time.Time, interval models.Interval) ([]models.SalesBucket, error) {
+	period := bson.M{"date": "$createdAt", "unit": string(interval)}
+	if interval == models.IntervalWeek {
+		period["startOfWeek"] = "monday"
+	}
+	pipeline := bson.A{
+		bson.M{"$match": soldMatch(storeID, from, to)},
+		bson.M{"$group": bson.M{
+			"_id":    bson.M{"period": bson.M{"$dateTrunc": period}, "currency": "$totalAmount.currency"},
+			"orders": bson.M{"$sum": 1},
+			"amount": bson.M{"$sum": "$totalAmount.amount"},
+		}},
+		bson.M{"$project": bson.M{"_id": 0, "period": "$_id.period", "currency": "$_id.currency", "orders": 1, "amount": 1}},
+		bson.M{"$sort": bson.D{{Key: "period", Value: 1}, {Key: "currency", Value: 1}}},
+	}
+
+	buckets := []models.SalesBucket{}
+	if err := r.db.Aggregate(ctx, "orders", pipeline, &buckets); err != nil {
+		return nil, fmt.Errorf("failed to aggregate sales: %w", err)
+	}
+	return buckets, nil
+}
+
+func (r *AnalyticsRepository) SalesTotals(ctx context.Context, storeID primitive.ObjectID, from, to time.Time) ([]models.CurrencyTotals, error) {
+	pipeline := bson.A{
+		bson.M{"$match": soldMatch(storeID, from, to)},
+		bson.M{"$group": bson.M{
+			"_id":    "$totalAmount.currency",
+			"orders": bson.M{"$sum": 1},
+			"amount": bson.M{"$sum": "$totalAmount.amount"},
+		}},
+		bson.M{"$sort": bson.M{"_id": 1}},
+	}
+
+	totals := []models.CurrencyTotals{}
+	if err := r.db.Aggregate(ctx, "orders", pipeline, &totals); err != nil {
+		return nil, fmt.Errorf("failed to aggregate sales totals: %w", err)
+	}
+	return totals, nil
+}
+
+// CountOrders counts every order placed in the range, whatever became of it.
+func (r *AnalyticsRepository) CountOrders(ctx context.Context, storeID primitive.ObjectID, from, to time.Time) (models.OrderCounts, error) {
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"storeID": storeID, "createdAt": bson.M{"$gte": from, "$lt": to}}},
+		bson.M{"$group": bson.M{
+			"_id":    nil,
+			"placed": bson.M{"$sum": 1},
+			"cancelled": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$eq": bson.A{"$status", orderModels.OrderStatusCancelled}}, 1, 0},
+			}},
+		}},
+	}
+
+	var counts []models.OrderCounts
+	if err := r.db.Aggregate(ctx, "orders", pipeline, &counts); err != nil {
+		return models.OrderCounts{}, fmt.Errorf("failed to count orders: %w", err)
+	}
+	if len(counts) == 0 {
+		return models.OrderCounts{}, nil
+	}
+	return counts[0], nil
+}
+
+// TopProducts ranks the products sold in the range by units and by revenue
+// net of discounts, in a single pass over the orders.
+func (r *AnalyticsRepository) TopProducts(ctx context.Context, storeID primitive.ObjectID, from, to time.Time, limit int) ([]models.ProductSales, []models.ProductSales, error) {
+	pipeline := bson.A{
+		bson.M{"$match": soldMatch(storeID, from, to)},
+		bson.M{"$unwind": "$items"},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"product_id": "$items.productID", "currency": "$items.totalPrice.currency"},
+			"units": bson.M{"$sum": "$items.quantity"},
+			"amount": bson.M{"$sum": bson.M{
+				"$subtract": bson.A{"$items.totalPrice.amount", bson.M{"$ifNull": bson.A{"$items.discountAmount.amount", 0}}},
+			}},
+		}},
+		bson.M{"$project": bson.M{"_id": 0, "product_id": "$_id.product_id", "currency": "$_id.currency", "units": 1, "amount": 1}},
+		bson.M{"$facet": bson.M{
+			"by_units": bson.A{
+				bson.M{"$sort": bson.D{{Key: "units", Value: -1}, {Key: "product_id", Value: 1}}},
+				bson.M{"$limit": limit},
+			},
+			"by_revenue": bson.A{
+				bson.M{"$sort": bson.D{{Key: "amount", Value: -1}, {Key: "product_id", Value: 1}}},
+				bson.M{"$limit": limit},
+			},
+		}},
+	}
+
+	var ranked []struct {
+		ByUnits   []models.ProductSales `bson:"by_units"`
+		ByRevenue []models.ProductSales `bson:"by_revenue"`
+	}
+	if err := r.db.Aggregate(ctx, "orders", pipeline, &ranked); err != nil {
+		return nil, nil, fmt.Errorf("failed to rank products: %w", err)
+	}
+	if len(ranked) == 0 {
+		return []models.ProductSales{}, []models.ProductSales{}, nil
+	}
+	return ranked[0].ByUnits, ranked[0].ByRevenue, nil
+}
+
+// StockLevels counts the store's inventories whose available stock is at or
+// below their minimum, and those with nothing left to sell.
+func (r *AnalyticsRepository) StockLevels(ctx context.Context, storeID primitive.ObjectID) (models.StockLevels, error) {
+	available := bson.M{"$subtract": bson.A{"$quantity", "$reserved_quantity"}}
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"store_id": storeID, "deleted_at": nil}},
+		bson.M{"$project": bson.M{"available": available, "min_quantity": 1}},
+		bson.M{"$group": bson.M{
+			"_id": nil,
+			"out_of_stock": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$lte": bson.A{"$available", 0}}, 1, 0},
+			}},
+			"low_stock": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$and": bson.A{
+					bson.M{"$gt": bson.A{"$available", 0}},
+					bson.M{"$lte": bson.A{"$available", "$min_quantity"}},
+				}}, 1, 0},
+			}},
+		}},
+	}
+
+	var levels []models.StockLevels
+	if err := r.db.Aggregate(ctx, "inventories", pipeline, &levels); err != nil {
+		return models.StockLevels{}, fmt.Errorf("failed to aggregate stock levels: %w", err)
+	}
+	if len(levels) == 0 {
+		return models.StockLevels{}, nil
+	}
+	return levels[0], nil
+}
+
+func soldMatch(storeID primitive.ObjectID, from, to time.Time) bson.M {
+	return bson.M{
+		"storeID":   storeID,
+		"createdAt": bson.M{"$gte": from, "$lt": to},
+		"status":    bson.M{"$in": soldStatuses},
+	}
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/analytics/dtos"
+	"github.com/devbenho/luka-platform/internal/analytics/models"
+	"github.com/devbenho/luka-platform/internal/analytics/repositories"
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	storeRepo "github.com/devbenho/luka-platform/internal/store/repositories"
+	"github.com/devbenho/luka-platform/pkg/cache"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultRange    = 30 * 24 * time.Hour
+	maxRange        = 2 * 366 * 24 * time.Hour
+	defaultTopCount = 10
+)
+
+type IAnalyticsService interface {
+	GetStoreAnalytics(ctx context.Context, storeID string, actor orderModels.Actor, query dtos.StoreAnalyticsQuery) (*models.StoreAnalytics, error)
+}
+
+// analyticsKey identifies a computed dashboard in the cache.
+type analyticsKey struct {
+	storeID  primitive.ObjectID
+	from, to int64
+	interval models.Interval
+	top      int
+}
+
+type AnalyticsService struct {
+	repo      repositories.IAnalyticsRepository
+	storeRepo storeRepo.IStoreRepository
+	validator *validation.Validator
+	cache     *cache.TTLCache[analyticsKey, *models.StoreAnalytics]
+}
+
+func NewAnalyticsService(
+	repo repositories.IAnalyticsRepository,
+	storeRepo storeRepo.IStoreRepository,
+	validator *validation.Validator,
+	cacheTTL time.Duration,
+) *AnalyticsService {
+	return &AnalyticsService{
+		repo:      repo,
+		storeRepo: storeRepo,
+		validator: validator,
+		cache:     cache.NewTTLCache[analyticsKey, *models.StoreAnalytics](cacheTTL),
+	}
+}
+
+// GetStoreAnalytics builds the sales dashboard of a store for its owner.
+// Dashboards are cached briefly, so recent orders may take up to the cache
+// TTL to show.
+func (s *AnalyticsService) GetStoreAnalytics(ctx context.Context, storeID string, actor orderModels.Actor, query dtos.StoreAnalyticsQuery) (*models.StoreAnalytics, error) {
+	if err := s.validator.ValidateStruct(query); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+
+	store, err := s.storeRepo.GetStoreByID(ctx, storeID)
+	if err != nil {
+		return nil, errors.NewNotFoundError("store", storeID)
+	}
+	if !actor.IsPrivileged() && actor.ID != store.OwnerId {
+		return nil, errors.NewError(errors.UnauthorizedType, 403, "only the store owner may view its analytics")
+	}
+
+	key, err := analyticsKeyFor(store.ID, query)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := s.cache.Get(key); ok {
+		return cached, nil
+	}
+
+	from, to := time.Unix(key.from, 0).UTC(), time.Unix(key.to, 0).UTC()
+	analytics := &models.StoreAnalytics{
+		StoreID:     store.ID,
+		From:        from,
+		To:          to,
+		Interval:    key.interval,
+		GeneratedAt: time.Now(),
+	}
+	if analytics.Sales, err = s.repo.SalesOverTime(ctx, store.ID, from, to, key.interval); err != nil {
+		return nil, errors.Wrap(err, "aggregating sales")
+	}
+	if analytics.Totals, err = s.repo.SalesTotals(ctx, store.ID, from, to); err != nil {
+		return nil, errors.Wrap(err, "aggregating sales totals")
+	}
+	if analytics.Orders, err = s.repo.CountOrders(ctx, store.ID, from, to); err != nil {
+		return nil, errors.Wrap(err, "counting orders")
+	}
+	if analytics.TopByUnits, analytics.TopByRevenue, err = s.repo.TopProducts(ctx, store.ID, from, to, key.top); err != nil {
+		return nil, errors.Wrap(err, "ranking products")
+	}
+	if analytics.Stock, err = s.repo.StockLevels(ctx, store.ID); err != nil {
+		return nil, errors.Wrap(err, "counting stock levels")
+	}
+
+	for i := range analytics.Sales {
+		bucket := &analytics.Sales[i]
+		bucket.Revenue = money.Money{Amount: bucket.Amount, Currency: bucket.Currency}
+	}
+	for i := range analytics.Totals {
+		totals := &analytics.Totals[i]
+		totals.Revenue = money.Money{Amount: totals.Amount, Currency: totals.Currency}
+		if totals.AverageOrderValue, err = totals.Revenue.MulFraction(1, totals.Orders); err != nil {
+			return nil, errors.Wrap(err, "averaging order value")
+		}
+	}
+	for _, products := range [][]models.ProductSales{analytics.TopByUnits, analytics.TopByRevenue} {
+		for i := range products {
+			products[i].Revenue = money.Money{Amount: products[i].Amount, Currency: products[i].Currency}
+		}
+	}
+	if analytics.Orders.Placed > 0 {
+		analytics.CancellationRate = float64(analytics.Orders.Cancelled) / float64(analytics.Orders.Placed)
+	}
+
+	s.cache.Set(key, analytics)
+	return analytics, nil
+}
+
+// analyticsKeyFor applies the query defaults. An open range ends at the next
+// minute so that repeated requests share a cache entry.
+func analyticsKeyFor(storeID primitive.ObjectID, query dtos.StoreAnalyticsQuery) (analyticsKey, error) {
+	to := query.To
+	if to.IsZero() {
+		to = time.Now().Truncate(time.Minute).Add(time.Minute)
+	}
+	from := query.From
+	if from.IsZero() {
+		from = to.Add(-defaultRange)
+	}
+	if !from.Before(to) {
+		return analyticsKey{}, errors.NewBadRequestError("from must be before to")
+	}
+	if to.Sub(from) > maxRange {
+		return analyticsKey{}, errors.NewBadRequestError("the date range cannot exceed two years")
+	}
+
+	key := analyticsKey{
+		storeID:  storeID,
+		from:     from.Unix(),
+		to:       to.Unix(),
+		interval: models.Interval(query.Interval),
+		top:      query.Top,
+	}
+	if key.interval == "" {
+		key.interval = models.IntervalDay
+	}
+	if key.top == 0 {
+		key.top = defaultTopCount
+	}
+	return key, nil
+}
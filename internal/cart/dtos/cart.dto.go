@@ -0,0 +1,45 @@
+package dtos
+
+import (
+	"github.com/devbenho/luka-platform/internal/cart/models"
+	shippingModels "github.com/devbenho/luka-platform/internal/shipping/models"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AddCartItemRequest struct {
+	ProductID primitive.ObjectID `json:"product_id" validate:"required"`
+	Quantity  int                `json:"quantity" validate:"required,gt=0"`
+}
+
+type UpdateCartItemRequest struct {
+	// Quantity replaces the current quantity; zero removes the item.
+	Quantity int `json:"quantity" validate:"gte=0"`
+}
+
+type CheckoutCartRequest struct {
+	ShippingAddress   shippingModels.Address `json:"shipping_address" validate:"required"`
+	ShippingMethodIDs []primitive.ObjectID   `json:"shipping_method_ids"`
+	Notes             string                 `json:"notes"`
+	CouponCode        string                 `json:"coupon_code" validate:"omitempty,max=50"`
+	// AcceptPriceChanges confirms the buyer has seen prices that changed
+	// since the items were added. Without it such a checkout is refused.
+	AcceptPriceChanges bool `json:"accept_price_changes"`
+}
+
+// CartResponse is a cart with every item checked against the current price
+// and stock.
+type CartResponse struct {
+	models.Cart
+	Items []CartItemResponse `json:"items"`
+	Total money.Money        `json:"total"`
+}
+
+type CartItemResponse struct {
+	models.CartItem
+	CurrentPrice money.Money `json:"current_price"`
+	PriceChanged bool        `json:"price_changed"`
+	LineTotal    money.Money `json:"line_total"`
+	Available    int         `json:"available"`
+	InStock      bool        `json:"in_stock"`
+}
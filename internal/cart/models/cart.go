@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cart is a buyer's server-side shopping cart. A cart belongs either to a
+// signed-in user or, before sign-in, to a guest identified by an opaque token.
+type Cart struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID     *primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	GuestToken string              `bson:"guest_token,omitempty" json:"guest_token,omitempty"`
+	Items      []CartItem          `bson:"items" json:"items"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// CartItem is a product in the cart. PriceSnapshot is the price when the
+// product was added, so the buyer can be told when it changed since.
+type CartItem struct {
+	ProductID     primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Quantity      int                `bson:"quantity" json:"quantity"`
+	PriceSnapshot money.Money        `bson:"price_snapshot" json:"price_snapshot"`
+	AddedAt       time.Time          `bson:"added_at" json:"added_at"`
+}
+
+// Owner identifies whose cart to use: a user when UserID is set, a guest
+// otherwise.
+type Owner struct {
+	UserID     primitive.ObjectID
+	GuestToken string
+}
+
+func (o Owner) IsGuest() bool {
+	return o.UserID.IsZero()
+}
+
+// IsZero reports whether the owner identifies nobody yet.
+func (o Owner) IsZero() bool {
+	return o.UserID.IsZero() && o.GuestToken == ""
+}
+
+// Item returns the cart line for a product, if any.
+func (c *Cart) Item(productID primitive.ObjectID) (int, bool) {
+	for i, item := range c.Items {
+		if item.ProductID == productID {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// RemoveItem drops the cart line for a product.
+func (c *Cart) RemoveItem(productID primitive.ObjectID) {
+	if i, ok := c.Item(productID); ok {
+		c.Items = append(c.Items[:i], c.Items[i+1:]...)
+	}
+}
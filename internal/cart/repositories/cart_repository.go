@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/cart/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ICartRepository interface {
+	FindCart(ctx context.Context, owner models.Owner) (*models.Cart, error)
+	// SaveCart fails with a duplicate key error, which
+	// mongo.IsDuplicateKeyError detects, when it creates a second cart for
+	// the same owner.
+	SaveCart(ctx context.Context, cart *models.Cart) error
+	DeleteCart(ctx context.Context, id primitive.ObjectID) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type CartRepository struct {
+	db database.IDatabase
+}
+
+func NewCartRepository(db database.IDatabase) ICartRepository {
+	return &CartRepository{
+		db: db,
+	}
+}
+
+// FindCart returns the owner's cart, or nil when they have none yet.
+func (r *CartRepository) FindCart(ctx context.Context, owner models.Owner) (*models.Cart, error) {
+	filter := bson.M{"guest_token": owner.GuestToken, "user_id": nil}
+	if !owner.IsGuest() {
+		filter = bson.M{"user_id": owner.UserID}
+	}
+
+	var cart models.Cart
+	if err := r.db.FindOne(ctx, "carts", filter, &cart); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cart from db: %w", err)
+	}
+	return &cart, nil
+}
+
+// SaveCart creates the cart on first save and replaces its items afterwards.
+func (r *CartRepository) SaveCart(ctx context.Context, cart *models.Cart) error {
+	cart.UpdatedAt = time.Now()
+	if cart.ID.IsZero() {
+		cart.ID = primitive.NewObjectID()
+		cart.CreatedAt = cart.UpdatedAt
+		if err := r.db.Create(ctx, "carts", cart); err != nil {
+			return fmt.Errorf("failed to create cart in db: %w", err)
+		}
+		return nil
+	}
+
+	filter := bson.M{"_id": cart.ID}
+	update := bson.M{"$set": bson.M{
+		"items":      cart.Items,
+		"updated_at": cart.UpdatedAt,
+	}}
+	if err := r.db.Update(ctx, "carts", filter, update); err != nil {
+		return fmt.Errorf("failed to update cart in db: %w", err)
+	}
+	return nil
+}
+
+func (r *CartRepository) DeleteCart(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id}
+	if err := r.db.Delete(ctx, "carts", filter); err != nil {
+		return fmt.Errorf("failed to delete cart from db: %w", err)
+	}
+	return nil
+}
+
+// EnsureIndexes keeps each user and each guest token to a single cart.
+func (r *CartRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, database.DatabaseTimeout)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"user_id": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{{Key: "guest_token", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"guest_token": bson.M{"$exists": true}}),
+		},
+	}
+	if _, err := r.db.GetDB().Collection("carts").Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("failed to create cart indexes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+
+	"github.com/devbenho/luka-platform/internal/cart/models"
+	"github.com/devbenho/luka-platform/internal/cart/repositories"
+	inventorySvc "github.com/devbenho/luka-platform/internal/inventory/services"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CartMerger moves a guest's cart into their own cart once they sign in.
+type CartMerger struct {
+	db               database.IDatabase
+	repo             repositories.ICartRepository
+	inventoryService inventorySvc.IInventoryService
+}
+
+func NewCartMerger(db database.IDatabase, repo repositories.ICartRepository, inventoryService inventorySvc.IInventoryService) *CartMerger {
+	return &CartMerger{
+		db:               db,
+		repo:             repo,
+		inventoryService: inventoryService,
+	}
+}
+
+// MergeGuestCart adds the items of the guest cart to the user's cart and
+// deletes the guest cart. Quantities of products in both carts are added up
+// as far as the stock allows, never dropping below what the user already
+// had; the user's price snapshot is kept. Unknown tokens are ignored.
+func (m *CartMerger) MergeGuestCart(ctx context.Context, userID primitive.ObjectID, guestToken string) error {
+	if guestToken == "" {
+		return nil
+	}
+	return m.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		guestCart, err := m.repo.FindCart(sessCtx, models.Owner{GuestToken: guestToken})
+		if err != nil {
+			return errors.Wrap(err, "fetching guest cart")
+		}
+		if guestCart == nil {
+			return nil
+		}
+
+		userCart, err := m.repo.FindCart(sessCtx, models.Owner{UserID: userID})
+		if err != nil {
+			return errors.Wrap(err, "fetching cart")
+		}
+		if userCart == nil {
+			userCart = &models.Cart{UserID: &userID}
+		}
+
+		for _, item := range guestCart.Items {
+			if i, ok := userCart.Item(item.ProductID); ok {
+				quantity, err := m.mergedQuantity(sessCtx, item.ProductID, userCart.Items[i].Quantity, item.Quantity)
+				if err != nil {
+					return err
+				}
+				userCart.Items[i].Quantity = quantity
+				continue
+			}
+			userCart.Items = append(userCart.Items, item)
+		}
+
+		if err := m.repo.SaveCart(sessCtx, userCart); err != nil {
+			return errors.Wrap(err, "saving cart")
+		}
+		if err := m.repo.DeleteCart(sessCtx, guestCart.ID); err != nil {
+			return errors.Wrap(err, "deleting guest cart")
+		}
+		return nil
+	})
+}
+
+// mergedQuantity adds the guest quantity of a product to the user's, capped
+// at the available stock but never below the user's own quantity.
+func (m *CartMerger) mergedQuantity(ctx context.Context, productID primitive.ObjectID, user, guest int) (int, error) {
+	available, err := m.inventoryService.GetAvailableQuantity(ctx, productID)
+	if err != nil {
+		return 0, errors.Wrap(err, "checking available stock")
+	}
+	quantity := user + guest
+	if quantity > available {
+		quantity = max(user, available)
+	}
+	return quantity, nil
+}
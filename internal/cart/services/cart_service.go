@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/cart/dtos"
+	"github.com/devbenho/luka-platform/internal/cart/models"
+	"github.com/devbenho/luka-platform/internal/cart/repositories"
+	inventorySvc "github.com/devbenho/luka-platform/internal/inventory/services"
+	orderDtos "github.com/devbenho/luka-platform/internal/orders/order_dtos"
+	orderSvc "github.com/devbenho/luka-platform/internal/orders/services"
+	productSvc "github.com/devbenho/luka-platform/internal/product/services"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ICartService interface {
+	GetCart(ctx context.Context, owner models.Owner) (*dtos.CartResponse, error)
+	AddItem(ctx context.Context, owner models.Owner, dto dtos.AddCartItemRequest) (*dtos.CartResponse, error)
+	UpdateItem(ctx context.Context, owner models.Owner, productID string, dto dtos.UpdateCartItemRequest) (*dtos.CartResponse, error)
+	RemoveItem(ctx context.Context, owner models.Owner, productID string) (*dtos.CartResponse, error)
+	Checkout(ctx context.Context, owner models.Owner, dto dtos.CheckoutCartRequest) (*orderDtos.CheckoutResponse, error)
+}
+
+type CartService struct {
+	db               database.IDatabase
+	repo             repositories.ICartRepository
+	productService   productSvc.IProductService
+	inventoryService inventorySvc.IInventoryService
+	orderService     orderSvc.IOrderService
+	validator        *validation.Validator
+}
+
+func NewCartService(
+	db database.IDatabase,
+	repo repositories.ICartRepository,
+	productService productSvc.IProductService,
+	inventoryService inventorySvc.IInventoryService,
+	orderService orderSvc.IOrderService,
+	validator *validation.Validator,
+) *CartService {
+	return &CartService{
+		db:               db,
+		repo:             repo,
+		productService:   productService,
+		inventoryService: inventoryService,
+		orderService:     orderService,
+		validator:        validator,
+	}
+}
+
+func (s *CartService) GetCart(ctx context.Context, owner models.Owner) (*dtos.CartResponse, error) {
+	cart, err := s.findCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	return s.describe(ctx, cart)
+}
+
+// AddItem puts a product in the cart, or adds to its quantity if it is
+// already there. A guest without a cart gets a new one, whose token is
+// returned with the cart.
+func (s *CartService) AddItem(ctx context.Context, owner models.Owner, dto dtos.AddCartItemRequest) (*dtos.CartResponse, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+
+	product, err := s.productService.GetProductByID(ctx, dto.ProductID.Hex())
+	if err != nil {
+		return nil, errors.NewNotFoundError("product", dto.ProductID.Hex())
+	}
+
+	cart, err := s.findCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := dto.Quantity
+	i, ok := cart.Item(dto.ProductID)
+	if ok {
+		quantity += cart.Items[i].Quantity
+	}
+	if err := s.checkStock(ctx, dto.ProductID, quantity); err != nil {
+		return nil, err
+	}
+
+	if ok {
+		cart.Items[i].Quantity = quantity
+	} else {
+		cart.Items = append(cart.Items, models.CartItem{
+			ProductID:     dto.ProductID,
+			Quantity:      quantity,
+			PriceSnapshot: product.Price,
+			AddedAt:       time.Now(),
+		})
+	}
+
+	if err := s.repo.SaveCart(ctx, cart); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.NewConflictError("cart was created concurrently, please retry")
+		}
+		return nil, errors.Wrap(err, "saving cart")
+	}
+	return s.describe(ctx, cart)
+}
+
+func (s *CartService) UpdateItem(ctx context.Context, owner models.Owner, productID string, dto dtos.UpdateCartItemRequest) (*dtos.CartResponse, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+	if dto.Quantity == 0 {
+		return s.RemoveItem(ctx, owner, productID)
+	}
+
+	cart, i, err := s.findItem(ctx, owner, productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkStock(ctx, cart.Items[i].ProductID, dto.Quantity); err != nil {
+		return nil, err
+	}
+
+	cart.Items[i].Quantity = dto.Quantity
+	if err := s.repo.SaveCart(ctx, cart); err != nil {
+		return nil, errors.Wrap(err, "saving cart")
+	}
+	return s.describe(ctx, cart)
+}
+
+func (s *CartService) RemoveItem(ctx context.Context, owner models.Owner, productID string) (*dtos.CartResponse, error) {
+	cart, i, err := s.findItem(ctx, owner, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart.RemoveItem(cart.Items[i].ProductID)
+	if err := s.repo.SaveCart(ctx, cart); err != nil {
+		return nil, errors.Wrap(err, "saving cart")
+	}
+	return s.describe(ctx, cart)
+}
+
+// Checkout places the signed-in user's cart as an order and empties the cart.
+// Orders are placed at current prices, so a checkout is refused while prices
+// changed since the items were added unless the buyer accepts the changes.
+func (s *CartService) Checkout(ctx context.Context, owner models.Owner, dto dtos.CheckoutCartRequest) (*orderDtos.CheckoutResponse, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+	if owner.IsGuest() {
+		return nil, errors.NewUnauthorizedError("sign in to check out")
+	}
+
+	cart, err := s.findCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, errors.NewBadRequestError("cart is empty")
+	}
+
+	view, err := s.describe(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+	var changed []primitive.ObjectID
+	for _, item := range view.Items {
+		if item.PriceChanged {
+			changed = append(changed, item.ProductID)
+		}
+	}
+	if len(changed) > 0 && !dto.AcceptPriceChanges {
+		return nil, errors.NewError(
+			errors.ConflictType,
+			409,
+			fmt.Sprintf("prices of %d item(s) changed since they were added to the cart", len(changed)),
+			errors.WithMetadata(map[string]interface{}{
+				"product_ids": changed,
+			}),
+		)
+	}
+
+	request := orderDtos.CreateOrderRequest{
+		CustomerID:        owner.UserID,
+		ShippingAddress:   dto.ShippingAddress,
+		ShippingMethodIDs: dto.ShippingMethodIDs,
+		Notes:             dto.Notes,
+		CouponCode:        dto.CouponCode,
+	}
+	for _, item := range cart.Items {
+		request.Items = append(request.Items, orderDtos.CreateOrderItemRequest{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	// The cart is emptied in the transaction that places the order, so that
+	// a retried checkout finds either an empty cart or no order at all.
+	var placed *orderDtos.CheckoutResponse
+	err = s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		placed, err = s.orderService.CreateOrder(sessCtx, request)
+		if err != nil {
+			return err
+		}
+		cart.Items = []models.CartItem{}
+		if err := s.repo.SaveCart(sessCtx, cart); err != nil {
+			return errors.Wrap(err, "emptying cart")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return placed, nil
+}
+
+// findCart returns the owner's cart, or a new unsaved one if they have none.
+func (s *CartService) findCart(ctx context.Context, owner models.Owner) (*models.Cart, error) {
+	if owner.IsZero() {
+		token, err := newGuestToken()
+		if err != nil {
+			return nil, errors.Wrap(err, "creating cart")
+		}
+		return &models.Cart{GuestToken: token, Items: []models.CartItem{}}, nil
+	}
+
+	cart, err := s.repo.FindCart(ctx, owner)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching cart")
+	}
+	if cart != nil {
+		return cart, nil
+	}
+	cart = &models.Cart{GuestToken: owner.GuestToken, Items: []models.CartItem{}}
+	if !owner.IsGuest() {
+		cart.UserID = &owner.UserID
+		cart.GuestToken = ""
+	}
+	return cart, nil
+}
+
+func (s *CartService) findItem(ctx context.Context, owner models.Owner, productID string) (*models.Cart, int, error) {
+	id, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, 0, errors.NewBadRequestError("invalid product ID")
+	}
+	cart, err := s.findCart(ctx, owner)
+	if err != nil {
+		return nil, 0, err
+	}
+	i, ok := cart.Item(id)
+	if !ok {
+		return nil, 0, errors.NewNotFoundError("cart item", productID)
+	}
+	return cart, i, nil
+}
+
+func (s *CartService) checkStock(ctx context.Context, productID primitive.ObjectID, quantity int) error {
+	available, err := s.inventoryService.GetAvailableQuantity(ctx, productID)
+	if err != nil {
+		return errors.Wrap(err, "checking available stock")
+	}
+	if available < quantity {
+		return errors.NewError(
+			errors.InsufficientStock,
+			409,
+			"not enough stock for the requested quantity",
+			errors.WithMetadata(map[string]interface{}{
+				"product_id": productID,
+				"requested":  quantity,
+				"available":  available,
+			}),
+		)
+	}
+	return nil
+}
+
+// describe prices the cart at current prices and reports, per item, whether
+// the price changed and whether enough stock is available. Products that no
+// longer exist are reported as out of stock at their snapshot price.
+func (s *CartService) describe(ctx context.Context, cart *models.Cart) (*dtos.CartResponse, error) {
+	response := &dtos.CartResponse{Cart: *cart, Items: make([]dtos.CartItemResponse, 0, len(cart.Items))}
+	lineTotals := make([]money.Money, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		line := dtos.CartItemResponse{CartItem: item, CurrentPrice: item.PriceSnapshot}
+
+		product, err := s.productService.GetProductByID(ctx, item.ProductID.Hex())
+		if err == nil {
+			line.CurrentPrice = product.Price
+			line.PriceChanged = product.Price != item.PriceSnapshot
+			if line.Available, err = s.inventoryService.GetAvailableQuantity(ctx, item.ProductID); err != nil {
+				return nil, errors.Wrap(err, "checking available stock")
+			}
+			line.InStock = line.Available >= item.Quantity
+		}
+
+		if line.LineTotal, err = line.CurrentPrice.Mul(int64(item.Quantity)); err != nil {
+			return nil, errors.Wrap(err, "pricing cart item")
+		}
+		lineTotals = append(lineTotals, line.LineTotal)
+		response.Items = append(response.Items, line)
+	}
+
+	total, err := money.Sum(lineTotals...)
+	if err != nil {
+		// Carts may hold products priced in different currencies; checkout
+		// refuses those, so there is no single total to show.
+		total = money.Money{}
+	}
+	response.Total = total
+	return response, nil
+}
+
+func newGuestToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationStatusActive    ReservationStatus = "ACTIVE"
+	ReservationStatusCommitted ReservationStatus = "COMMITTED"
+	ReservationStatusReleased  ReservationStatus = "RELEASED"
+	ReservationStatusExpired   ReservationStatus = "EXPIRED"
+)
+
+// Reservation is a temporary hold on stock for a pending order. While active
+// it counts towards Inventory.ReservedQuantity; it is either committed into a
+// real deduction or released back to stock.
+type Reservation struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrderID     primitive.ObjectID `bson:"order_id" json:"order_id"`
+	ProductID   primitive.ObjectID `bson:"product_id" json:"product_id"`
+	InventoryID primitive.ObjectID `bson:"inventory_id" json:"inventory_id"`
+	WarehouseID primitive.ObjectID `bson:"warehouse_id" json:"warehouse_id"`
+	Quantity    int                `bson:"quantity" json:"quantity"`
+	Status      ReservationStatus  `bson:"status" json:"status"`
+	ExpiresAt   time.Time          `bson:"expires_at" json:"expires_at"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// IsExpired reports whether an active hold has outlived its TTL.
+func (r *Reservation) IsExpired(now time.Time) bool {
+	return r.Status == ReservationStatusActive && now.After(r.ExpiresAt)
+}
@@ -14,11 +14,16 @@ import (
 type IInventoryRepository interface {
 	CreateInventory(ctx context.Context, inventory *models.Inventory) (*models.Inventory, error)
 	GetInventoryByID(ctx context.Context, id string) (*models.Inventory, error)
-	UpdateInventory(ctx context.Context, id string, inventory *models.Inventory) error
+	UpdateInventory(ctx context.Context, id string, update InventoryUpdate) (*models.Inventory, error)
 	DeleteInventory(ctx context.Context, id string) error
 	GetInventoryByWarehouse(ctx context.Context, warehouseID primitive.ObjectID) ([]*models.Inventory, error)
 	GetProductInventoryAcrossWarehouses(ctx context.Context, productID primitive.ObjectID) ([]*models.Inventory, error)
 	TransferInventory(ctx context.Context, fromWarehouseID, toWarehouseID primitive.ObjectID, productID primitive.ObjectID, quantity int) error
+	HoldStock(ctx context.Context, productID primitive.ObjectID, quantity int) (*models.Inventory, error)
+	ReleaseStock(ctx context.Context, inventoryID primitive.ObjectID, quantity int) (*models.Inventory, error)
+	CommitStock(ctx context.Context, inventoryID primitive.ObjectID, quantity int) (*models.Inventory, error)
+	RestockStock(ctx context.Context, inventoryID primitive.ObjectID, quantity int) (*models.Inventory, error)
+	RestockWarehouse(ctx context.Context, productID, warehouseID primitive.ObjectID, quantity int) (*models.Inventory, error)
 }
 
 type InventoryRepository struct {
@@ -55,15 +60,50 @@ func (r *InventoryRepository) GetInventoryByID(ctx context.Context, id string) (
 	return &inventory, nil
 }
 
-func (r *InventoryRepository) UpdateInventory(ctx context.Context, id string, inventory *models.Inventory) error {
+// InventoryUpdate holds the fields of an inventory record its seller may edit.
+// Nil fields are left as they are.
+type InventoryUpdate struct {
+	ProductID *primitive.ObjectID
+	Quantity  *int
+	Status    *string
+}
+
+// UpdateInventory applies a seller's edit to an inventory record. The
+// reserved quantity is never written, and the edit only applies while it
+// keeps the holds made for orders valid: the quantity may not drop below the
+// reserved quantity and the product may only change while nothing is held.
+// It returns mongo.ErrNoDocuments when the record is gone or the edit would
+// break a hold.
+func (r *InventoryRepository) UpdateInventory(ctx context.Context, id string, update InventoryUpdate) (*models.Inventory, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	inventory.UpdatedAt = time.Now()
-	filter := bson.M{"_id": objID}
-	update := bson.M{"$set": inventory}
-	return r.db.Update(ctx, "inventories", filter, update)
+	reserved := bson.M{"$ifNull": bson.A{"$reserved_quantity", 0}}
+	conditions := bson.A{}
+	set := bson.M{"updated_at": time.Now()}
+	if update.Quantity != nil {
+		conditions = append(conditions, bson.M{"$gte": bson.A{*update.Quantity, reserved}})
+		set["quantity"] = *update.Quantity
+	}
+	if update.ProductID != nil {
+		conditions = append(conditions, bson.M{"$eq": bson.A{reserved, 0}})
+		set["product_id"] = *update.ProductID
+	}
+	filter := bson.M{"_id": objID, "deleted_at": nil}
+	if len(conditions) > 0 {
+		filter["$expr"] = bson.M{"$and": conditions}
+	}
+
+	if update.Status == nil {
+		return r.applyStockUpdate(ctx, filter, bson.M{"$set": set})
+	}
+	set["status"] = *update.Status
+	var inventory models.Inventory
+	if err := r.db.FindOneAndUpdate(ctx, "inventories", filter, bson.M{"$set": set}, &inventory); err != nil {
+		return nil, err
+	}
+	return &inventory, nil
 }
 
 func (r *InventoryRepository) DeleteInventory(ctx context.Context, id string) error {
@@ -113,3 +153,93 @@ func (r *InventoryRepository) TransferInventory(ctx context.Context, fromWarehou
 		return r.db.Update(sessCtx, "inventories", toFilter, toUpdate)
 	})
 }
+
+// HoldStock atomically reserves quantity units of a product on the first
+// inventory record whose available stock can cover it. It returns
+// mongo.ErrNoDocuments when no single record can cover the quantity.
+func (r *InventoryRepository) HoldStock(ctx context.Context, productID primitive.ObjectID, quantity int) (*models.Inventory, error) {
+	filter := bson.M{
+		"product_id": productID,
+		"deleted_at": nil,
+		"$expr": bson.M{
+			"$gte": bson.A{
+				bson.M{"$subtract": bson.A{"$quantity", bson.M{"$ifNull": bson.A{"$reserved_quantity", 0}}}},
+				quantity,
+			},
+		},
+	}
+	update := bson.M{
+		"$inc": bson.M{"reserved_quantity": quantity},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	return r.applyStockUpdate(ctx, filter, update)
+}
+
+// ReleaseStock returns held units of an inventory record to available stock.
+func (r *InventoryRepository) ReleaseStock(ctx context.Context, inventoryID primitive.ObjectID, quantity int) (*models.Inventory, error) {
+	filter := bson.M{
+		"_id":               inventoryID,
+		"reserved_quantity": bson.M{"$gte": quantity},
+	}
+	update := bson.M{
+		"$inc": bson.M{"reserved_quantity": -quantity},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	return r.applyStockUpdate(ctx, filter, update)
+}
+
+// CommitStock turns held units into a real deduction of on-hand stock.
+func (r *InventoryRepository) CommitStock(ctx context.Context, inventoryID primitive.ObjectID, quantity int) (*models.Inventory, error) {
+	filter := bson.M{
+		"_id":               inventoryID,
+		"reserved_quantity": bson.M{"$gte": quantity},
+		"quantity":          bson.M{"$gte": quantity},
+	}
+	update := bson.M{
+		"$inc": bson.M{"quantity": -quantity, "reserved_quantity": -quantity},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	return r.applyStockUpdate(ctx, filter, update)
+}
+
+// RestockStock puts units that were already deducted back into on-hand stock.
+func (r *InventoryRepository) RestockStock(ctx context.Context, inventoryID primitive.ObjectID, quantity int) (*models.Inventory, error) {
+	filter := bson.M{"_id": inventoryID}
+	update := bson.M{
+		"$inc": bson.M{"quantity": quantity},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	return r.applyStockUpdate(ctx, filter, update)
+}
+
+// RestockWarehouse adds units of a product to its inventory record in the
+// given warehouse. It returns mongo.ErrNoDocuments when the warehouse does not
+// stock the product.
+func (r *InventoryRepository) RestockWarehouse(ctx context.Context, productID, warehouseID primitive.ObjectID, quantity int) (*models.Inventory, error) {
+	filter := bson.M{
+		"product_id":   productID,
+		"warehouse_id": warehouseID,
+		"deleted_at":   nil,
+	}
+	update := bson.M{
+		"$inc": bson.M{"quantity": quantity},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	return r.applyStockUpdate(ctx, filter, update)
+}
+
+// applyStockUpdate runs a conditional stock update and refreshes the stock
+// status of the touched record to match its new quantities.
+func (r *InventoryRepository) applyStockUpdate(ctx context.Context, filter, update bson.M) (*models.Inventory, error) {
+	var inventory models.Inventory
+	if err := r.db.FindOneAndUpdate(ctx, "inventories", filter, update, &inventory); err != nil {
+		return nil, err
+	}
+
+	inventory.UpdateStatus()
+	statusUpdate := bson.M{"$set": bson.M{"status": inventory.Status}}
+	if err := r.db.Update(ctx, "inventories", bson.M{"_id": inventory.ID}, statusUpdate); err != nil {
+		return nil, err
+	}
+	return &inventory, nil
+}
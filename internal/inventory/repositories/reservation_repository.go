@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/inventory/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IReservationRepository interface {
+	CreateReservation(ctx context.Context, reservation *models.Reservation) (*models.Reservation, error)
+	GetReservationsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]*models.Reservation, error)
+	GetExpiredReservations(ctx context.Context, now time.Time) ([]*models.Reservation, error)
+	TransitionReservation(ctx context.Context, id primitive.ObjectID, from, to models.ReservationStatus) (*models.Reservation, error)
+}
+
+type ReservationRepository struct {
+	db database.IDatabase
+}
+
+func NewReservationRepository(db database.IDatabase) IReservationRepository {
+	return &ReservationRepository{
+		db: db,
+	}
+}
+
+func (r *ReservationRepository) CreateReservation(ctx context.Context, reservation *models.Reservation) (*models.Reservation, error) {
+	reservation.ID = primitive.NewObjectID()
+	reservation.CreatedAt = time.Now()
+	reservation.UpdatedAt = time.Now()
+	if err := r.db.Create(ctx, "reservations", reservation); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func (r *ReservationRepository) GetReservationsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]*models.Reservation, error) {
+	var reservations []*models.Reservation
+	filter := bson.M{"order_id": orderID}
+	err := r.db.Find(ctx, "reservations", filter, &reservations)
+	return reservations, err
+}
+
+func (r *ReservationRepository) GetExpiredReservations(ctx context.Context, now time.Time) ([]*models.Reservation, error) {
+	var reservations []*models.Reservation
+	filter := bson.M{
+		"status":     models.ReservationStatusActive,
+		"expires_at": bson.M{"$lt": now},
+	}
+	err := r.db.Find(ctx, "reservations", filter, &reservations)
+	return reservations, err
+}
+
+// TransitionReservation moves a reservation from one status to another. It
+// returns mongo.ErrNoDocuments when the reservation is no longer in the
+// expected status, so concurrent commits and sweeps cannot both win.
+func (r *ReservationRepository) TransitionReservation(ctx context.Context, id primitive.ObjectID, from, to models.ReservationStatus) (*models.Reservation, error) {
+	filter := bson.M{"_id": id, "status": from}
+	update := bson.M{"$set": bson.M{"status": to, "updated_at": time.Now()}}
+
+	var reservation models.Reservation
+	if err := r.db.FindOneAndUpdate(ctx, "reservations", filter, update, &reservation); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
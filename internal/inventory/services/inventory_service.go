@@ -2,15 +2,18 @@ package services
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
 	"log"
 
 	"github.com/devbenho/luka-platform/internal/inventory/dtos"
 	"github.com/devbenho/luka-platform/internal/inventory/models"
 	"github.com/devbenho/luka-platform/internal/inventory/repositories"
-	"github.com/devbenho/luka-platform/internal/utils"
+	ownership "github.com/devbenho/luka-platform/internal/ownership/services"
 	"github.com/devbenho/luka-platform/pkg/errors"
 	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type IInventoryService interface {
@@ -18,16 +21,21 @@ type IInventoryService interface {
 	UpdateInventory(ctx context.Context, id string, dto dtos.UpdateInventoryRequest) (*models.Inventory, error)
 	DeleteInventory(ctx context.Context, id string) error
 	GetInventoryByID(ctx context.Context, id string) (*models.Inventory, error)
+	// GetAvailableQuantity is how many units of a product one order line
+	// can be held for.
+	GetAvailableQuantity(ctx context.Context, productID primitive.ObjectID) (int, error)
 }
 
 type InventoryService struct {
 	repo      repositories.IInventoryRepository
+	ownership ownership.IOwnershipService
 	validator *validation.Validator
 }
 
-func NewInventoryService(repo repositories.IInventoryRepository, validator *validation.Validator) *InventoryService {
+func NewInventoryService(repo repositories.IInventoryRepository, ownership ownership.IOwnershipService, validator *validation.Validator) *InventoryService {
 	return &InventoryService{
 		repo:      repo,
+		ownership: ownership,
 		validator: validator,
 	}
 }
@@ -39,6 +47,20 @@ func (s *InventoryService) CreateInventory(ctx context.Context, dto dtos.CreateI
 		}
 		return nil, err
 	}
+	if _, err := s.ownership.AuthorizeStore(ctx, dto.StoreID); err != nil {
+		return nil, err
+	}
+	if err := s.checkProduct(ctx, dto.StoreID, dto.ProductID); err != nil {
+		return nil, err
+	}
+	warehouse, err := s.ownership.AuthorizeWarehouse(ctx, dto.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if warehouse.StoreID != dto.StoreID {
+		return nil, errors.NewBadRequestError("warehouse does not belong to the store")
+	}
+
 	inventory := dto.ToInventory()
 	return s.repo.CreateInventory(ctx, inventory)
 }
@@ -49,35 +71,94 @@ func (s *InventoryService) UpdateInventory(ctx context.Context, id string, updat
 		return nil, fmt.Errorf("invalid update request: %w", err)
 	}
 
-	existingInventory, err := s.repo.GetInventoryByID(ctx, id)
+	existingInventory, err := s.authorizeInventory(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch inventory: %w", err)
+		return nil, err
+	}
+	update := repositories.InventoryUpdate{
+		Quantity: updateBody.Quantity,
+		Status:   updateBody.Status,
+	}
+	if updateBody.ProductId != nil && *updateBody.ProductId != existingInventory.ProductID {
+		if existingInventory.ReservedQuantity > 0 {
+			return nil, errors.NewConflictError("the product of an inventory cannot change while stock is held for orders")
+		}
+		if err := s.checkProduct(ctx, existingInventory.StoreID, *updateBody.ProductId); err != nil {
+			return nil, err
+		}
+		update.ProductID = updateBody.ProductId
 	}
-	if existingInventory == nil {
-		return nil, errors.NewNotFoundError(
-			"inventory",
-			id,
+	if updateBody.Quantity != nil && *updateBody.Quantity < existingInventory.ReservedQuantity {
+		return nil, errors.NewError(
+			errors.ValidationErrorType,
+			400,
+			fmt.Sprintf("quantity cannot be below the %d units held for orders", existingInventory.ReservedQuantity),
+			errors.WithField("quantity"),
 		)
 	}
 
-	utils.Copy(existingInventory, updateBody)
-
-	if err := s.repo.UpdateInventory(ctx, id, existingInventory); err != nil {
+	// The update is conditional on the holds as they are now, so that one
+	// placed since the inventory was read is not overwritten or broken.
+	inventory, err := s.repo.UpdateInventory(ctx, id, update)
+	if stdErrors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errors.NewConflictError("stock was held for orders meanwhile, retry the update")
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to update inventory: %w", err)
 	}
 
-	return existingInventory, nil
+	return inventory, nil
 }
 
 func (s *InventoryService) DeleteInventory(ctx context.Context, id string) error {
+	if _, err := s.authorizeInventory(ctx, id); err != nil {
+		return err
+	}
 	return s.repo.DeleteInventory(ctx, id)
 }
 
 func (s *InventoryService) GetInventoryByID(ctx context.Context, id string) (*models.Inventory, error) {
-	existInventory, err := s.repo.GetInventoryByID(ctx, id)
-	if err != nil {
+	return s.authorizeInventory(ctx, id)
+}
+
+// authorizeInventory returns the inventory when the principal of ctx owns its
+// store.
+func (s *InventoryService) authorizeInventory(ctx context.Context, id string) (*models.Inventory, error) {
+	inventory, err := s.repo.GetInventoryByID(ctx, id)
+	if err != nil || inventory == nil {
 		return nil, errors.NewNotFoundError("inventory", id)
 	}
+	if _, err := s.ownership.AuthorizeStore(ctx, inventory.StoreID); err != nil {
+		return nil, err
+	}
+	return inventory, nil
+}
+
+// checkProduct makes sure the principal of ctx owns the product and that it is
+// sold by the store the inventory belongs to.
+func (s *InventoryService) checkProduct(ctx context.Context, storeID, productID primitive.ObjectID) error {
+	product, err := s.ownership.AuthorizeProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if product.StoreID != storeID {
+		return errors.NewBadRequestError("product does not belong to the store")
+	}
+	return nil
+}
+
+// GetAvailableQuantity returns the unreserved stock of the product's best
+// stocked warehouse. An order line is held from a single inventory record, so
+// stock spread over several warehouses cannot be added up.
+func (s *InventoryService) GetAvailableQuantity(ctx context.Context, productID primitive.ObjectID) (int, error) {
+	inventories, err := s.repo.GetProductInventoryAcrossWarehouses(ctx, productID)
+	if err != nil {
+		return 0, errors.Wrap(err, "fetching product inventory")
+	}
 
-	return existInventory, nil
+	available := 0
+	for _, inventory := range inventories {
+		available = max(available, inventory.Available())
+	}
+	return available, nil
 }
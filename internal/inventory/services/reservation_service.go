@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/inventory/models"
+	"github.com/devbenho/luka-platform/internal/inventory/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IReservationService manages temporary stock holds for pending orders.
+// HoldStock and CommitReservations expect to be called with a transaction
+// session context so they commit or roll back together with the order.
+type IReservationService interface {
+	HoldStock(ctx context.Context, orderID, productID primitive.ObjectID, quantity int, ttl time.Duration) (*models.Reservation, error)
+	CommitReservations(ctx context.Context, orderID primitive.ObjectID) error
+	ReleaseReservations(ctx context.Context, orderID primitive.ObjectID) error
+	ReleaseExpiredReservations(ctx context.Context) (int, error)
+}
+
+type ReservationService struct {
+	db            database.IDatabase
+	repo          repositories.IReservationRepository
+	inventoryRepo repositories.IInventoryRepository
+}
+
+func NewReservationService(
+	db database.IDatabase,
+	repo repositories.IReservationRepository,
+	inventoryRepo repositories.IInventoryRepository,
+) *ReservationService {
+	return &ReservationService{
+		db:            db,
+		repo:          repo,
+		inventoryRepo: inventoryRepo,
+	}
+}
+
+func (s *ReservationService) HoldStock(ctx context.Context, orderID, productID primitive.ObjectID, quantity int, ttl time.Duration) (*models.Reservation, error) {
+	inventory, err := s.inventoryRepo.HoldStock(ctx, productID, quantity)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewError(
+			errors.InsufficientStock,
+			409,
+			fmt.Sprintf("insufficient stock for product %s", productID.Hex()),
+			errors.WithField("product_id"),
+		)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "holding stock")
+	}
+
+	reservation, err := s.repo.CreateReservation(ctx, &models.Reservation{
+		OrderID:     orderID,
+		ProductID:   productID,
+		InventoryID: inventory.ID,
+		WarehouseID: inventory.WarehouseID,
+		Quantity:    quantity,
+		Status:      models.ReservationStatusActive,
+		ExpiresAt:   time.Now().Add(ttl),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "creating reservation")
+	}
+	return reservation, nil
+}
+
+// CommitReservations converts every hold of an order into a deduction of
+// on-hand stock. It fails if any hold has expired or was already released.
+func (s *ReservationService) CommitReservations(ctx context.Context, orderID primitive.ObjectID) error {
+	reservations, err := s.repo.GetReservationsByOrder(ctx, orderID)
+	if err != nil {
+		return errors.Wrap(err, "fetching reservations")
+	}
+
+	now := time.Now()
+	for _, reservation := range reservations {
+		if reservation.Status == models.ReservationStatusCommitted {
+			continue
+		}
+		if reservation.Status != models.ReservationStatusActive || reservation.IsExpired(now) {
+			return errors.NewConflictError(fmt.Sprintf("stock reservation for product %s has expired", reservation.ProductID.Hex()))
+		}
+
+		if _, err := s.repo.TransitionReservation(ctx, reservation.ID, models.ReservationStatusActive, models.ReservationStatusCommitted); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errors.NewConflictError(fmt.Sprintf("stock reservation for product %s is no longer active", reservation.ProductID.Hex()))
+			}
+			return errors.Wrap(err, "committing reservation")
+		}
+		if _, err := s.inventoryRepo.CommitStock(ctx, reservation.InventoryID, reservation.Quantity); err != nil {
+			return errors.Wrapf(err, "committing stock for inventory %s", reservation.InventoryID.Hex())
+		}
+	}
+	return nil
+}
+
+// ReleaseReservations returns all stock taken for an order: active holds go
+// back to available stock and committed deductions go back on hand. Holds that
+// already expired or were released are skipped, so calling it twice is safe.
+func (s *ReservationService) ReleaseReservations(ctx context.Context, orderID primitive.ObjectID) error {
+	reservations, err := s.repo.GetReservationsByOrder(ctx, orderID)
+	if err != nil {
+		return errors.Wrap(err, "fetching reservations")
+	}
+
+	for _, reservation := range reservations {
+		switch reservation.Status {
+		case models.ReservationStatusActive:
+			err = s.release(ctx, reservation, s.inventoryRepo.ReleaseStock)
+		case models.ReservationStatusCommitted:
+			err = s.release(ctx, reservation, s.inventoryRepo.RestockStock)
+		default:
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "releasing reservation %s", reservation.ID.Hex())
+		}
+	}
+	return nil
+}
+
+type stockUpdateFunc func(ctx context.Context, inventoryID primitive.ObjectID, quantity int) (*models.Inventory, error)
+
+func (s *ReservationService) release(ctx context.Context, reservation *models.Reservation, giveBack stockUpdateFunc) error {
+	_, err := s.repo.TransitionReservation(ctx, reservation.ID, reservation.Status, models.ReservationStatusReleased)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = giveBack(ctx, reservation.InventoryID, reservation.Quantity)
+	return err
+}
+
+// ReleaseExpiredReservations gives the stock of every expired hold back to its
+// inventory record. Each hold is released in its own transaction so one bad
+// record does not block the rest: failures are reported together once every
+// hold has been tried. It returns how many holds were released.
+func (s *ReservationService) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	reservations, err := s.repo.GetExpiredReservations(ctx, time.Now())
+	if err != nil {
+		return 0, errors.Wrap(err, "fetching expired reservations")
+	}
+
+	released := 0
+	var failures []error
+	for _, reservation := range reservations {
+		err := s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+			_, err := s.repo.TransitionReservation(sessCtx, reservation.ID, models.ReservationStatusActive, models.ReservationStatusExpired)
+			if err != nil {
+				return err
+			}
+			_, err = s.inventoryRepo.ReleaseStock(sessCtx, reservation.InventoryID, reservation.Quantity)
+			return err
+		})
+		if err == mongo.ErrNoDocuments {
+			// Committed or released concurrently; nothing left to give back.
+			continue
+		}
+		if err != nil {
+			failures = append(failures, fmt.Errorf("reservation %s: %w", reservation.ID.Hex(), err))
+			continue
+		}
+		released++
+	}
+	if len(failures) > 0 {
+		return released, errors.Wrapf(stdErrors.Join(failures...), "releasing %d expired reservation(s)", len(failures))
+	}
+	return released, nil
+}
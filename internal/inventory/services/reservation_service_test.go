@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/inventory/models"
+	"github.com/devbenho/luka-platform/internal/inventory/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// fakeDB runs transactions in place; the fakes below have no rollback.
+type fakeDB struct {
+	database.IDatabase
+}
+
+func (fakeDB) WithTransaction(ctx context.Context, function func(sessCtx mongo.SessionContext) error) error {
+	return function(mongo.NewSessionContext(ctx, nil))
+}
+
+// fakeInventoryRepo applies the stock updates with the conditions the Mongo
+// repository puts in its filters.
+type fakeInventoryRepo struct {
+	repositories.IInventoryRepository
+	records     []*models.Inventory
+	failRelease map[primitive.ObjectID]error
+}
+
+func (r *fakeInventoryRepo) find(id primitive.ObjectID) *models.Inventory {
+	for _, record := range r.records {
+		if record.ID == id {
+			return record
+		}
+	}
+	return nil
+}
+
+func (r *fakeInventoryRepo) HoldStock(ctx context.Context, productID primitive.ObjectID, quantity int) (*models.Inventory, error) {
+	for _, record := range r.records {
+		if record.ProductID == productID && record.Available() >= quantity {
+			record.ReservedQuantity += quantity
+			held := *record
+			return &held, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *fakeInventoryRepo) ReleaseStock(ctx context.Context, inventoryID primitive.ObjectID, quantity int) (*models.Inventory, error) {
+	if err := r.failRelease[inventoryID]; err != nil {
+		return nil, err
+	}
+	record := r.find(inventoryID)
+	if record == nil || record.ReservedQuantity < quantity {
+		return nil, mongo.ErrNoDocuments
+	}
+	record.ReservedQuantity -= quantity
+	return record, nil
+}
+
+func (r *fakeInventoryRepo) CommitStock(ctx context.Context, inventoryID primitive.ObjectID, quantity int) (*models.Inventory, error) {
+	record := r.find(inventoryID)
+	if record == nil || record.ReservedQuantity < quantity || record.Quantity < quantity {
+		return nil, mongo.ErrNoDocuments
+	}
+	record.Quantity -= quantity
+	record.ReservedQuantity -= quantity
+	return record, nil
+}
+
+func (r *fakeInventoryRepo) RestockStock(ctx context.Context, inventoryID primitive.ObjectID, quantity int) (*models.Inventory, error) {
+	record := r.find(inventoryID)
+	if record == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	record.Quantity += quantity
+	return record, nil
+}
+
+// fakeReservationRepo hands out copies, as decoding from the database does.
+type fakeReservationRepo struct {
+	reservations []*models.Reservation
+}
+
+func (r *fakeReservationRepo) CreateReservation(ctx context.Context, reservation *models.Reservation) (*models.Reservation, error) {
+	reservation.ID = primitive.NewObjectID()
+	stored := *reservation
+	r.reservations = append(r.reservations, &stored)
+	return reservation, nil
+}
+
+func (r *fakeReservationRepo) GetReservationsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]*models.Reservation, error) {
+	var found []*models.Reservation
+	for _, reservation := range r.reservations {
+		if reservation.OrderID == orderID {
+			copied := *reservation
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeReservationRepo) GetExpiredReservations(ctx context.Context, now time.Time) ([]*models.Reservation, error) {
+	var found []*models.Reservation
+	for _, reservation := range r.reservations {
+		if reservation.IsExpired(now) {
+			copied := *reservation
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeReservationRepo) TransitionReservation(ctx context.Context, id primitive.ObjectID, from, to models.ReservationStatus) (*models.Reservation, error) {
+	for _, reservation := range r.reservations {
+		if reservation.ID == id && reservation.Status == from {
+			reservation.Status = to
+			copied := *reservation
+			return &copied, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *fakeReservationRepo) status(id primitive.ObjectID) models.ReservationStatus {
+	for _, reservation := range r.reservations {
+		if reservation.ID == id {
+			return reservation.Status
+		}
+	}
+	return ""
+}
+
+func newInventory(productID primitive.ObjectID, quantity, reserved int) *models.Inventory {
+	return &models.Inventory{
+		ID:               primitive.NewObjectID(),
+		ProductID:        productID,
+		WarehouseID:      primitive.NewObjectID(),
+		Quantity:         quantity,
+		ReservedQuantity: reserved,
+	}
+}
+
+func newReservationService(records ...*models.Inventory) (*ReservationService, *fakeReservationRepo, *fakeInventoryRepo) {
+	reservations := &fakeReservationRepo{}
+	inventories := &fakeInventoryRepo{records: records, failRelease: map[primitive.ObjectID]error{}}
+	return NewReservationService(fakeDB{}, reservations, inventories), reservations, inventories
+}
+
+func isAppError(err error, code int) bool {
+	var appErr *errors.AppError
+	return stdErrors.As(err, &appErr) && appErr.Code == code
+}
+
+func TestHoldStock(t *testing.T) {
+	product := primitive.NewObjectID()
+	tests := []struct {
+		name         string
+		records      []*models.Inventory
+		quantity     int
+		wantRecord   int
+		wantReserved []int
+		wantCode     int
+	}{
+		{"holds from the first record", []*models.Inventory{newInventory(product, 10, 0)}, 4, 0, []int{4}, 0},
+		{"holds all that is available", []*models.Inventory{newInventory(product, 10, 6)}, 4, 0, []int{10}, 0},
+		{"skips a record that cannot cover it", []*models.Inventory{newInventory(product, 3, 0), newInventory(product, 8, 2)}, 5, 1, []int{0, 7}, 0},
+		{"does not split across records", []*models.Inventory{newInventory(product, 3, 0), newInventory(product, 3, 0)}, 5, -1, []int{0, 0}, 409},
+		{"counts held stock as taken", []*models.Inventory{newInventory(product, 10, 8)}, 3, -1, []int{8}, 409},
+		{"other products do not count", []*models.Inventory{newInventory(primitive.NewObjectID(), 10, 0)}, 1, -1, []int{0}, 409},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, reservations, _ := newReservationService(tt.records...)
+			orderID := primitive.NewObjectID()
+			before := time.Now()
+
+			reservation, err := service.HoldStock(context.Background(), orderID, product, tt.quantity, 15*time.Minute)
+			if tt.wantCode != 0 {
+				if !isAppError(err, tt.wantCode) {
+					t.Fatalf("HoldStock() error = %v, want code %d", err, tt.wantCode)
+				}
+				if len(reservations.reservations) != 0 {
+					t.Fatalf("HoldStock() recorded %d reservation(s) on failure", len(reservations.reservations))
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("HoldStock() error = %v", err)
+				}
+				record := tt.records[tt.wantRecord]
+				if reservation.InventoryID != record.ID || reservation.WarehouseID != record.WarehouseID {
+					t.Errorf("HoldStock() held from %s, want %s", reservation.InventoryID.Hex(), record.ID.Hex())
+				}
+				if reservation.Status != models.ReservationStatusActive || reservation.Quantity != tt.quantity || reservation.OrderID != orderID {
+					t.Errorf("HoldStock() = %+v", reservation)
+				}
+				if reservation.ExpiresAt.Before(before.Add(15 * time.Minute)) {
+					t.Errorf("HoldStock() expires at %s, before the TTL", reservation.ExpiresAt)
+				}
+			}
+			for i, record := range tt.records {
+				if record.ReservedQuantity != tt.wantReserved[i] {
+					t.Errorf("record %d reserved %d, want %d", i, record.ReservedQuantity, tt.wantReserved[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCommitAndReleaseReservations(t *testing.T) {
+	tests := []struct {
+		name         string
+		steps        []string
+		expired      bool
+		wantErr      []bool
+		wantQuantity int
+		wantReserved int
+		wantStatus   models.ReservationStatus
+	}{
+		{"commit deducts the hold", []string{"commit"}, false, []bool{false}, 6, 0, models.ReservationStatusCommitted},
+		{"commit twice deducts once", []string{"commit", "commit"}, false, []bool{false, false}, 6, 0, models.ReservationStatusCommitted},
+		{"release returns the hold", []string{"release"}, false, []bool{false}, 10, 0, models.ReservationStatusReleased},
+		{"release twice returns once", []string{"release", "release"}, false, []bool{false, false}, 10, 0, models.ReservationStatusReleased},
+		{"release after commit restocks", []string{"commit", "release"}, false, []bool{false, false}, 10, 0, models.ReservationStatusReleased},
+		{"commit after release fails", []string{"release", "commit"}, false, []bool{false, true}, 10, 0, models.ReservationStatusReleased},
+		{"expired hold cannot be committed", []string{"commit"}, true, []bool{true}, 10, 4, models.ReservationStatusActive},
+		{"expired hold can be released", []string{"release"}, true, []bool{false}, 10, 0, models.ReservationStatusReleased},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := primitive.NewObjectID()
+			record := newInventory(product, 10, 0)
+			service, reservations, _ := newReservationService(record)
+			ctx := context.Background()
+			orderID := primitive.NewObjectID()
+
+			ttl := 15 * time.Minute
+			if tt.expired {
+				ttl = -time.Minute
+			}
+			reservation, err := service.HoldStock(ctx, orderID, product, 4, ttl)
+			if err != nil {
+				t.Fatalf("HoldStock() error = %v", err)
+			}
+
+			for i, step := range tt.steps {
+				if step == "commit" {
+					err = service.CommitReservations(ctx, orderID)
+				} else {
+					err = service.ReleaseReservations(ctx, orderID)
+				}
+				if (err != nil) != tt.wantErr[i] {
+					t.Fatalf("step %d (%s) error = %v, want error %t", i, step, err, tt.wantErr[i])
+				}
+			}
+			if record.Quantity != tt.wantQuantity || record.ReservedQuantity != tt.wantReserved {
+				t.Errorf("stock = %d on hand, %d reserved; want %d, %d", record.Quantity, record.ReservedQuantity, tt.wantQuantity, tt.wantReserved)
+			}
+			if got := reservations.status(reservation.ID); got != tt.wantStatus {
+				t.Errorf("reservation status = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestReleaseExpiredReservationsContinuesAfterFailure(t *testing.T) {
+	product := primitive.NewObjectID()
+	records := []*models.Inventory{newInventory(product, 2, 0), newInventory(product, 2, 0), newInventory(product, 2, 0)}
+	service, reservations, inventories := newReservationService(records...)
+	ctx := context.Background()
+
+	for range records {
+		if _, err := service.HoldStock(ctx, primitive.NewObjectID(), product, 2, -time.Minute); err != nil {
+			t.Fatalf("HoldStock() error = %v", err)
+		}
+	}
+	inventories.records = append(inventories.records, newInventory(product, 5, 0))
+	live, err := service.HoldStock(ctx, primitive.NewObjectID(), product, 1, time.Hour)
+	if err != nil {
+		t.Fatalf("HoldStock() error = %v", err)
+	}
+	failing := reservations.reservations[1]
+	inventories.failRelease[failing.InventoryID] = stdErrors.New("write conflict")
+
+	released, err := service.ReleaseExpiredReservations(ctx)
+	if released != 2 {
+		t.Errorf("ReleaseExpiredReservations() released %d, want 2", released)
+	}
+	if err == nil || !strings.Contains(err.Error(), failing.ID.Hex()) {
+		t.Errorf("ReleaseExpiredReservations() error = %v, want it to name reservation %s", err, failing.ID.Hex())
+	}
+	for i, record := range records {
+		want := 0
+		if i == 1 {
+			want = 2
+		}
+		if record.ReservedQuantity != want {
+			t.Errorf("record %d reserved %d, want %d", i, record.ReservedQuantity, want)
+		}
+	}
+	if got := reservations.status(live.ID); got != models.ReservationStatusActive {
+		t.Errorf("unexpired reservation status = %s, want %s", got, models.ReservationStatusActive)
+	}
+}
+
+// sweepCounter fails its first sweep and counts the ones after it.
+type sweepCounter struct {
+	IReservationService
+	calls chan int
+	n     int
+}
+
+func (s *sweepCounter) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	s.n++
+	select {
+	case s.calls <- s.n:
+	default:
+	}
+	if s.n == 1 {
+		return 0, stdErrors.New("database unavailable")
+	}
+	return 1, nil
+}
+
+func TestReservationSweeperKeepsRunningAfterError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := &sweepCounter{calls: make(chan int, 10)}
+
+	done := make(chan struct{})
+	go func() {
+		NewReservationSweeper(service, time.Millisecond, zap.NewNop()).Run(ctx)
+		close(done)
+	}()
+
+	timeout := time.After(5 * time.Second)
+	for swept := 0; swept < 3; {
+		select {
+		case swept = <-service.calls:
+		case <-timeout:
+			t.Fatalf("sweeper stopped after %d sweep(s)", swept)
+		}
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-timeout:
+		t.Fatal("sweeper did not stop when its context was cancelled")
+	}
+}
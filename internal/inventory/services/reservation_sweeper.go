@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReservationSweeper periodically releases expired stock holds.
+type ReservationSweeper struct {
+	service  IReservationService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewReservationSweeper(service IReservationService, interval time.Duration, logger *zap.Logger) *ReservationSweeper {
+	return &ReservationSweeper{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run sweeps until ctx is cancelled. It is meant to be started in its own goroutine.
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := s.service.ReleaseExpiredReservations(ctx)
+			if err != nil {
+				s.logger.Error("releasing expired reservations", zap.Error(err))
+			}
+			if released > 0 {
+				s.logger.Info("released expired reservations", zap.Int("count", released))
+			}
+		}
+	}
+}
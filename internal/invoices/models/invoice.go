@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	shippingModels "github.com/devbenho/luka-platform/internal/shipping/models"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InvoiceLine is one billed order item.
+type InvoiceLine struct {
+	ProductID    primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Description  string             `bson:"description" json:"description"`
+	Quantity     int                `bson:"quantity" json:"quantity"`
+	UnitPrice    money.Money        `bson:"unit_price" json:"unit_price"`
+	Discount     money.Money        `bson:"discount" json:"discount"`
+	TaxRate      int64              `bson:"tax_rate" json:"tax_rate_basis_points"`
+	TaxInclusive bool               `bson:"tax_inclusive" json:"tax_inclusive"`
+	TaxAmount    money.Money        `bson:"tax_amount" json:"tax_amount"`
+	NetAmount    money.Money        `bson:"net_amount" json:"net_amount"`
+}
+
+// Seller holds the store details printed on an invoice.
+type Seller struct {
+	StoreID   primitive.ObjectID `bson:"store_id" json:"store_id"`
+	Name      string             `bson:"name" json:"name"`
+	Slug      string             `bson:"slug" json:"slug"`
+	Latitude  float64            `bson:"latitude,omitempty" json:"latitude,omitempty"`
+	Longitude float64            `bson:"longitude,omitempty" json:"longitude,omitempty"`
+}
+
+// Invoice is the bill a store issues for an order. It is a snapshot taken
+// when the order is processed, so later changes to products or the store do
+// not alter invoices that were already issued.
+type Invoice struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrderID    primitive.ObjectID `bson:"order_id" json:"order_id"`
+	CustomerID primitive.ObjectID `bson:"customer_id" json:"customer_id"`
+	// Sequence counts the invoices of the store, starting at one.
+	Sequence       int64                  `bson:"sequence" json:"sequence"`
+	Number         string                 `bson:"number" json:"number"`
+	Seller         Seller                 `bson:"seller" json:"seller"`
+	BillTo         shippingModels.Address `bson:"bill_to" json:"bill_to"`
+	Lines          []InvoiceLine          `bson:"lines" json:"lines"`
+	Discount       string                 `bson:"discount,omitempty" json:"discount,omitempty"`
+	DiscountAmount money.Money            `bson:"discount_amount" json:"discount_amount"`
+	Subtotal       money.Money            `bson:"subtotal" json:"subtotal"`
+	TaxLines       []orderModels.TaxLine  `bson:"tax_lines,omitempty" json:"tax_lines,omitempty"`
+	TaxAmount      money.Money            `bson:"tax_amount" json:"tax_amount"`
+	ShippingMethod string                 `bson:"shipping_method,omitempty" json:"shipping_method,omitempty"`
+	ShippingAmount money.Money            `bson:"shipping_amount" json:"shipping_amount"`
+	TotalAmount    money.Money            `bson:"total_amount" json:"total_amount"`
+	IssuedAt       time.Time              `bson:"issued_at" json:"issued_at"`
+}
+
+// InvoiceNumber formats the sequence of a store's invoice, e.g. INV-000042.
+func InvoiceNumber(sequence int64) string {
+	return fmt.Sprintf("INV-%06d", sequence)
+}
+
+// InvoiceCounter holds the last invoice sequence used by a store.
+type InvoiceCounter struct {
+	StoreID  primitive.ObjectID `bson:"_id"`
+	Sequence int64              `bson:"sequence"`
+}
@@ -0,0 +1,78 @@
+package renderers
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/devbenho/luka-platform/internal/invoices/models"
+)
+
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"rate": formatRate,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 40px; }
+h1 { margin: 0 0 4px; }
+table { width: 100%; border-collapse: collapse; margin-top: 24px; }
+th, td { padding: 6px 8px; text-align: left; border-bottom: 1px solid #ddd; }
+td.amount, th.amount { text-align: right; }
+.parties { display: flex; justify-content: space-between; margin-top: 24px; }
+.totals { width: 40%; margin-left: auto; }
+.total td { font-weight: bold; border-top: 2px solid #222; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<div>Issued {{.IssuedAt.Format "2006-01-02"}} &middot; Order {{.OrderID.Hex}}</div>
+<div class="parties">
+<div>
+<strong>{{.Seller.Name}}</strong><br>
+{{.Seller.Slug}}
+</div>
+<div>
+<strong>Bill to</strong><br>
+{{.BillTo.Recipient}}<br>
+{{.BillTo.Address}}<br>
+{{.BillTo.City}}{{if .BillTo.Region}}, {{.BillTo.Region}}{{end}} {{.BillTo.PostalCode}}<br>
+{{.BillTo.Country}}
+</div>
+</div>
+<table>
+<thead>
+<tr><th>Item</th><th class="amount">Qty</th><th class="amount">Unit price</th><th class="amount">Discount</th><th class="amount">Tax</th><th class="amount">Net</th></tr>
+</thead>
+<tbody>
+{{range .Lines}}<tr><td>{{.Description}}</td><td class="amount">{{.Quantity}}</td><td class="amount">{{.UnitPrice}}</td><td class="amount">{{.Discount}}</td><td class="amount">{{.TaxAmount}} ({{rate .TaxRate}})</td><td class="amount">{{.NetAmount}}</td></tr>
+{{end}}</tbody>
+</table>
+<table class="totals">
+<tr><td>Subtotal</td><td class="amount">{{.Subtotal}}</td></tr>
+{{if .Discount}}<tr><td>Discount {{.Discount}} (included above)</td><td class="amount">-{{.DiscountAmount}}</td></tr>
+{{end}}{{range .TaxLines}}<tr><td>{{.Rule}} {{rate .RateBasisPoints}}{{if .Inclusive}} (included){{end}}</td><td class="amount">{{.TaxAmount}}</td></tr>
+{{end}}<tr><td>Shipping{{if .ShippingMethod}} ({{.ShippingMethod}}){{end}}</td><td class="amount">{{.ShippingAmount}}</td></tr>
+<tr class="total"><td>Total</td><td class="amount">{{.TotalAmount}}</td></tr>
+</table>
+</body>
+</html>
+`))
+
+// RenderHTML writes the invoice as a standalone HTML page.
+func RenderHTML(w io.Writer, invoice *models.Invoice) error {
+	if err := invoiceTemplate.Execute(w, invoice); err != nil {
+		return fmt.Errorf("failed to render invoice html: %w", err)
+	}
+	return nil
+}
+
+// formatRate prints a rate in basis points as a percentage, e.g. 1250 as 12.5%.
+func formatRate(basisPoints int64) string {
+	if basisPoints%100 == 0 {
+		return fmt.Sprintf("%d%%", basisPoints/100)
+	}
+	return fmt.Sprintf("%.2f%%", float64(basisPoints)/100)
+}
@@ -0,0 +1,128 @@
+package renderers
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/devbenho/luka-platform/internal/invoices/models"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Column widths of the line table, in millimetres. They add up to the
+// printable width of an A4 page with 15mm margins.
+var lineColumns = []float64{70, 15, 25, 25, 20, 25}
+
+// RenderPDF writes the invoice as an A4 PDF document.
+func RenderPDF(w io.Writer, invoice *models.Invoice) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetTitle("Invoice "+invoice.Number, true)
+	// The core fonts are Latin-1, so names and addresses are translated.
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, "Invoice "+invoice.Number, "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 5, "Issued "+invoice.IssuedAt.Format("2006-01-02")+" - Order "+invoice.OrderID.Hex(), "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	top := pdf.GetY()
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(90, 6, tr(invoice.Seller.Name), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(90, 5, tr(invoice.Seller.Slug), "", 2, "L", false, 0, "")
+
+	address := invoice.BillTo
+	city := address.City
+	if address.Region != "" {
+		city += ", " + address.Region
+	}
+	pdf.SetXY(110, top)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(85, 6, "Bill to", "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range []string{address.Recipient, address.Address, city + " " + address.PostalCode, address.Country} {
+		pdf.CellFormat(85, 5, tr(line), "", 2, "L", false, 0, "")
+	}
+	pdf.SetX(15)
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	for i, header := range []string{"Item", "Qty", "Unit price", "Discount", "Tax", "Net"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(lineColumns[i], 7, header, "B", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, line := range invoice.Lines {
+		cells := []string{
+			fit(pdf, tr(line.Description), lineColumns[0]-2),
+			strconv.Itoa(line.Quantity),
+			line.UnitPrice.String(),
+			line.Discount.String(),
+			line.TaxAmount.String(),
+			line.NetAmount.String(),
+		}
+		for i, cell := range cells {
+			align := "R"
+			if i == 0 {
+				align = "L"
+			}
+			pdf.CellFormat(lineColumns[i], 6, cell, "B", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.Ln(4)
+
+	total := func(label, amount string, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.SetX(105)
+		pdf.CellFormat(55, 6, tr(label), "", 0, "L", false, 0, "")
+		pdf.CellFormat(35, 6, amount, "", 1, "R", false, 0, "")
+	}
+	total("Subtotal", invoice.Subtotal.String(), false)
+	if invoice.Discount != "" {
+		total("Discount "+invoice.Discount+" (included above)", "-"+invoice.DiscountAmount.String(), false)
+	}
+	for _, line := range invoice.TaxLines {
+		label := line.Rule + " " + formatRate(line.RateBasisPoints)
+		if line.Inclusive {
+			label += " (included)"
+		}
+		total(label, line.TaxAmount.String(), false)
+	}
+	shipping := "Shipping"
+	if invoice.ShippingMethod != "" {
+		shipping += " (" + invoice.ShippingMethod + ")"
+	}
+	total(shipping, invoice.ShippingAmount.String(), false)
+	total("Total", invoice.TotalAmount.String(), true)
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to render invoice pdf: %w", err)
+	}
+	return nil
+}
+
+// fit shortens already translated, single-byte text with an ellipsis until it
+// is at most width wide.
+func fit(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
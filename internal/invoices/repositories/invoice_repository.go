@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/devbenho/luka-platform/internal/invoices/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IInvoiceRepository interface {
+	CreateInvoice(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error)
+	GetInvoiceByOrder(ctx context.Context, orderID primitive.ObjectID) (*models.Invoice, error)
+	NextSequence(ctx context.Context, storeID primitive.ObjectID) (int64, error)
+}
+
+type InvoiceRepository struct {
+	db database.IDatabase
+}
+
+func NewInvoiceRepository(db database.IDatabase) IInvoiceRepository {
+	return &InvoiceRepository{
+		db: db,
+	}
+}
+
+func (r *InvoiceRepository) CreateInvoice(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error) {
+	invoice.ID = primitive.NewObjectID()
+	if err := r.db.Create(ctx, "invoices", invoice); err != nil {
+		return nil, fmt.Errorf("failed to create invoice in db: %w", err)
+	}
+	return invoice, nil
+}
+
+func (r *InvoiceRepository) GetInvoiceByOrder(ctx context.Context, orderID primitive.ObjectID) (*models.Invoice, error) {
+	var invoice models.Invoice
+	filter := bson.M{"order_id": orderID}
+	if err := r.db.FindOne(ctx, "invoices", filter, &invoice); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("invoice not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get invoice from db: %w", err)
+	}
+	return &invoice, nil
+}
+
+// NextSequence increments and returns the store's invoice counter. Called in
+// a transaction, the increment is rolled back with it, so numbers have no
+// gaps.
+func (r *InvoiceRepository) NextSequence(ctx context.Context, storeID primitive.ObjectID) (int64, error) {
+	var counter models.InvoiceCounter
+	filter := bson.M{"_id": storeID}
+	update := bson.M{"$inc": bson.M{"sequence": 1}}
+	if err := r.db.FindOneAndUpsert(ctx, "invoice_counters", filter, update, &counter); err != nil {
+		return 0, fmt.Errorf("failed to increment invoice sequence: %w", err)
+	}
+	return counter.Sequence, nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/invoices/models"
+	"github.com/devbenho/luka-platform/internal/invoices/repositories"
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	orderRepo "github.com/devbenho/luka-platform/internal/orders/repositories"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	productRepo "github.com/devbenho/luka-platform/internal/product/repositories"
+	storeRepo "github.com/devbenho/luka-platform/internal/store/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IInvoiceService interface {
+	IssueInvoice(ctx context.Context, order *orderModels.Order) error
+	GetInvoiceByOrder(ctx context.Context, orderID string, actor orderModels.Actor) (*models.Invoice, error)
+}
+
+type InvoiceService struct {
+	db          database.IDatabase
+	repo        repositories.IInvoiceRepository
+	orderRepo   orderRepo.IOrderRepository
+	storeRepo   storeRepo.IStoreRepository
+	productRepo productRepo.IProductRepository
+	ownership   ownershipSvc.IOwnershipService
+}
+
+func NewInvoiceService(
+	db database.IDatabase,
+	repo repositories.IInvoiceRepository,
+	orderRepo orderRepo.IOrderRepository,
+	storeRepo storeRepo.IStoreRepository,
+	productRepo productRepo.IProductRepository,
+	ownership ownershipSvc.IOwnershipService,
+) *InvoiceService {
+	return &InvoiceService{
+		db:          db,
+		repo:        repo,
+		orderRepo:   orderRepo,
+		storeRepo:   storeRepo,
+		productRepo: productRepo,
+		ownership:   ownership,
+	}
+}
+
+// IssueInvoice bills an order under the next number of its store. It is meant
+// to run in the transaction that moves the order to PROCESSING, and does
+// nothing when the order was already invoiced. Orders placed before they
+// belonged to a store carry no seller and are numbered in a shared sequence.
+func (s *InvoiceService) IssueInvoice(ctx context.Context, order *orderModels.Order) error {
+	if _, err := s.repo.GetInvoiceByOrder(ctx, order.ID); err == nil {
+		return nil
+	} else if !stdErrors.Is(err, mongo.ErrNoDocuments) {
+		return errors.Wrap(err, "fetching invoice")
+	}
+
+	invoice := &models.Invoice{
+		OrderID:        order.ID,
+		CustomerID:     order.CustomerID,
+		BillTo:         order.ShippingAddress,
+		DiscountAmount: order.DiscountAmount,
+		Subtotal:       order.Subtotal,
+		TaxLines:       order.TaxLines,
+		TaxAmount:      order.TaxAmount,
+		ShippingAmount: order.ShippingAmount,
+		TotalAmount:    order.TotalAmount,
+		IssuedAt:       time.Now(),
+	}
+	if !order.StoreID.IsZero() {
+		store, err := s.storeRepo.GetStoreByID(ctx, order.StoreID.Hex())
+		if err != nil {
+			return errors.Wrap(err, "fetching store")
+		}
+		invoice.Seller = models.Seller{
+			StoreID:   store.ID,
+			Name:      store.Name,
+			Slug:      store.Slug,
+			Latitude:  store.Location.Latitude,
+			Longitude: store.Location.Longitude,
+		}
+	}
+	if order.Discount != nil {
+		invoice.Discount = order.Discount.Code
+	}
+	if order.Shipping != nil {
+		invoice.ShippingMethod = order.Shipping.MethodName
+	}
+	for _, item := range order.Items {
+		line, err := s.invoiceLine(ctx, item)
+		if err != nil {
+			return err
+		}
+		invoice.Lines = append(invoice.Lines, line)
+	}
+
+	var err error
+	if invoice.Sequence, err = s.repo.NextSequence(ctx, order.StoreID); err != nil {
+		return errors.Wrap(err, "numbering invoice")
+	}
+	invoice.Number = models.InvoiceNumber(invoice.Sequence)
+	if _, err := s.repo.CreateInvoice(ctx, invoice); err != nil {
+		return errors.Wrap(err, "creating invoice")
+	}
+	return nil
+}
+
+// GetInvoiceByOrder returns the invoice of an order to its customer or the
+// owner of its store. Orders that were processed before invoicing existed
+// are invoiced on first request.
+func (s *InvoiceService) GetInvoiceByOrder(ctx context.Context, orderID string, actor orderModels.Actor) (*models.Invoice, error) {
+	if orderID == "" {
+		return nil, errors.NewBadRequestError("order ID is required")
+	}
+	order, err := s.orderRepo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching order")
+	}
+	if actor.ID != order.CustomerID {
+		if err := s.ownership.AuthorizeSeller(ctx, actor, order.StoreID); err != nil {
+			return nil, err
+		}
+	}
+
+	invoice, err := s.repo.GetInvoiceByOrder(ctx, order.ID)
+	if err == nil {
+		return invoice, nil
+	}
+	if !stdErrors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errors.Wrap(err, "fetching invoice")
+	}
+	if !invoiced(order.Status) {
+		return nil, errors.NewNotFoundError("invoice", "order "+orderID+" has not been processed yet")
+	}
+
+	err = s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		if err := s.IssueInvoice(sessCtx, order); err != nil {
+			return err
+		}
+		invoice, err = s.repo.GetInvoiceByOrder(sessCtx, order.ID)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "issuing invoice")
+	}
+	return invoice, nil
+}
+
+func (s *InvoiceService) invoiceLine(ctx context.Context, item orderModels.OrderItem) (models.InvoiceLine, error) {
+	net, err := item.NetPrice()
+	if err != nil {
+		return models.InvoiceLine{}, errors.Wrap(err, "pricing invoice line")
+	}
+	line := models.InvoiceLine{
+		ProductID:    item.ProductID,
+		Description:  item.ProductID.Hex(),
+		Quantity:     item.Quantity,
+		UnitPrice:    item.UnitPrice,
+		Discount:     item.DiscountAmount,
+		TaxRate:      item.TaxRate,
+		TaxInclusive: item.TaxInclusive,
+		TaxAmount:    item.TaxAmount,
+		NetAmount:    net,
+	}
+	// Products deleted since the order was placed keep their ID as description.
+	if product, err := s.productRepo.GetProductByID(ctx, item.ProductID.Hex()); err == nil {
+		line.Description = product.Name
+	}
+	return line, nil
+}
+
+// invoiced reports whether an order in the status has gone through PROCESSING.
+func invoiced(status orderModels.OrderStatus) bool {
+	switch status {
+	case orderModels.OrderStatusProcessing,
+		orderModels.OrderStatusShipped,
+		orderModels.OrderStatusDelivered,
+		orderModels.OrderStatusPartiallyReturned,
+		orderModels.OrderStatusReturned:
+		return true
+	}
+	return false
+}
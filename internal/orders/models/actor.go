@@ -0,0 +1,41 @@
+package models
+
+import (
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Roles of the users acting on orders are those of rbac.
+const (
+	RoleBuyer    = rbac.RoleBuyer
+	RoleSeller   = rbac.RoleSeller
+	RoleSupplier = rbac.RoleSupplier
+	RoleAdmin    = rbac.RoleAdmin
+	// RoleSystem is used for transitions triggered by the platform itself,
+	// such as background jobs, rather than by a signed-in user.
+	RoleSystem = "system"
+)
+
+// Actor identifies who triggers an order operation.
+type Actor struct {
+	ID   primitive.ObjectID
+	Role string
+}
+
+// IsPrivileged reports whether the actor may act on any order: the platform
+// itself and admins.
+func (a Actor) IsPrivileged() bool {
+	return a.Role == RoleSystem || a.Role == RoleAdmin
+}
+
+// SystemActor is the actor for transitions made by the platform itself.
+var SystemActor = Actor{Role: RoleSystem}
+
+// NewActor builds an actor from the user ID and role of an authenticated request.
+func NewActor(userID, role string) (Actor, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return Actor{}, err
+	}
+	return Actor{ID: id, Role: role}, nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	shippingModels "github.com/devbenho/luka-platform/internal/shipping/models"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Checkout groups the orders a buyer placed in one go. Items are split into
+// one child order per store so that each seller fulfils only their part, and
+// each child order moves through its own status lifecycle.
+type Checkout struct {
+	ID              primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	CustomerID      primitive.ObjectID     `bson:"customerID" json:"customer_id"`
+	OrderIDs        []primitive.ObjectID   `bson:"orderIDs" json:"order_ids"`
+	CouponCode      string                 `bson:"couponCode,omitempty" json:"coupon_code,omitempty"`
+	DiscountAmount  money.Money            `bson:"discountAmount" json:"discount_amount"`
+	TaxAmount       money.Money            `bson:"taxAmount" json:"tax_amount"`
+	ShippingAmount  money.Money            `bson:"shippingAmount" json:"shipping_amount"`
+	TotalAmount     money.Money            `bson:"totalAmount" json:"total_amount"`
+	ShippingAddress shippingModels.Address `bson:"shippingAddress" json:"shipping_address"`
+	Notes           string                 `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedAt       time.Time              `bson:"createdAt" json:"created_at"`
+	UpdatedAt       time.Time              `bson:"updatedAt" json:"updated_at"`
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	shippingModels "github.com/devbenho/luka-platform/internal/shipping/models"
+	"github.com/devbenho/luka-platform/pkg/money"
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -12,30 +14,159 @@ type OrderStatus string
 const (
 	OrderStatusPending    OrderStatus = "PENDING"
 	OrderStatusProcessing OrderStatus = "PROCESSING"
-	OrderStatusConfirmed  OrderStatus = "confirmed"
+	OrderStatusConfirmed  OrderStatus = "CONFIRMED"
 	OrderStatusShipped    OrderStatus = "SHIPPED"
 	OrderStatusDelivered  OrderStatus = "DELIVERED"
 	OrderStatusCancelled  OrderStatus = "CANCELLED"
+
+	OrderStatusPartiallyReturned OrderStatus = "PARTIALLY_RETURNED"
+	OrderStatusReturned          OrderStatus = "RETURNED"
 )
 
 type OrderItem struct {
-	ProductID  primitive.ObjectID `bson:"productID" json:"product_id"`
-	Quantity   int                `bson:"quantity" json:"quantity"`
-	UnitPrice  float64            `bson:"unitPrice" json:"unit_price"`
-	TotalPrice float64            `bson:"totalPrice" json:"total_price"`
+	ProductID   primitive.ObjectID `bson:"productID" json:"product_id"`
+	StoreID     primitive.ObjectID `bson:"storeID,omitempty" json:"store_id,omitempty"`
+	InventoryID primitive.ObjectID `bson:"inventoryID,omitempty" json:"inventory_id,omitempty"`
+	WarehouseID primitive.ObjectID `bson:"warehouseID,omitempty" json:"warehouse_id,omitempty"`
+	Quantity    int                `bson:"quantity" json:"quantity"`
+	UnitPrice   money.Money        `bson:"unitPrice" json:"unit_price"`
+	TotalPrice  money.Money        `bson:"totalPrice" json:"total_price"`
+	// DiscountAmount is the part of TotalPrice taken off by a coupon.
+	DiscountAmount money.Money `bson:"discountAmount" json:"discount_amount"`
+	// Tax charged on the line. When TaxInclusive is set the tax is part of
+	// TotalPrice, otherwise it is added on top of it.
+	TaxRule      string      `bson:"taxRule,omitempty" json:"tax_rule,omitempty"`
+	TaxRate      int64       `bson:"taxRate" json:"tax_rate_basis_points"`
+	TaxInclusive bool        `bson:"taxInclusive" json:"tax_inclusive"`
+	TaxAmount    money.Money `bson:"taxAmount" json:"tax_amount"`
+	// WeightGrams is the weight of one unit, used to price shipping.
+	WeightGrams int64 `bson:"weightGrams,omitempty" json:"weight_grams,omitempty"`
+}
+
+// DiscountedPrice is the line total after discounts, as tax is charged on it.
+func (i OrderItem) DiscountedPrice() (money.Money, error) {
+	return i.TotalPrice.Sub(i.DiscountAmount)
+}
+
+// NetPrice is the line total after discounts and excluding tax.
+func (i OrderItem) NetPrice() (money.Money, error) {
+	price, err := i.DiscountedPrice()
+	if err != nil || !i.TaxInclusive {
+		return price, err
+	}
+	return price.Sub(i.TaxAmount)
+}
+
+// AppliedDiscount records the coupon used on an order.
+type AppliedDiscount struct {
+	Code         string      `bson:"code" json:"code"`
+	Type         string      `bson:"type" json:"type"`
+	FreeShipping bool        `bson:"freeShipping" json:"free_shipping"`
+	Amount       money.Money `bson:"amount" json:"amount"`
+}
+
+// TaxLine sums the tax an order owes under one rule and rate.
+type TaxLine struct {
+	Rule            string      `bson:"rule" json:"rule"`
+	RateBasisPoints int64       `bson:"rateBasisPoints" json:"rate_basis_points"`
+	Inclusive       bool        `bson:"inclusive" json:"inclusive"`
+	TaxableAmount   money.Money `bson:"taxableAmount" json:"taxable_amount"`
+	TaxAmount       money.Money `bson:"taxAmount" json:"tax_amount"`
+}
+
+// Cancellation records why, by whom and when an order was cancelled.
+type Cancellation struct {
+	Reason      string             `bson:"reason" json:"reason"`
+	CancelledBy primitive.ObjectID `bson:"cancelledBy,omitempty" json:"cancelled_by,omitempty"`
+	CancelledAt time.Time          `bson:"cancelledAt" json:"cancelled_at"`
+}
+
+// StockShortage describes an order line that could not be covered by stock.
+type StockShortage struct {
+	ProductID primitive.ObjectID `json:"product_id"`
+	Requested int                `json:"requested"`
+	Available int                `json:"available"`
 }
 
 type Order struct {
-	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	CustomerID      primitive.ObjectID `bson:"customerID" json:"customer_id"`
-	Items           []OrderItem        `bson:"items" json:"items"`
-	Status          OrderStatus        `bson:"status" json:"status"`
-	TotalAmount     float64            `bson:"totalAmount" json:"total_amount"`
-	ShippingAddress string             `bson:"shippingAddress" json:"shipping_address"`
-	Notes           string             `bson:"notes,omitempty" json:"notes,omitempty"`
-	CreatedAt       time.Time          `bson:"createdAt" json:"created_at"`
-	UpdatedAt       time.Time          `bson:"updatedAt" json:"updated_at"`
-	DeletedAt       *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	ID              primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	CustomerID      primitive.ObjectID     `bson:"customerID" json:"customer_id"`
+	CheckoutID      primitive.ObjectID     `bson:"checkoutID,omitempty" json:"checkout_id,omitempty"`
+	StoreID         primitive.ObjectID     `bson:"storeID,omitempty" json:"store_id,omitempty"`
+	Items           []OrderItem            `bson:"items" json:"items"`
+	Status          OrderStatus            `bson:"status" json:"status"`
+	Discount        *AppliedDiscount       `bson:"discount,omitempty" json:"discount,omitempty"`
+	DiscountAmount  money.Money            `bson:"discountAmount" json:"discount_amount"`
+	Subtotal        money.Money            `bson:"subtotal" json:"subtotal"`
+	TaxAmount       money.Money            `bson:"taxAmount" json:"tax_amount"`
+	TaxLines        []TaxLine              `bson:"taxLines,omitempty" json:"tax_lines,omitempty"`
+	TotalAmount     money.Money            `bson:"totalAmount" json:"total_amount"`
+	Shipping        *shippingModels.Quote  `bson:"shipping,omitempty" json:"shipping,omitempty"`
+	ShippingAmount  money.Money            `bson:"shippingAmount" json:"shipping_amount"`
+	ShippingAddress shippingModels.Address `bson:"shippingAddress" json:"shipping_address"`
+	Notes           string                 `bson:"notes,omitempty" json:"notes,omitempty"`
+	Cancellation    *Cancellation          `bson:"cancellation,omitempty" json:"cancellation,omitempty"`
+	CreatedAt       time.Time              `bson:"createdAt" json:"created_at"`
+	UpdatedAt       time.Time              `bson:"updatedAt" json:"updated_at"`
+	DeletedAt       *time.Time             `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+}
+
+// CalculateTotals derives the discount, the subtotal, the tax breakdown and
+// the total from the order's items and shipping amount. The subtotal is net
+// of discounts and excludes all tax; the total is what the buyer pays.
+func (o *Order) CalculateTotals() error {
+	var discount, subtotal, tax money.Money
+	var lines []TaxLine
+	for _, item := range o.Items {
+		net, err := item.NetPrice()
+		if err != nil {
+			return err
+		}
+		if discount, err = discount.Add(item.DiscountAmount); err != nil {
+			return err
+		}
+		if subtotal, err = subtotal.Add(net); err != nil {
+			return err
+		}
+		if tax, err = tax.Add(item.TaxAmount); err != nil {
+			return err
+		}
+		if item.TaxRule == "" {
+			continue
+		}
+
+		index := -1
+		for i, line := range lines {
+			if line.Rule == item.TaxRule && line.RateBasisPoints == item.TaxRate && line.Inclusive == item.TaxInclusive {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			lines = append(lines, TaxLine{Rule: item.TaxRule, RateBasisPoints: item.TaxRate, Inclusive: item.TaxInclusive})
+			index = len(lines) - 1
+		}
+		if lines[index].TaxableAmount, err = lines[index].TaxableAmount.Add(net); err != nil {
+			return err
+		}
+		if lines[index].TaxAmount, err = lines[index].TaxAmount.Add(item.TaxAmount); err != nil {
+			return err
+		}
+	}
+
+	total, err := subtotal.Add(tax)
+	if err != nil {
+		return err
+	}
+	if total, err = total.Add(o.ShippingAmount); err != nil {
+		return err
+	}
+	o.DiscountAmount = discount
+	o.Subtotal = subtotal
+	o.TaxAmount = tax
+	o.TaxLines = lines
+	o.TotalAmount = total
+	return nil
 }
 
 func (o *Order) Validate() error {
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrderStatusEvent is an append-only record of a single order status change.
+// The first event of an order has an empty From status.
+type OrderStatusEvent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrderID   primitive.ObjectID `bson:"orderID" json:"order_id"`
+	From      OrderStatus        `bson:"from,omitempty" json:"from,omitempty"`
+	To        OrderStatus        `bson:"to" json:"to"`
+	ActorID   primitive.ObjectID `bson:"actorID,omitempty" json:"actor_id,omitempty"`
+	Note      string             `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt" json:"created_at"`
+}
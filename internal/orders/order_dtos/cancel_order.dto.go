@@ -0,0 +1,5 @@
+package dtos
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -1,15 +1,22 @@
 package dtos
 
 import (
+	shippingModels "github.com/devbenho/luka-platform/internal/shipping/models"
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type CreateOrderRequest struct {
-	CustomerID      primitive.ObjectID       `json:"customerID" validate:"required"`
+	// CustomerID is the authenticated user placing the order, never taken
+	// from the request.
+	CustomerID      primitive.ObjectID       `json:"-" validate:"required"`
 	Items           []CreateOrderItemRequest `json:"items" validate:"required,min=1,dive"`
-	ShippingAddress string                   `json:"shippingAddress" validate:"required"`
-	Notes           string                   `json:"notes"`
+	ShippingAddress shippingModels.Address   `json:"shippingAddress" validate:"required"`
+	// ShippingMethodIDs picks at most one shipping method per store. Stores
+	// without a pick ship with their cheapest method.
+	ShippingMethodIDs []primitive.ObjectID `json:"shippingMethodIDs"`
+	Notes             string               `json:"notes"`
+	CouponCode        string               `json:"coupon_code" validate:"omitempty,max=50"`
 }
 
 type CreateOrderItemRequest struct {
@@ -0,0 +1,24 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/devbenho/luka-platform/pkg/database"
+)
+
+// ListOrdersQuery filters, sorts and pages an order listing. Statuses may be
+// repeated or comma separated, and the amount range is in major units of
+// Currency.
+type ListOrdersQuery struct {
+	CustomerID  string    `form:"customer_id" validate:"omitempty,mongodb"`
+	StoreID     string    `form:"store_id" validate:"omitempty,mongodb"`
+	Status      []string  `form:"status"`
+	CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Currency    string    `form:"currency" validate:"required_with=MinTotal MaxTotal,omitempty,len=3"`
+	MinTotal    string    `form:"min_total" validate:"omitempty,numeric"`
+	MaxTotal    string    `form:"max_total" validate:"omitempty,numeric"`
+	Sort        string    `form:"sort" validate:"omitempty,oneof=created_at updated_at total"`
+	Order       string    `form:"order" validate:"omitempty,oneof=asc desc"`
+	database.PageRequest
+}
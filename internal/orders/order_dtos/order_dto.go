@@ -1 +1,16 @@
 package dtos
+
+import "github.com/devbenho/luka-platform/internal/orders/models"
+
+// OrderDetailsResponse is an order optionally enriched with its status history.
+type OrderDetailsResponse struct {
+	models.Order
+	History []models.OrderStatusEvent `json:"history,omitempty"`
+}
+
+// CheckoutResponse is a checkout together with the per-store orders it was
+// split into.
+type CheckoutResponse struct {
+	models.Checkout
+	Orders []models.Order `json:"orders"`
+}
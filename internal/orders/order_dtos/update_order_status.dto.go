@@ -0,0 +1,8 @@
+package dtos
+
+import "github.com/devbenho/luka-platform/internal/orders/models"
+
+type UpdateOrderStatusRequest struct {
+	Status models.OrderStatus `json:"status" validate:"required"`
+	Note   string             `json:"note" validate:"max=500"`
+}
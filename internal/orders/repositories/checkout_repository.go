@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ICheckoutRepository interface {
+	CreateCheckout(ctx context.Context, checkout *models.Checkout) (*models.Checkout, error)
+	GetCheckoutByID(ctx context.Context, id string) (*models.Checkout, error)
+}
+
+type CheckoutRepository struct {
+	db database.IDatabase
+}
+
+func NewCheckoutRepository(db database.IDatabase) ICheckoutRepository {
+	return &CheckoutRepository{
+		db: db,
+	}
+}
+
+func (r *CheckoutRepository) CreateCheckout(ctx context.Context, checkout *models.Checkout) (*models.Checkout, error) {
+	if checkout.ID.IsZero() {
+		checkout.ID = primitive.NewObjectID()
+	}
+	checkout.CreatedAt = time.Now()
+	checkout.UpdatedAt = time.Now()
+	if err := r.db.Create(ctx, "checkouts", checkout); err != nil {
+		return nil, fmt.Errorf("failed to create checkout in db: %w", err)
+	}
+	return checkout, nil
+}
+
+func (r *CheckoutRepository) GetCheckoutByID(ctx context.Context, id string) (*models.Checkout, error) {
+	var checkout models.Checkout
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkout ID: %w", err)
+	}
+	filter := bson.M{"_id": objID}
+	if err := r.db.FindOne(ctx, "checkouts", filter, &checkout); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("checkout not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get checkout from db: %w", err)
+	}
+	return &checkout, nil
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IOrderHistoryRepository stores order status events. Events are only ever
+// appended; there is deliberately no update or delete.
+type IOrderHistoryRepository interface {
+	AppendEvent(ctx context.Context, event *models.OrderStatusEvent) error
+	GetEventsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.OrderStatusEvent, error)
+}
+
+type OrderHistoryRepository struct {
+	db database.IDatabase
+}
+
+func NewOrderHistoryRepository(db database.IDatabase) IOrderHistoryRepository {
+	return &OrderHistoryRepository{
+		db: db,
+	}
+}
+
+func (r *OrderHistoryRepository) AppendEvent(ctx context.Context, event *models.OrderStatusEvent) error {
+	event.ID = primitive.NewObjectID()
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if err := r.db.Create(ctx, "order_status_events", event); err != nil {
+		return fmt.Errorf("failed to append order status event: %w", err)
+	}
+	return nil
+}
+
+func (r *OrderHistoryRepository) GetEventsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.OrderStatusEvent, error) {
+	events := []models.OrderStatusEvent{}
+	filter := bson.M{"orderID": orderID}
+	if err := r.db.Find(ctx, "order_status_events", filter, &events); err != nil {
+		return nil, fmt.Errorf("failed to get order status events: %w", err)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	return events, nil
+}
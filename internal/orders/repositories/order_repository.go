@@ -16,7 +16,34 @@ type IOrderRepository interface {
 	CreateOrder(ctx context.Context, order *models.Order) (*models.Order, error)
 	UpdateOrder(ctx context.Context, id string, order *models.Order) error
 	GetOrderByID(ctx context.Context, id string) (*models.Order, error)
-	ListOrders(ctx context.Context, customerID string) ([]models.Order, error)
+	QueryOrders(ctx context.Context, query OrderQuery) ([]models.Order, *database.Page, error)
+	ListOrdersByCheckout(ctx context.Context, checkoutID primitive.ObjectID) ([]models.Order, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+// Sort keys of an order query.
+const (
+	SortByCreatedAt = "createdAt"
+	SortByUpdatedAt = "updatedAt"
+	SortByTotal     = "totalAmount.amount"
+)
+
+// OrderQuery selects orders. Zero fields do not filter. Orders come sorted by
+// SortBy and then by ID.
+type OrderQuery struct {
+	CustomerID  primitive.ObjectID
+	StoreID     primitive.ObjectID
+	Statuses    []models.OrderStatus
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Currency restricts the listing to orders paid in it; MinTotal and
+	// MaxTotal are in its minor units.
+	Currency   string
+	MinTotal   *int64
+	MaxTotal   *int64
+	SortBy     string
+	Descending bool
+	Page       database.PageRequest
 }
 
 type OrderRepository struct {
@@ -30,7 +57,9 @@ func NewOrderRepository(db database.IDatabase) IOrderRepository {
 }
 
 func (r *OrderRepository) CreateOrder(ctx context.Context, order *models.Order) (*models.Order, error) {
-	order.ID = primitive.NewObjectID()
+	if order.ID.IsZero() {
+		order.ID = primitive.NewObjectID()
+	}
 	order.CreatedAt = time.Now()
 	order.UpdatedAt = time.Now()
 	err := r.db.Create(ctx, "orders", order)
@@ -71,15 +100,87 @@ func (r *OrderRepository) GetOrderByID(ctx context.Context, id string) (*models.
 	return &order, nil
 }
 
-func (r *OrderRepository) ListOrders(ctx context.Context, customerID string) ([]models.Order, error) {
-	var orders []models.Order
-	custID, err := primitive.ObjectIDFromHex(customerID)
-	if err != nil {
-		return nil, err
-	}
-	filter := bson.M{"customer_id": custID}
+func (r *OrderRepository) ListOrdersByCheckout(ctx context.Context, checkoutID primitive.ObjectID) ([]models.Order, error) {
+	orders := []models.Order{}
+	filter := bson.M{"checkoutID": checkoutID}
 	if err := r.db.Find(ctx, "orders", filter, &orders); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to list checkout orders from db: %w", err)
 	}
 	return orders, nil
 }
+
+func (r *OrderRepository) QueryOrders(ctx context.Context, query OrderQuery) ([]models.Order, *database.Page, error) {
+	filter := bson.D{}
+	if !query.CustomerID.IsZero() {
+		filter = append(filter, bson.E{Key: "customerID", Value: query.CustomerID})
+	}
+	if !query.StoreID.IsZero() {
+		filter = append(filter, bson.E{Key: "storeID", Value: query.StoreID})
+	}
+	if len(query.Statuses) > 0 {
+		filter = append(filter, bson.E{Key: "status", Value: bson.M{"$in": query.Statuses}})
+	}
+	created := bson.M{}
+	if !query.CreatedFrom.IsZero() {
+		created["$gte"] = query.CreatedFrom
+	}
+	if !query.CreatedTo.IsZero() {
+		created["$lt"] = query.CreatedTo
+	}
+	if len(created) > 0 {
+		filter = append(filter, bson.E{Key: "createdAt", Value: created})
+	}
+	if query.Currency != "" {
+		filter = append(filter, bson.E{Key: "totalAmount.currency", Value: query.Currency})
+	}
+	total := bson.M{}
+	if query.MinTotal != nil {
+		total["$gte"] = *query.MinTotal
+	}
+	if query.MaxTotal != nil {
+		total["$lte"] = *query.MaxTotal
+	}
+	if len(total) > 0 {
+		filter = append(filter, bson.E{Key: "totalAmount.amount", Value: total})
+	}
+
+	direction := 1
+	if query.Descending {
+		direction = -1
+	}
+
+	orders := []models.Order{}
+	page, err := r.db.FindPage(ctx, "orders", database.Query{
+		Filter: filter,
+		Sort:   bson.D{{Key: query.SortBy, Value: direction}},
+		Page:   query.Page,
+	}, &orders)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query orders from db: %w", err)
+	}
+	return orders, page, nil
+}
+
+// EnsureIndexes creates the indexes that order listings and lookups rely on.
+// Each listing index ends with the sort key and _id so that pages are read in
+// index order.
+func (r *OrderRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, database.DatabaseTimeout)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "customerID", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "customerID", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "storeID", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "storeID", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "storeID", Value: 1}, {Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "storeID", Value: 1}, {Key: "totalAmount.currency", Value: 1}, {Key: "totalAmount.amount", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "checkoutID", Value: 1}}},
+	}
+	if _, err := r.db.GetDB().Collection("orders").Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("failed to create order indexes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/devbenho/luka-platform/internal/orders/models"
+)
+
+// INotifier tells interested parties about order status changes.
+type INotifier interface {
+	OrderStatusChanged(ctx context.Context, order *models.Order, from models.OrderStatus) error
+}
+
+// LogNotifier writes notifications to the application log. It stands in until
+// a real delivery channel such as email is wired up.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) OrderStatusChanged(ctx context.Context, order *models.Order, from models.OrderStatus) error {
+	log.Printf("order %s for customer %s moved from %s to %s", order.ID.Hex(), order.CustomerID.Hex(), from, order.Status)
+	return nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/devbenho/luka-platform/internal/orders/models"
+	dtos "github.com/devbenho/luka-platform/internal/orders/order_dtos"
+	"github.com/devbenho/luka-platform/internal/orders/repositories"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sortKeys maps the sort names of the API to the order fields they sort on.
+var sortKeys = map[string]string{
+	"created_at": repositories.SortByCreatedAt,
+	"updated_at": repositories.SortByUpdatedAt,
+	"total":      repositories.SortByTotal,
+}
+
+// buildOrderQuery turns a validated listing request, whose sort and order
+// are set, into a repository query.
+func buildOrderQuery(query dtos.ListOrdersQuery) (repositories.OrderQuery, error) {
+	result := repositories.OrderQuery{
+		CreatedFrom: query.CreatedFrom,
+		CreatedTo:   query.CreatedTo,
+		Page:        query.PageRequest,
+	}
+	if query.CustomerID != "" {
+		result.CustomerID, _ = primitive.ObjectIDFromHex(query.CustomerID)
+	}
+	if query.StoreID != "" {
+		result.StoreID, _ = primitive.ObjectIDFromHex(query.StoreID)
+	}
+	for _, value := range query.Status {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.ToUpper(strings.TrimSpace(status))
+			if status != "" {
+				result.Statuses = append(result.Statuses, models.OrderStatus(status))
+			}
+		}
+	}
+	if !query.CreatedFrom.IsZero() && !query.CreatedTo.IsZero() && !query.CreatedFrom.Before(query.CreatedTo) {
+		return result, errors.NewBadRequestError("created_from must be before created_to")
+	}
+
+	if query.Currency != "" {
+		result.Currency = strings.ToUpper(query.Currency)
+		bounds := []struct {
+			value  string
+			target **int64
+		}{{query.MinTotal, &result.MinTotal}, {query.MaxTotal, &result.MaxTotal}}
+		for _, bound := range bounds {
+			if bound.value == "" {
+				continue
+			}
+			amount, err := money.Parse(bound.value, result.Currency)
+			if err != nil {
+				return result, errors.NewBadRequestError("invalid total range: " + err.Error())
+			}
+			*bound.target = &amount.Amount
+		}
+	}
+
+	if query.Sort == "total" && result.Currency == "" {
+		return result, errors.NewBadRequestError("sorting by total requires a currency")
+	}
+	result.SortBy = sortKeys[query.Sort]
+	result.Descending = query.Order == "desc"
+	return result, nil
+}
@@ -2,50 +2,103 @@ package services
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
-	inventoryDTOs "github.com/devbenho/luka-platform/internal/inventory/dtos"
 	"github.com/devbenho/luka-platform/internal/inventory/services"
 	"github.com/devbenho/luka-platform/internal/orders/models"
 	dtos "github.com/devbenho/luka-platform/internal/orders/order_dtos"
 	"github.com/devbenho/luka-platform/internal/orders/repositories"
+	"github.com/devbenho/luka-platform/internal/orders/statemachine"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
 	productService "github.com/devbenho/luka-platform/internal/product/services"
-	"github.com/devbenho/luka-platform/internal/utils"
+	promoModels "github.com/devbenho/luka-platform/internal/promotions/models"
+	promoSvc "github.com/devbenho/luka-platform/internal/promotions/services"
+	shippingModels "github.com/devbenho/luka-platform/internal/shipping/models"
+	shippingSvc "github.com/devbenho/luka-platform/internal/shipping/services"
+	taxModels "github.com/devbenho/luka-platform/internal/tax/models"
+	taxSvc "github.com/devbenho/luka-platform/internal/tax/services"
+	warehouseRepo "github.com/devbenho/luka-platform/internal/warehouse/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
 	"github.com/devbenho/luka-platform/pkg/errors"
 	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type IOrderService interface {
-	CreateOrder(ctx context.Context, dto dtos.CreateOrderRequest) (*models.Order, error)
-	UpdateOrderStatus(ctx context.Context, id string, status models.OrderStatus) error
+	CreateOrder(ctx context.Context, dto dtos.CreateOrderRequest) (*dtos.CheckoutResponse, error)
+	UpdateOrderStatus(ctx context.Context, id string, actor models.Actor, dto dtos.UpdateOrderStatusRequest) error
+	CancelOrder(ctx context.Context, id string, actor models.Actor, dto dtos.CancelOrderRequest) (*models.Order, error)
 	GetOrderByID(ctx context.Context, id string) (*models.Order, error)
-	ListOrders(ctx context.Context, customerID string) ([]models.Order, error)
+	GetOrder(ctx context.Context, id string, actor models.Actor) (*models.Order, error)
+	GetOrderHistory(ctx context.Context, id string, actor models.Actor) ([]models.OrderStatusEvent, error)
+	ListOrders(ctx context.Context, actor models.Actor, query dtos.ListOrdersQuery) ([]models.Order, *database.Page, error)
+	GetCheckout(ctx context.Context, id string, actor models.Actor) (*dtos.CheckoutResponse, error)
 }
 
 type OrderService struct {
-	repo             repositories.IOrderRepository
-	inventoryService services.IInventoryService
-	productService   productService.IProductService
-	validator        *validation.Validator
+	db                 database.IDatabase
+	repo               repositories.IOrderRepository
+	historyRepo        repositories.IOrderHistoryRepository
+	checkoutRepo       repositories.ICheckoutRepository
+	inventoryService   services.IInventoryService
+	reservationService services.IReservationService
+	productService     productService.IProductService
+	taxCalculator      taxSvc.ITaxCalculator
+	promotionService   promoSvc.IPromotionService
+	shippingService    shippingSvc.IShippingService
+	warehouseRepo      warehouseRepo.IWarehouseRepository
+	ownership          ownershipSvc.IOwnershipService
+	validator          *validation.Validator
+	machine            *statemachine.StateMachine
+	reservationTTL     time.Duration
 }
 
 func NewOrderService(
+	db database.IDatabase,
 	repo repositories.IOrderRepository,
+	historyRepo repositories.IOrderHistoryRepository,
+	checkoutRepo repositories.ICheckoutRepository,
 	inventoryService services.IInventoryService,
+	reservationService services.IReservationService,
 	productService productService.IProductService,
+	taxCalculator taxSvc.ITaxCalculator,
+	promotionService promoSvc.IPromotionService,
+	shippingService shippingSvc.IShippingService,
+	warehouseRepo warehouseRepo.IWarehouseRepository,
+	ownership ownershipSvc.IOwnershipService,
 	validator *validation.Validator,
+	machine *statemachine.StateMachine,
+	reservationTTL time.Duration,
 ) *OrderService {
 	return &OrderService{
-		repo:             repo,
-		inventoryService: inventoryService,
-		productService:   productService,
-		validator:        validator,
+		db:                 db,
+		repo:               repo,
+		historyRepo:        historyRepo,
+		checkoutRepo:       checkoutRepo,
+		inventoryService:   inventoryService,
+		reservationService: reservationService,
+		productService:     productService,
+		taxCalculator:      taxCalculator,
+		promotionService:   promotionService,
+		shippingService:    shippingService,
+		warehouseRepo:      warehouseRepo,
+		ownership:          ownership,
+		validator:          validator,
+		machine:            machine,
+		reservationTTL:     reservationTTL,
 	}
 }
 
-func (s *OrderService) CreateOrder(ctx context.Context, dto dtos.CreateOrderRequest) (*models.Order, error) {
+// CreateOrder places a checkout for the requested items. Items are grouped by
+// the store selling them and every group becomes an order of its own, so each
+// seller only sees and fulfils their part. Stock for all orders is held in one
+// transaction: either the whole checkout is placed or none of it is.
+func (s *OrderService) CreateOrder(ctx context.Context, dto dtos.CreateOrderRequest) (*dtos.CheckoutResponse, error) {
 	if err := s.validator.ValidateStruct(dto); err != nil {
 		if validationErrors, ok := err.(errors.ValidationErrors); ok {
 			validationErrorsResult := validationErrors
@@ -53,164 +106,470 @@ func (s *OrderService) CreateOrder(ctx context.Context, dto dtos.CreateOrderRequ
 		}
 		return nil, err
 	}
+	dto.ShippingAddress.Country = strings.ToUpper(dto.ShippingAddress.Country)
 
-	orderItems, totalAmount, err := s.prepareOrderItems(ctx, dto.Items)
+	orderItems, discount, err := s.prepareOrderItems(ctx, dto)
 	if err != nil {
 		return nil, errors.Wrap(err, "preparing order items")
 	}
 
-	order := s.buildOrder(dto, orderItems, totalAmount)
+	var placed *dtos.CheckoutResponse
+	err = s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		// The callback may be retried on transient errors, so it must not
+		// depend on state left behind by a previous attempt.
+		items := make([]models.OrderItem, len(orderItems))
+		copy(items, orderItems)
+
+		checkout := &models.Checkout{
+			ID:              primitive.NewObjectID(),
+			CustomerID:      dto.CustomerID,
+			ShippingAddress: dto.ShippingAddress,
+			Notes:           dto.Notes,
+		}
+		if discount != nil {
+			checkout.CouponCode = discount.Code
+		}
 
-	if err := s.reserveInventory(ctx, orderItems); err != nil {
-		return nil, errors.Wrap(err, "reserving inventory")
-	}
+		var orders []*models.Order
+		var shortages []models.StockShortage
+		for _, group := range groupItemsByStore(items) {
+			order, err := s.buildOrder(dto, group, discount)
+			if err != nil {
+				return err
+			}
+			order.ID = primitive.NewObjectID()
+			order.CheckoutID = checkout.ID
+			order.StoreID = group[0].StoreID
+
+			short, err := s.reserveInventory(sessCtx, order.ID, order.Items)
+			if err != nil {
+				return err
+			}
+			shortages = append(shortages, short...)
+			orders = append(orders, order)
+		}
+		if len(shortages) > 0 {
+			return insufficientStockError(shortages)
+		}
+
+		// Shipping is priced once stock is held, as distance rates depend on
+		// the warehouses the items are taken from.
+		for _, order := range orders {
+			if err := s.applyShipping(sessCtx, order, dto.ShippingMethodIDs, discount); err != nil {
+				return err
+			}
+		}
+
+		placed = &dtos.CheckoutResponse{Orders: make([]models.Order, 0, len(orders))}
+		for _, order := range orders {
+			created, err := s.repo.CreateOrder(sessCtx, order)
+			if err != nil {
+				return errors.NewError(
+					errors.InternalServerType,
+					500,
+					fmt.Sprintf("creating order: %v", err),
+					errors.WithMetadata(map[string]interface{}{
+						"customer_id": dto.CustomerID,
+						"store_id":    order.StoreID,
+						"items_count": len(order.Items),
+					}),
+				)
+			}
+			if err := s.recordStatusChange(sessCtx, created, "", dto.CustomerID, ""); err != nil {
+				return err
+			}
+			checkout.OrderIDs = append(checkout.OrderIDs, created.ID)
+			if checkout.DiscountAmount, err = checkout.DiscountAmount.Add(created.DiscountAmount); err != nil {
+				return errors.Wrap(err, "totalling checkout")
+			}
+			if checkout.TaxAmount, err = checkout.TaxAmount.Add(created.TaxAmount); err != nil {
+				return errors.Wrap(err, "totalling checkout")
+			}
+			if checkout.ShippingAmount, err = checkout.ShippingAmount.Add(created.ShippingAmount); err != nil {
+				return errors.Wrap(err, "totalling checkout")
+			}
+			if checkout.TotalAmount, err = checkout.TotalAmount.Add(created.TotalAmount); err != nil {
+				return errors.Wrap(err, "totalling checkout")
+			}
+			placed.Orders = append(placed.Orders, *created)
+		}
 
-	createdOrder, err := s.repo.CreateOrder(ctx, order)
+		if discount != nil {
+			if err := s.promotionService.RedeemCoupon(sessCtx, discount, dto.CustomerID, checkout.ID); err != nil {
+				return err
+			}
+		}
+
+		created, err := s.checkoutRepo.CreateCheckout(sessCtx, checkout)
+		if err != nil {
+			return errors.Wrap(err, "creating checkout")
+		}
+		placed.Checkout = *created
+		return nil
+	})
 	if err != nil {
-		s.rollbackInventory(ctx, orderItems)
-		return nil, errors.NewError(
-			errors.InternalServerType,
-			500,
-			fmt.Sprintf("creating order: %v", err),
-			errors.WithMetadata(map[string]interface{}{
-				"customer_id": dto.CustomerID,
-				"items_count": len(dto.Items),
-			}),
-		)
+		return nil, errors.Wrap(err, "placing order")
 	}
 
-	return createdOrder, nil
+	return placed, nil
 }
 
-func (s *OrderService) prepareOrderItems(ctx context.Context, items []dtos.CreateOrderItemRequest) ([]models.OrderItem, float64, error) {
-	var totalAmount float64
-	orderItems := make([]models.OrderItem, len(items))
+// prepareOrderItems prices the requested items, applies the coupon if one was
+// given and taxes what remains. All products of one checkout must be priced
+// in the same currency.
+func (s *OrderService) prepareOrderItems(ctx context.Context, dto dtos.CreateOrderRequest) ([]models.OrderItem, *promoModels.Discount, error) {
+	orderItems := make([]models.OrderItem, len(dto.Items))
+	discountableItems := make([]promoModels.DiscountableItem, len(dto.Items))
 
-	for i, item := range items {
+	for i, item := range dto.Items {
 		product, err := s.productService.GetProductByID(ctx, item.ProductID.Hex())
 		if err != nil {
-			return nil, 0, errors.NewNotFoundError("product", item.ProductID.Hex())
+			return nil, nil, errors.NewNotFoundError("product", item.ProductID.Hex())
+		}
+		if err := product.Price.Validate(); err != nil {
+			return nil, nil, errors.NewError(
+				errors.InternalServerType,
+				500,
+				fmt.Sprintf("product %s has an invalid price", item.ProductID.Hex()),
+				errors.WithCause(err),
+			)
+		}
+		if i > 0 && !product.Price.SameCurrency(orderItems[0].UnitPrice) {
+			return nil, nil, errors.NewError(
+				errors.BadRequestType,
+				400,
+				"all items of an order must be priced in the same currency",
+				errors.WithMetadata(map[string]interface{}{
+					"currencies": []string{orderItems[0].UnitPrice.Currency, product.Price.Currency},
+				}),
+			)
 		}
 
-		inventory, err := s.inventoryService.GetInventoryByID(ctx, item.ProductID.Hex())
+		totalPrice, err := product.Price.Mul(int64(item.Quantity))
 		if err != nil {
-			return nil, 0, errors.NewNotFoundError("inventory", item.ProductID.Hex())
+			return nil, nil, errors.NewError(errors.ValidationErrorType, 400, "item total is out of range", errors.WithField("quantity"))
+		}
+		orderItems[i] = models.OrderItem{
+			ProductID:   item.ProductID,
+			StoreID:     product.StoreID,
+			Quantity:    item.Quantity,
+			UnitPrice:   product.Price,
+			TotalPrice:  totalPrice,
+			WeightGrams: product.WeightGrams,
 		}
+		discountableItems[i] = promoModels.DiscountableItem{
+			ProductID:  item.ProductID,
+			StoreID:    product.StoreID,
+			Categories: product.Categories,
+			Amount:     orderItems[i].TotalPrice,
+		}
+	}
 
-		if inventory.Quantity < item.Quantity {
-			return nil, 0, errors.Wrapf(err, "product_id: %s", item.ProductID.Hex())
+	var discount *promoModels.Discount
+	if dto.CouponCode != "" {
+		var err error
+		discount, err = s.promotionService.ApplyCoupon(ctx, dto.CouponCode, dto.CustomerID, discountableItems)
+		if err != nil {
+			return nil, nil, err
 		}
+		for i := range orderItems {
+			orderItems[i].DiscountAmount = discount.ItemAmounts[i]
+		}
+	}
 
-		itemTotal := float64(item.Quantity) * product.Price
-		orderItems[i] = models.OrderItem{
+	taxableItems := make([]taxModels.TaxableItem, len(orderItems))
+	for i, item := range orderItems {
+		price, err := item.DiscountedPrice()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "applying discount")
+		}
+		taxableItems[i] = taxModels.TaxableItem{
 			ProductID:  item.ProductID,
-			Quantity:   item.Quantity,
-			UnitPrice:  product.Price,
-			TotalPrice: itemTotal,
+			Categories: discountableItems[i].Categories,
+			Amount:     price,
 		}
-		totalAmount += itemTotal
 	}
 
-	return orderItems, totalAmount, nil
+	location := taxModels.Location{Country: dto.ShippingAddress.Country, Region: dto.ShippingAddress.Region}
+	taxes, err := s.taxCalculator.Calculate(ctx, location, taxableItems)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "calculating tax")
+	}
+	for i, tax := range taxes {
+		orderItems[i].TaxRule = tax.Rule
+		orderItems[i].TaxRate = tax.RateBasisPoints
+		orderItems[i].TaxInclusive = tax.Inclusive
+		orderItems[i].TaxAmount = tax.Amount
+	}
+
+	return orderItems, discount, nil
 }
 
-func (s *OrderService) buildOrder(dto dtos.CreateOrderRequest, items []models.OrderItem, totalAmount float64) *models.Order {
-	return &models.Order{
+// groupItemsByStore splits order items by the store selling them, keeping
+// stores in the order they first appear in the request.
+func groupItemsByStore(items []models.OrderItem) [][]models.OrderItem {
+	var groups [][]models.OrderItem
+	index := map[primitive.ObjectID]int{}
+	for _, item := range items {
+		i, ok := index[item.StoreID]
+		if !ok {
+			i = len(groups)
+			index[item.StoreID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], item)
+	}
+	return groups
+}
+
+func (s *OrderService) buildOrder(dto dtos.CreateOrderRequest, items []models.OrderItem, discount *promoModels.Discount) (*models.Order, error) {
+	order := &models.Order{
 		CustomerID:      dto.CustomerID,
 		Items:           items,
 		Status:          models.OrderStatusPending,
-		TotalAmount:     totalAmount,
 		ShippingAddress: dto.ShippingAddress,
 		Notes:           dto.Notes,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
+	if err := order.CalculateTotals(); err != nil {
+		return nil, errors.Wrap(err, "totalling order")
+	}
+	if discount != nil {
+		order.Discount = &models.AppliedDiscount{
+			Code:         discount.Code,
+			Type:         string(discount.Type),
+			FreeShipping: discount.FreeShipping,
+			Amount:       order.DiscountAmount,
+		}
+	}
+	return order, nil
 }
 
-func (s *OrderService) reserveInventory(ctx context.Context, items []models.OrderItem) error {
-	for _, item := range items {
-		updateReq := inventoryDTOs.UpdateInventoryRequest{
-			Quantity: utils.IntPtr(-item.Quantity),
+// applyShipping quotes delivery of the order from the warehouses its stock was
+// held in and adds the cost to the order total. A free shipping coupon
+// covering the order's store waives the cost but keeps the chosen method.
+func (s *OrderService) applyShipping(sessCtx mongo.SessionContext, order *models.Order, preferred []primitive.ObjectID, discount *promoModels.Discount) error {
+	shipment := shippingModels.Shipment{
+		StoreID:     order.StoreID,
+		Destination: order.ShippingAddress,
+		Subtotal:    order.Subtotal,
+	}
+	seen := map[primitive.ObjectID]bool{}
+	for _, item := range order.Items {
+		shipment.WeightGrams += item.WeightGrams * int64(item.Quantity)
+		if item.WarehouseID.IsZero() || seen[item.WarehouseID] {
+			continue
 		}
-		if _, err := s.inventoryService.UpdateInventory(ctx, item.ProductID.Hex(), updateReq); err != nil {
-			return errors.Wrap(err, "updating inventory quantity")
+		seen[item.WarehouseID] = true
+		warehouse, err := s.warehouseRepo.GetWarehouseByID(sessCtx, item.WarehouseID)
+		if err != nil {
+			return errors.Wrap(err, "finding warehouse")
+		}
+		shipment.Origins = append(shipment.Origins, shippingModels.Origin{
+			Latitude:  warehouse.Location.Latitude,
+			Longitude: warehouse.Location.Longitude,
+		})
+	}
+
+	quote, err := s.shippingService.Quote(sessCtx, shipment, preferred)
+	if err != nil {
+		return err
+	}
+	if quote == nil {
+		return nil
+	}
+	if discount != nil && discount.FreeShipping {
+		for _, storeID := range discount.ShippingStoreIDs {
+			if storeID == order.StoreID {
+				quote.Cost = quote.Cost.Zero()
+				break
+			}
 		}
 	}
+
+	order.Shipping = quote
+	order.ShippingAmount = quote.Cost
+	if err := order.CalculateTotals(); err != nil {
+		return errors.Wrap(err, "totalling order")
+	}
 	return nil
 }
 
-func (s *OrderService) rollbackInventory(ctx context.Context, items []models.OrderItem) {
-	for _, item := range items {
-		updateReq := inventoryDTOs.UpdateInventoryRequest{
-			Quantity: utils.IntPtr(item.Quantity),
-		}
-		if _, err := s.inventoryService.UpdateInventory(ctx, item.ProductID.String(), updateReq); err != nil {
-			log.Printf("failed to rollback inventory: %v", err)
+// reserveInventory places an expiring stock hold for every item and returns
+// the items that could not be covered, with the most a single warehouse could
+// hold for them. It must run inside a transaction so
+// that the caller can discard the holds that did succeed.
+func (s *OrderService) reserveInventory(sessCtx mongo.SessionContext, orderID primitive.ObjectID, items []models.OrderItem) ([]models.StockShortage, error) {
+	var shortages []models.StockShortage
+	for i, item := range items {
+		reservation, err := s.reservationService.HoldStock(sessCtx, orderID, item.ProductID, item.Quantity, s.reservationTTL)
+		if err != nil {
+			if !isInsufficientStock(err) {
+				return nil, errors.Wrap(err, "holding inventory")
+			}
+			available, err := s.inventoryService.GetAvailableQuantity(sessCtx, item.ProductID)
+			if err != nil {
+				return nil, errors.Wrap(err, "checking available stock")
+			}
+			shortages = append(shortages, models.StockShortage{
+				ProductID: item.ProductID,
+				Requested: item.Quantity,
+				Available: available,
+			})
+			continue
 		}
+		items[i].InventoryID = reservation.InventoryID
+		items[i].WarehouseID = reservation.WarehouseID
 	}
+	return shortages, nil
+}
+
+// insufficientStockError reports every short item at once so the buyer can
+// fix the whole cart in one go.
+func insufficientStockError(shortages []models.StockShortage) error {
+	return errors.NewError(
+		errors.InsufficientStock,
+		409,
+		fmt.Sprintf("insufficient stock for %d item(s)", len(shortages)),
+		errors.WithMetadata(map[string]interface{}{
+			"items": shortages,
+		}),
+	)
 }
 
-func (s *OrderService) UpdateOrderStatus(ctx context.Context, id string, status models.OrderStatus) error {
-	if !isValidStatus(status) {
+func isInsufficientStock(err error) bool {
+	appErr, ok := err.(*errors.AppError)
+	return ok && appErr.Type == errors.InsufficientStock
+}
+
+func isForbidden(err error) bool {
+	appErr, ok := err.(*errors.AppError)
+	return ok && appErr.Code == 403
+}
+
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, id string, actor models.Actor, dto dtos.UpdateOrderStatusRequest) error {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return validationErrors
+		}
+		return err
+	}
+	if !s.machine.HasState(dto.Status) {
 		return errors.NewBadRequestError("invalid order status")
 	}
 
-	order, err := s.GetOrderByID(ctx, id)
-	if err != nil {
-		return errors.Wrap(err, "fetching order")
-	}
+	return s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		order, err := s.GetOrderByID(sessCtx, id)
+		if err != nil {
+			return errors.Wrap(err, "fetching order")
+		}
 
-	if !isValidStatusTransition(order.Status, status) {
-		return errors.NewBadRequestError("invalid status transition")
+		return s.transition(sessCtx, &statemachine.Transition{
+			Order: order,
+			From:  order.Status,
+			To:    dto.Status,
+			Actor: actor,
+			Note:  dto.Note,
+		})
+	})
+}
+
+// CancelOrder cancels an order and returns all of its stock to inventory in a
+// single transaction. Only the customer, the owner of the order's store and
+// privileged actors may cancel it. Cancelling an already cancelled order is a
+// no-op that returns the order as it was first cancelled.
+func (s *OrderService) CancelOrder(ctx context.Context, id string, actor models.Actor, dto dtos.CancelOrderRequest) (*models.Order, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
 	}
 
-	order.Status = status
-	order.UpdatedAt = time.Now()
+	var cancelledOrder *models.Order
+	err := s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		order, err := s.GetOrderByID(sessCtx, id)
+		if err != nil {
+			return errors.Wrap(err, "fetching order")
+		}
+		if err := s.authorizeOrder(sessCtx, actor, order); err != nil {
+			return err
+		}
+		cancelledOrder = order
+		if order.Status == models.OrderStatusCancelled {
+			return nil
+		}
 
-	if err := s.repo.UpdateOrder(ctx, id, order); err != nil {
-		return errors.Wrap(err, "updating order status")
+		return s.transition(sessCtx, &statemachine.Transition{
+			Order: order,
+			From:  order.Status,
+			To:    models.OrderStatusCancelled,
+			Actor: actor,
+			Note:  dto.Reason,
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cancelling order")
 	}
-
-	return nil
+	return cancelledOrder, nil
 }
 
-func isValidStatusTransition(from, to models.OrderStatus) bool {
-	transitions := map[models.OrderStatus][]models.OrderStatus{
-		models.OrderStatusPending:    {models.OrderStatusProcessing, models.OrderStatusCancelled},
-		models.OrderStatusProcessing: {models.OrderStatusShipped, models.OrderStatusCancelled},
-		models.OrderStatusShipped:    {models.OrderStatusDelivered},
-		models.OrderStatusDelivered:  {},
-		models.OrderStatusCancelled:  {},
+// authorizeOrder lets the order's customer, the owner of its store and
+// privileged actors through.
+func (s *OrderService) authorizeOrder(ctx context.Context, actor models.Actor, order *models.Order) error {
+	if actor.ID == order.CustomerID {
+		return nil
 	}
+	return s.ownership.AuthorizeSeller(ctx, actor, order.StoreID)
+}
 
-	allowedTransitions, exists := transitions[from]
-	if !exists {
-		return false
+// transition moves an order through the state machine and saves it together
+// with its history entry. It must run inside a transaction so that the work
+// done by the state's hooks lands together with the status change. The
+// hooks that need the change committed are queued until the outermost
+// transaction commits, which may be a caller's.
+func (s *OrderService) transition(ctx context.Context, t *statemachine.Transition) error {
+	if err := s.machine.Fire(ctx, t); err != nil {
+		return err
 	}
 
-	for _, allowed := range allowedTransitions {
-		if to == allowed {
-			return true
-		}
+	t.Order.UpdatedAt = time.Now()
+	if err := s.repo.UpdateOrder(ctx, t.Order.ID.Hex(), t.Order); err != nil {
+		return errors.Wrap(err, "updating order status")
 	}
-	return false
+	if err := s.recordStatusChange(ctx, t.Order, t.From, t.Actor.ID, t.Note); err != nil {
+		return err
+	}
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		s.afterTransition(ctx, t)
+	})
+	return nil
 }
 
-func isValidStatus(status models.OrderStatus) bool {
-	validStatuses := []models.OrderStatus{
-		models.OrderStatusPending,
-		models.OrderStatusProcessing,
-		models.OrderStatusShipped,
-		models.OrderStatusDelivered,
-		models.OrderStatusCancelled,
+// afterTransition runs the hooks that must only see committed changes. Their
+// failures are logged rather than returned since the transition already stands.
+func (s *OrderService) afterTransition(ctx context.Context, t *statemachine.Transition) {
+	for _, err := range s.machine.Committed(ctx, t) {
+		log.Printf("order %s: after entering %s: %v", t.Order.ID.Hex(), t.To, err)
 	}
+}
 
-	for _, s := range validStatuses {
-		if status == s {
-			return true
-		}
+// recordStatusChange appends an entry to the order's status history. It runs
+// in the same transaction as the status change it describes.
+func (s *OrderService) recordStatusChange(ctx context.Context, order *models.Order, from models.OrderStatus, actor primitive.ObjectID, note string) error {
+	event := &models.OrderStatusEvent{
+		OrderID:   order.ID,
+		From:      from,
+		To:        order.Status,
+		ActorID:   actor,
+		Note:      note,
+		CreatedAt: order.UpdatedAt,
 	}
-	return false
+	if err := s.historyRepo.AppendEvent(ctx, event); err != nil {
+		return errors.Wrap(err, "recording status history")
+	}
+	return nil
 }
 
 func (s *OrderService) GetOrderByID(ctx context.Context, id string) (*models.Order, error) {
@@ -235,27 +594,126 @@ func (s *OrderService) GetOrderByID(ctx context.Context, id string) (*models.Ord
 	return order, nil
 }
 
-func (s *OrderService) ListOrders(ctx context.Context, customerID string) ([]models.Order, error) {
+// GetOrder returns an order to its customer, the owner of its store or a
+// privileged actor.
+func (s *OrderService) GetOrder(ctx context.Context, id string, actor models.Actor) (*models.Order, error) {
+	order, err := s.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeOrder(ctx, actor, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (s *OrderService) GetOrderHistory(ctx context.Context, id string, actor models.Actor) ([]models.OrderStatusEvent, error) {
+	order, err := s.GetOrder(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.historyRepo.GetEventsByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching order history")
+	}
+	return events, nil
+}
+
+// ListOrders returns one page of the orders matching query. Filtering by a
+// store is limited to its owner; everyone else only lists their own orders.
+func (s *OrderService) ListOrders(ctx context.Context, actor models.Actor, query dtos.ListOrdersQuery) ([]models.Order, *database.Page, error) {
 	if ctx.Err() != nil {
-		return nil, errors.Wrap(ctx.Err(), "context cancelled")
+		return nil, nil, errors.Wrap(ctx.Err(), "context cancelled")
+	}
+	if err := s.validator.ValidateStruct(query); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, nil, validationErrors
+		}
+		return nil, nil, err
 	}
 
-	if customerID == "" {
-		return nil, errors.NewBadRequestError("customer ID is required")
+	switch {
+	case actor.IsPrivileged():
+	case query.StoreID != "":
+		storeID, _ := primitive.ObjectIDFromHex(query.StoreID)
+		if err := s.ownership.AuthorizeSeller(ctx, actor, storeID); err != nil {
+			return nil, nil, err
+		}
+	default:
+		if query.CustomerID != "" && query.CustomerID != actor.ID.Hex() {
+			return nil, nil, errors.NewError(errors.UnauthorizedType, 403, "only your own orders and those of your stores can be listed")
+		}
+		query.CustomerID = actor.ID.Hex()
+	}
+	if query.Sort == "" {
+		query.Sort = "created_at"
+	}
+	if query.Order == "" {
+		query.Order = "desc"
 	}
 
-	orders, err := s.repo.ListOrders(ctx, customerID)
+	orderQuery, err := buildOrderQuery(query)
 	if err != nil {
-		// Use NewError when we need to add metadata
-		return nil, errors.NewError(
+		return nil, nil, err
+	}
+	for _, status := range orderQuery.Statuses {
+		if !s.machine.HasState(status) {
+			return nil, nil, errors.NewBadRequestError(fmt.Sprintf("invalid order status %q", status))
+		}
+	}
+
+	orders, page, err := s.repo.QueryOrders(ctx, orderQuery)
+	if stdErrors.Is(err, database.ErrInvalidCursor) {
+		return nil, nil, errors.NewBadRequestError("invalid cursor")
+	}
+	if err != nil {
+		return nil, nil, errors.NewError(
 			errors.InternalServerType,
 			500,
-			fmt.Sprintf("listing orders: %v", err),
+			"listing orders",
+			errors.WithCause(err),
 			errors.WithMetadata(map[string]interface{}{
-				"customer_id": customerID,
+				"customer_id": query.CustomerID,
+				"store_id":    query.StoreID,
 			}),
 		)
 	}
+	return orders, page, nil
+}
+
+// GetCheckout returns a checkout as the buyer sees it: one purchase made of
+// the per-store orders it was split into. Store owners only see the orders of
+// their stores.
+func (s *OrderService) GetCheckout(ctx context.Context, id string, actor models.Actor) (*dtos.CheckoutResponse, error) {
+	if id == "" {
+		return nil, errors.NewBadRequestError("checkout ID is required")
+	}
+
+	checkout, err := s.checkoutRepo.GetCheckoutByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching checkout")
+	}
+
+	orders, err := s.repo.ListOrdersByCheckout(ctx, checkout.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching checkout orders")
+	}
+	if actor.ID != checkout.CustomerID && !actor.IsPrivileged() {
+		var visible []models.Order
+		for _, order := range orders {
+			err := s.ownership.AuthorizeSeller(ctx, actor, order.StoreID)
+			if err == nil {
+				visible = append(visible, order)
+			} else if !isForbidden(err) {
+				return nil, err
+			}
+		}
+		if len(visible) == 0 {
+			return nil, errors.NewError(errors.UnauthorizedType, 403, "not allowed to see this checkout")
+		}
+		orders = visible
+	}
 
-	return orders, nil
+	return &dtos.CheckoutResponse{Checkout: *checkout, Orders: orders}, nil
 }
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"reflect"
+	"testing"
+	"time"
+
+	inventoryModels "github.com/devbenho/luka-platform/internal/inventory/models"
+	inventoryServices "github.com/devbenho/luka-platform/internal/inventory/services"
+	"github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeStock holds stock from one record per product, as many units as it has.
+type fakeStock struct {
+	inventoryServices.IReservationService
+	inventoryServices.IInventoryService
+	available map[primitive.ObjectID]int
+	failure   error
+	held      []primitive.ObjectID
+}
+
+func (f *fakeStock) HoldStock(ctx context.Context, orderID, productID primitive.ObjectID, quantity int, ttl time.Duration) (*inventoryModels.Reservation, error) {
+	if f.failure != nil {
+		return nil, f.failure
+	}
+	if f.available[productID] < quantity {
+		return nil, errors.NewError(errors.InsufficientStock, 409, "insufficient stock")
+	}
+	f.available[productID] -= quantity
+	f.held = append(f.held, productID)
+	return &inventoryModels.Reservation{
+		OrderID:     orderID,
+		ProductID:   productID,
+		InventoryID: productID,
+		WarehouseID: productID,
+		Quantity:    quantity,
+	}, nil
+}
+
+func (f *fakeStock) GetAvailableQuantity(ctx context.Context, productID primitive.ObjectID) (int, error) {
+	return f.available[productID], nil
+}
+
+func TestReserveInventory(t *testing.T) {
+	apples, pears, plums := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	tests := []struct {
+		name          string
+		available     map[primitive.ObjectID]int
+		items         []models.OrderItem
+		failure       error
+		wantShortages []models.StockShortage
+		wantHeld      []primitive.ObjectID
+		wantErr       bool
+	}{
+		{
+			name:      "holds every item",
+			available: map[primitive.ObjectID]int{apples: 5, pears: 2},
+			items:     []models.OrderItem{{ProductID: apples, Quantity: 5}, {ProductID: pears, Quantity: 1}},
+			wantHeld:  []primitive.ObjectID{apples, pears},
+		},
+		{
+			name:          "reports every short item with what is left",
+			available:     map[primitive.ObjectID]int{apples: 1, pears: 2, plums: 0},
+			items:         []models.OrderItem{{ProductID: apples, Quantity: 3}, {ProductID: pears, Quantity: 2}, {ProductID: plums, Quantity: 1}},
+			wantShortages: []models.StockShortage{{ProductID: apples, Requested: 3, Available: 1}, {ProductID: plums, Requested: 1, Available: 0}},
+			wantHeld:      []primitive.ObjectID{pears},
+		},
+		{
+			name:      "aborts on other errors",
+			available: map[primitive.ObjectID]int{apples: 5},
+			items:     []models.OrderItem{{ProductID: apples, Quantity: 1}},
+			failure:   stdErrors.New("write conflict"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stock := &fakeStock{available: tt.available, failure: tt.failure}
+			service := &OrderService{reservationService: stock, inventoryService: stock, reservationTTL: time.Minute}
+
+			shortages, err := service.reserveInventory(mongo.NewSessionContext(context.Background(), nil), primitive.NewObjectID(), tt.items)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reserveInventory() error = %v, want error %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(shortages, tt.wantShortages) {
+				t.Errorf("reserveInventory() shortages = %+v, want %+v", shortages, tt.wantShortages)
+			}
+			if !reflect.DeepEqual(stock.held, tt.wantHeld) {
+				t.Errorf("held %v, want %v", stock.held, tt.wantHeld)
+			}
+			held := map[primitive.ObjectID]bool{}
+			for _, id := range stock.held {
+				held[id] = true
+			}
+			for _, item := range tt.items {
+				if recorded := item.InventoryID == item.ProductID && item.WarehouseID == item.ProductID; recorded != held[item.ProductID] {
+					t.Errorf("item %s records its hold: %t, want %t", item.ProductID.Hex(), recorded, held[item.ProductID])
+				}
+			}
+		})
+	}
+}
+
+func TestInsufficientStockError(t *testing.T) {
+	shortages := []models.StockShortage{{ProductID: primitive.NewObjectID(), Requested: 3, Available: 1}}
+	err := insufficientStockError(shortages)
+	if err.(*errors.AppError).Code != 409 || !isInsufficientStock(err) {
+		t.Fatalf("insufficientStockError() = %v, want a 409 insufficient stock error", err)
+	}
+	if got := err.(*errors.AppError).Metadata["items"]; !reflect.DeepEqual(got, shortages) {
+		t.Errorf("metadata items = %v, want %v", got, shortages)
+	}
+}
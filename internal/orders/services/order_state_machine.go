@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	inventoryServices "github.com/devbenho/luka-platform/internal/inventory/services"
+	"github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/internal/orders/statemachine"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IPaymentCounter tells how many payments of an order have been captured.
+type IPaymentCounter interface {
+	CountCapturedPayments(ctx context.Context, orderID primitive.ObjectID) (int64, error)
+}
+
+// IPaymentReverser gives back the money taken or held for an order. Payments
+// are marked inside the cancel transaction and only reversed with the
+// provider once it has committed.
+type IPaymentReverser interface {
+	MarkForReversal(ctx context.Context, orderID primitive.ObjectID) error
+	ReverseOrderPayments(ctx context.Context, orderID primitive.ObjectID) error
+}
+
+// IShipmentCounter tells how many packages have been recorded for an order.
+type IShipmentCounter interface {
+	CountShipments(ctx context.Context, orderID primitive.ObjectID) (int64, error)
+}
+
+// IInvoiceIssuer bills an order once it is being processed.
+type IInvoiceIssuer interface {
+	IssueInvoice(ctx context.Context, order *models.Order) error
+}
+
+// NewOrderStateMachine builds the default order lifecycle:
+//
+//	PENDING -> CONFIRMED -> PROCESSING -> SHIPPED -> DELIVERED
+//	PENDING -> PROCESSING
+//	PENDING, CONFIRMED, PROCESSING -> CANCELLED
+//	DELIVERED -> PARTIALLY_RETURNED -> RETURNED
+//	DELIVERED -> RETURNED
+//
+// Only the owner of the order's store confirms, processes and ships it. The
+// customer may cancel it until it is being processed, the store owner until it
+// ships. Delivery and the return states are only entered by the system, once
+// the carrier reports delivery or returned goods arrive; admins pass every
+// other guard. An order is only processed once its payment has been captured,
+// and can only ship once its packages are recorded. Entering PROCESSING turns
+// the stock holds into deductions and issues the invoice, entering CANCELLED
+// puts the stock back and marks the order's payments for reversal, and once
+// the change is committed the marked payments are voided or refunded and
+// shipping notifies the customer.
+func NewOrderStateMachine(reservationService inventoryServices.IReservationService, payments IPaymentCounter, reverser IPaymentReverser, shipments IShipmentCounter, invoices IInvoiceIssuer, notifier INotifier, ownership ownershipSvc.IOwnershipService) *statemachine.StateMachine {
+	storeOwner := requireStoreOwner(ownership)
+	customerOrStoreOwner := requireCustomerOrStoreOwner(ownership)
+	systemOnly := statemachine.RequireRole(models.RoleSystem)
+	paid := requireCapturedPayment(payments)
+
+	return statemachine.New().
+		AddTransition(models.OrderStatusPending, models.OrderStatusConfirmed, storeOwner).
+		AddTransition(models.OrderStatusPending, models.OrderStatusProcessing, storeOwner, paid).
+		AddTransition(models.OrderStatusPending, models.OrderStatusCancelled, customerOrStoreOwner).
+		AddTransition(models.OrderStatusConfirmed, models.OrderStatusProcessing, storeOwner, paid).
+		AddTransition(models.OrderStatusConfirmed, models.OrderStatusCancelled, customerOrStoreOwner).
+		AddTransition(models.OrderStatusProcessing, models.OrderStatusShipped, storeOwner, requireShipments(shipments)).
+		AddTransition(models.OrderStatusProcessing, models.OrderStatusCancelled, storeOwner).
+		AddTransition(models.OrderStatusShipped, models.OrderStatusDelivered, systemOnly).
+		AddTransition(models.OrderStatusDelivered, models.OrderStatusPartiallyReturned, systemOnly).
+		AddTransition(models.OrderStatusDelivered, models.OrderStatusReturned, systemOnly).
+		AddTransition(models.OrderStatusPartiallyReturned, models.OrderStatusPartiallyReturned, systemOnly).
+		AddTransition(models.OrderStatusPartiallyReturned, models.OrderStatusReturned, systemOnly).
+		OnEnter(models.OrderStatusProcessing, commitStock(reservationService), issueInvoice(invoices)).
+		OnEnter(models.OrderStatusCancelled, recordCancellation, restockItems(reservationService), markPaymentsForReversal(reverser)).
+		AfterEnter(models.OrderStatusCancelled, reversePayments(reverser)).
+		AfterEnter(models.OrderStatusShipped, notifyStatusChange(notifier))
+}
+
+// requireStoreOwner only lets the owner of the order's store, admins and the
+// system make the transition.
+func requireStoreOwner(ownership ownershipSvc.IOwnershipService) statemachine.Guard {
+	return func(ctx context.Context, t *statemachine.Transition) error {
+		return ownership.AuthorizeSeller(ctx, t.Actor, t.Order.StoreID)
+	}
+}
+
+// requireCustomerOrStoreOwner also lets the customer who placed the order make
+// the transition.
+func requireCustomerOrStoreOwner(ownership ownershipSvc.IOwnershipService) statemachine.Guard {
+	return func(ctx context.Context, t *statemachine.Transition) error {
+		if t.Actor.ID == t.Order.CustomerID {
+			return nil
+		}
+		return ownership.AuthorizeSeller(ctx, t.Actor, t.Order.StoreID)
+	}
+}
+
+// requireCapturedPayment keeps an order from being processed before the
+// buyer's money has been taken.
+func requireCapturedPayment(payments IPaymentCounter) statemachine.Guard {
+	return func(ctx context.Context, t *statemachine.Transition) error {
+		count, err := payments.CountCapturedPayments(ctx, t.Order.ID)
+		if err != nil {
+			return errors.Wrap(err, "counting payments")
+		}
+		if count == 0 {
+			return errors.NewError(errors.PaymentFailed, 402, "order cannot be processed before its payment is captured")
+		}
+		return nil
+	}
+}
+
+// requireShipments refuses to ship an order before any package was recorded
+// for it, so that shipped orders always carry tracking information.
+func requireShipments(shipments IShipmentCounter) statemachine.Guard {
+	return func(ctx context.Context, t *statemachine.Transition) error {
+		count, err := shipments.CountShipments(ctx, t.Order.ID)
+		if err != nil {
+			return errors.Wrap(err, "counting shipments")
+		}
+		if count == 0 {
+			return errors.NewConflictError("record the order's shipments to ship it")
+		}
+		return nil
+	}
+}
+
+// commitStock turns the order's stock holds into permanent deductions once
+// the order is being processed.
+func commitStock(reservationService inventoryServices.IReservationService) statemachine.Hook {
+	return func(ctx context.Context, t *statemachine.Transition) error {
+		if err := reservationService.CommitReservations(ctx, t.Order.ID); err != nil {
+			return errors.Wrap(err, "committing stock reservations")
+		}
+		return nil
+	}
+}
+
+// issueInvoice bills the order in the transaction that starts processing it,
+// so that invoice numbers are only used by orders that were processed.
+func issueInvoice(invoices IInvoiceIssuer) statemachine.Hook {
+	return func(ctx context.Context, t *statemachine.Transition) error {
+		if err := invoices.IssueInvoice(ctx, t.Order); err != nil {
+			return errors.Wrap(err, "issuing invoice")
+		}
+		return nil
+	}
+}
+
+// restockItems returns every held or deducted unit of the order to stock.
+func restockItems(reservationService inventoryServices.IReservationService) statemachine.Hook {
+	return func(ctx context.Context, t *statemachine.Transition) error {
+		if err := reservationService.ReleaseReservations(ctx, t.Order.ID); err != nil {
+			return errors.Wrap(err, "restocking order items")
+		}
+		return nil
+	}
+}
+
+// markPaymentsForReversal flags the order's payments to be given back in the
+// transaction that cancels it, so that the flag lands with the cancellation.
+func markPaymentsForReversal(reverser IPaymentReverser) statemachine.Hook {
+	return func(ctx context.Context, t *statemachine.Transition) error {
+		if err := reverser.MarkForReversal(ctx, t.Order.ID); err != nil {
+			return errors.Wrap(err, "marking payments for reversal")
+		}
+		return nil
+	}
+}
+
+// reversePayments voids or refunds the marked payments once the cancellation
+// has committed. The provider is never called from inside the transaction,
+// which may be retried or rolled back after the money went back; payments it
+// fails to reverse stay marked and are retried in the background.
+func reversePayments(reverser IPaymentReverser) statemachine.Hook {
+	return func(ctx context.Context, t *statemachine.Transition) error {
+		if err := reverser.ReverseOrderPayments(ctx, t.Order.ID); err != nil {
+			return errors.Wrap(err, "reversing payments")
+		}
+		return nil
+	}
+}
+
+func recordCancellation(ctx context.Context, t *statemachine.Transition) error {
+	t.Order.Cancellation = &models.Cancellation{
+		Reason:      t.Note,
+		CancelledBy: t.Actor.ID,
+		CancelledAt: time.Now(),
+	}
+	return nil
+}
+
+func notifyStatusChange(notifier INotifier) statemachine.Hook {
+	return func(ctx context.Context, t *statemachine.Transition) error {
+		return notifier.OrderStatusChanged(ctx, t.Order, t.From)
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"reflect"
+	"testing"
+
+	inventoryServices "github.com/devbenho/luka-platform/internal/inventory/services"
+	"github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/internal/orders/statemachine"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// lifecycle records the collaborators the order state machine called, in order.
+type lifecycle struct {
+	calls     []string
+	captured  int64
+	shipments int64
+}
+
+type fakeReservations struct {
+	inventoryServices.IReservationService
+	*lifecycle
+}
+
+func (f fakeReservations) CommitReservations(ctx context.Context, orderID primitive.ObjectID) error {
+	f.calls = append(f.calls, "commit stock")
+	return nil
+}
+
+func (f fakeReservations) ReleaseReservations(ctx context.Context, orderID primitive.ObjectID) error {
+	f.calls = append(f.calls, "release stock")
+	return nil
+}
+
+func (l *lifecycle) CountCapturedPayments(ctx context.Context, orderID primitive.ObjectID) (int64, error) {
+	return l.captured, nil
+}
+
+func (l *lifecycle) MarkForReversal(ctx context.Context, orderID primitive.ObjectID) error {
+	l.calls = append(l.calls, "mark payments")
+	return nil
+}
+
+func (l *lifecycle) ReverseOrderPayments(ctx context.Context, orderID primitive.ObjectID) error {
+	l.calls = append(l.calls, "reverse payments")
+	return nil
+}
+
+func (l *lifecycle) CountShipments(ctx context.Context, orderID primitive.ObjectID) (int64, error) {
+	return l.shipments, nil
+}
+
+func (l *lifecycle) IssueInvoice(ctx context.Context, order *models.Order) error {
+	l.calls = append(l.calls, "issue invoice")
+	return nil
+}
+
+func (l *lifecycle) OrderStatusChanged(ctx context.Context, order *models.Order, from models.OrderStatus) error {
+	l.calls = append(l.calls, "notify")
+	return nil
+}
+
+// fakeOwnership knows the owner of a single store.
+type fakeOwnership struct {
+	ownershipSvc.IOwnershipService
+	storeID primitive.ObjectID
+	ownerID primitive.ObjectID
+}
+
+func (f fakeOwnership) AuthorizeSeller(ctx context.Context, actor models.Actor, storeID primitive.ObjectID) error {
+	if actor.IsPrivileged() || (storeID == f.storeID && actor.ID == f.ownerID) {
+		return nil
+	}
+	return errors.NewError(errors.UnauthorizedType, 403, "only the owner of the store may do this")
+}
+
+func newTestStateMachine(l *lifecycle, ownership fakeOwnership) *statemachine.StateMachine {
+	return NewOrderStateMachine(fakeReservations{lifecycle: l}, l, l, l, l, l, ownership)
+}
+
+func errorCode(err error) int {
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return 0
+}
+
+func TestOrderStateMachineTransitions(t *testing.T) {
+	ownership := fakeOwnership{storeID: primitive.NewObjectID(), ownerID: primitive.NewObjectID()}
+	customer := models.Actor{ID: primitive.NewObjectID(), Role: models.RoleBuyer}
+	owner := models.Actor{ID: ownership.ownerID, Role: models.RoleSeller}
+	otherSeller := models.Actor{ID: primitive.NewObjectID(), Role: models.RoleSeller}
+	admin := models.Actor{ID: primitive.NewObjectID(), Role: models.RoleAdmin}
+	system := models.SystemActor
+
+	tests := []struct {
+		name      string
+		from, to  models.OrderStatus
+		actor     models.Actor
+		captured  int64
+		shipments int64
+		wantCode  int
+	}{
+		{"owner confirms", models.OrderStatusPending, models.OrderStatusConfirmed, owner, 0, 0, 0},
+		{"admin confirms", models.OrderStatusPending, models.OrderStatusConfirmed, admin, 0, 0, 0},
+		{"customer cannot confirm", models.OrderStatusPending, models.OrderStatusConfirmed, customer, 0, 0, 403},
+		{"other seller cannot confirm", models.OrderStatusPending, models.OrderStatusConfirmed, otherSeller, 0, 0, 403},
+		{"processing needs a captured payment", models.OrderStatusConfirmed, models.OrderStatusProcessing, owner, 0, 0, 402},
+		{"owner processes a paid order", models.OrderStatusConfirmed, models.OrderStatusProcessing, owner, 1, 0, 0},
+		{"pending order is processed once paid", models.OrderStatusPending, models.OrderStatusProcessing, system, 1, 0, 0},
+		{"customer cannot process", models.OrderStatusConfirmed, models.OrderStatusProcessing, customer, 1, 0, 403},
+		{"customer cancels a pending order", models.OrderStatusPending, models.OrderStatusCancelled, customer, 0, 0, 0},
+		{"customer cancels a confirmed order", models.OrderStatusConfirmed, models.OrderStatusCancelled, customer, 0, 0, 0},
+		{"customer cannot cancel once processing", models.OrderStatusProcessing, models.OrderStatusCancelled, customer, 1, 0, 403},
+		{"owner cancels while processing", models.OrderStatusProcessing, models.OrderStatusCancelled, owner, 1, 0, 0},
+		{"other seller cannot cancel", models.OrderStatusPending, models.OrderStatusCancelled, otherSeller, 0, 0, 403},
+		{"shipping needs shipments", models.OrderStatusProcessing, models.OrderStatusShipped, owner, 1, 0, 409},
+		{"owner ships recorded packages", models.OrderStatusProcessing, models.OrderStatusShipped, owner, 1, 2, 0},
+		{"shipped orders cannot be cancelled", models.OrderStatusShipped, models.OrderStatusCancelled, owner, 1, 1, 400},
+		{"only the system delivers", models.OrderStatusShipped, models.OrderStatusDelivered, owner, 1, 1, 403},
+		{"admins do not deliver", models.OrderStatusShipped, models.OrderStatusDelivered, admin, 1, 1, 403},
+		{"system delivers", models.OrderStatusShipped, models.OrderStatusDelivered, system, 1, 1, 0},
+		{"system records a partial return", models.OrderStatusDelivered, models.OrderStatusPartiallyReturned, system, 1, 1, 0},
+		{"partial returns accumulate", models.OrderStatusPartiallyReturned, models.OrderStatusPartiallyReturned, system, 1, 1, 0},
+		{"system completes a return", models.OrderStatusPartiallyReturned, models.OrderStatusReturned, system, 1, 1, 0},
+		{"customer cannot mark returned", models.OrderStatusDelivered, models.OrderStatusReturned, customer, 1, 1, 403},
+		{"delivered orders cannot be cancelled", models.OrderStatusDelivered, models.OrderStatusCancelled, system, 1, 1, 400},
+		{"cancelled is terminal", models.OrderStatusCancelled, models.OrderStatusPending, system, 0, 0, 400},
+		{"returned is terminal", models.OrderStatusReturned, models.OrderStatusDelivered, system, 1, 1, 400},
+		{"no skipping to shipped", models.OrderStatusPending, models.OrderStatusShipped, owner, 1, 1, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machine := newTestStateMachine(&lifecycle{captured: tt.captured, shipments: tt.shipments}, ownership)
+			order := &models.Order{ID: primitive.NewObjectID(), CustomerID: customer.ID, StoreID: ownership.storeID, Status: tt.from}
+			err := machine.Validate(context.Background(), &statemachine.Transition{Order: order, From: tt.from, To: tt.to, Actor: tt.actor})
+			if got := errorCode(err); got != tt.wantCode || (tt.wantCode == 0) != (err == nil) {
+				t.Fatalf("%s -> %s by %s = %v, want code %d", tt.from, tt.to, tt.actor.Role, err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOrderStateMachineHooks(t *testing.T) {
+	ownership := fakeOwnership{storeID: primitive.NewObjectID(), ownerID: primitive.NewObjectID()}
+	owner := models.Actor{ID: ownership.ownerID, Role: models.RoleSeller}
+
+	tests := []struct {
+		name          string
+		from, to      models.OrderStatus
+		wantFired     []string
+		wantCommitted []string
+	}{
+		{"processing deducts stock and bills", models.OrderStatusConfirmed, models.OrderStatusProcessing, []string{"commit stock", "issue invoice"}, nil},
+		{"cancelling restocks and marks payments in the transaction", models.OrderStatusConfirmed, models.OrderStatusCancelled, []string{"release stock", "mark payments"}, []string{"reverse payments"}},
+		{"shipping notifies once committed", models.OrderStatusProcessing, models.OrderStatusShipped, nil, []string{"notify"}},
+		{"confirming has no side effects", models.OrderStatusPending, models.OrderStatusConfirmed, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &lifecycle{captured: 1, shipments: 1}
+			machine := newTestStateMachine(l, ownership)
+			order := &models.Order{ID: primitive.NewObjectID(), StoreID: ownership.storeID, Status: tt.from}
+			transition := &statemachine.Transition{Order: order, From: tt.from, To: tt.to, Actor: owner, Note: "out of stock"}
+
+			if err := machine.Fire(context.Background(), transition); err != nil {
+				t.Fatalf("Fire() = %v", err)
+			}
+			if order.Status != tt.to {
+				t.Errorf("order status = %s, want %s", order.Status, tt.to)
+			}
+			if !reflect.DeepEqual(l.calls, tt.wantFired) {
+				t.Errorf("Fire() called %v, want %v", l.calls, tt.wantFired)
+			}
+
+			l.calls = nil
+			if errs := machine.Committed(context.Background(), transition); len(errs) != 0 {
+				t.Fatalf("Committed() = %v", errs)
+			}
+			if !reflect.DeepEqual(l.calls, tt.wantCommitted) {
+				t.Errorf("Committed() called %v, want %v", l.calls, tt.wantCommitted)
+			}
+
+			if tt.to == models.OrderStatusCancelled {
+				if order.Cancellation == nil || order.Cancellation.Reason != "out of stock" || order.Cancellation.CancelledBy != owner.ID {
+					t.Errorf("cancellation = %+v, want reason and actor recorded", order.Cancellation)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package statemachine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/devbenho/luka-platform/pkg/errors"
+)
+
+// RequireRole only lets actors with one of the given roles make the transition.
+func RequireRole(roles ...string) Guard {
+	return func(ctx context.Context, t *Transition) error {
+		for _, role := range roles {
+			if t.Actor.Role == role {
+				return nil
+			}
+		}
+		return errors.NewError(
+			errors.UnauthorizedType,
+			403,
+			fmt.Sprintf("only %s may move an order to %s", strings.Join(roles, " or "), t.To),
+		)
+	}
+}
@@ -0,0 +1,142 @@
+package statemachine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/pkg/errors"
+)
+
+// Transition describes a single status change of an order as it moves through
+// the state machine. Hooks may modify Order; the caller persists it afterwards.
+type Transition struct {
+	Order *models.Order
+	From  models.OrderStatus
+	To    models.OrderStatus
+	Actor models.Actor
+	Note  string
+}
+
+// Guard decides whether a transition may happen. A non-nil error rejects it.
+type Guard func(ctx context.Context, t *Transition) error
+
+// Hook reacts to an order entering a state.
+type Hook func(ctx context.Context, t *Transition) error
+
+// StateMachine holds the order states, the allowed transitions between them,
+// the guards protecting each transition and the hooks run on entering a state.
+//
+// OnEnter hooks run inside the caller's transaction before the order is
+// saved, so a failing hook aborts the whole change. AfterEnter hooks run once
+// the change is committed and are meant for side effects such as notifications.
+type StateMachine struct {
+	states      map[models.OrderStatus]bool
+	transitions map[models.OrderStatus]map[models.OrderStatus][]Guard
+	onEnter     map[models.OrderStatus][]Hook
+	afterEnter  map[models.OrderStatus][]Hook
+}
+
+func New() *StateMachine {
+	return &StateMachine{
+		states:      map[models.OrderStatus]bool{},
+		transitions: map[models.OrderStatus]map[models.OrderStatus][]Guard{},
+		onEnter:     map[models.OrderStatus][]Hook{},
+		afterEnter:  map[models.OrderStatus][]Hook{},
+	}
+}
+
+// AddStates registers states, including terminal ones without transitions.
+func (m *StateMachine) AddStates(states ...models.OrderStatus) *StateMachine {
+	for _, state := range states {
+		m.states[state] = true
+	}
+	return m
+}
+
+// AddTransition allows moving from one state to another when all guards pass.
+// Both states are registered if they are not known yet.
+func (m *StateMachine) AddTransition(from, to models.OrderStatus, guards ...Guard) *StateMachine {
+	m.AddStates(from, to)
+	if m.transitions[from] == nil {
+		m.transitions[from] = map[models.OrderStatus][]Guard{}
+	}
+	m.transitions[from][to] = append(m.transitions[from][to], guards...)
+	return m
+}
+
+// OnEnter registers hooks that run in the transaction that enters state.
+func (m *StateMachine) OnEnter(state models.OrderStatus, hooks ...Hook) *StateMachine {
+	m.AddStates(state)
+	m.onEnter[state] = append(m.onEnter[state], hooks...)
+	return m
+}
+
+// AfterEnter registers hooks that run after entering state has been committed.
+func (m *StateMachine) AfterEnter(state models.OrderStatus, hooks ...Hook) *StateMachine {
+	m.AddStates(state)
+	m.afterEnter[state] = append(m.afterEnter[state], hooks...)
+	return m
+}
+
+func (m *StateMachine) HasState(state models.OrderStatus) bool {
+	return m.states[state]
+}
+
+func (m *StateMachine) CanTransition(from, to models.OrderStatus) bool {
+	_, ok := m.transitions[from][to]
+	return ok
+}
+
+// AllowedTransitions lists the states reachable from the given state.
+func (m *StateMachine) AllowedTransitions(from models.OrderStatus) []models.OrderStatus {
+	allowed := make([]models.OrderStatus, 0, len(m.transitions[from]))
+	for to := range m.transitions[from] {
+		allowed = append(allowed, to)
+	}
+	return allowed
+}
+
+// Validate checks that the transition is known and that all of its guards pass.
+func (m *StateMachine) Validate(ctx context.Context, t *Transition) error {
+	if !m.HasState(t.To) {
+		return errors.NewBadRequestError(fmt.Sprintf("invalid order status: %s", t.To))
+	}
+	guards, ok := m.transitions[t.From][t.To]
+	if !ok {
+		return errors.NewBadRequestError(fmt.Sprintf("invalid status transition from %s to %s", t.From, t.To))
+	}
+	for _, guard := range guards {
+		if err := guard(ctx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fire validates the transition, moves the order into the new state and runs
+// the OnEnter hooks of that state.
+func (m *StateMachine) Fire(ctx context.Context, t *Transition) error {
+	if err := m.Validate(ctx, t); err != nil {
+		return err
+	}
+	t.Order.Status = t.To
+	for _, hook := range m.onEnter[t.To] {
+		if err := hook(ctx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Committed runs the AfterEnter hooks of the state entered by t. It returns
+// every hook error so the caller can log them; the transition itself stands.
+func (m *StateMachine) Committed(ctx context.Context, t *Transition) []error {
+	var errs []error
+	for _, hook := range m.afterEnter[t.To] {
+		if err := hook(ctx, t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
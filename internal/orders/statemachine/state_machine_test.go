@@ -0,0 +1,171 @@
+package statemachine
+
+import (
+	"context"
+	stdErrors "errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/pkg/errors"
+)
+
+func errorCode(err error) int {
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return 0
+}
+
+func TestValidate(t *testing.T) {
+	deny := func(ctx context.Context, t *Transition) error {
+		return errors.NewError(errors.UnauthorizedType, 403, "denied")
+	}
+	allow := func(ctx context.Context, t *Transition) error { return nil }
+	machine := New().
+		AddStates(models.OrderStatusReturned).
+		AddTransition(models.OrderStatusPending, models.OrderStatusConfirmed, allow).
+		AddTransition(models.OrderStatusPending, models.OrderStatusCancelled, allow, deny).
+		AddTransition(models.OrderStatusConfirmed, models.OrderStatusShipped)
+
+	tests := []struct {
+		name     string
+		from, to models.OrderStatus
+		wantCode int
+	}{
+		{"guards pass", models.OrderStatusPending, models.OrderStatusConfirmed, 0},
+		{"no guards", models.OrderStatusConfirmed, models.OrderStatusShipped, 0},
+		{"a failing guard rejects", models.OrderStatusPending, models.OrderStatusCancelled, 403},
+		{"unknown target state", models.OrderStatusPending, "LOST", 400},
+		{"terminal state has no way out", models.OrderStatusReturned, models.OrderStatusPending, 400},
+		{"known states without a transition", models.OrderStatusShipped, models.OrderStatusPending, 400},
+		{"no skipping states", models.OrderStatusPending, models.OrderStatusShipped, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := machine.Validate(context.Background(), &Transition{Order: &models.Order{Status: tt.from}, From: tt.from, To: tt.to})
+			if got := errorCode(err); got != tt.wantCode || (tt.wantCode == 0) != (err == nil) {
+				t.Fatalf("Validate(%s -> %s) = %v, want code %d", tt.from, tt.to, err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestFire(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) Hook {
+		return func(ctx context.Context, t *Transition) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	failure := stdErrors.New("hook failed")
+
+	tests := []struct {
+		name       string
+		onEnter    []Hook
+		guard      Guard
+		wantErr    error
+		wantCalls  []string
+		wantStatus models.OrderStatus
+	}{
+		{"runs hooks in order", []Hook{record("a", nil), record("b", nil)}, nil, nil, []string{"guard", "a", "b"}, models.OrderStatusConfirmed},
+		{"stops at the first failing hook", []Hook{record("a", failure), record("b", nil)}, nil, failure, []string{"guard", "a"}, models.OrderStatusConfirmed},
+		{"no hooks when a guard fails", []Hook{record("a", nil)}, func(ctx context.Context, t *Transition) error {
+			calls = append(calls, "guard")
+			return failure
+		}, failure, []string{"guard"}, models.OrderStatusPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			guard := tt.guard
+			if guard == nil {
+				guard = func(ctx context.Context, t *Transition) error {
+					calls = append(calls, "guard")
+					return nil
+				}
+			}
+			machine := New().
+				AddTransition(models.OrderStatusPending, models.OrderStatusConfirmed, guard).
+				OnEnter(models.OrderStatusConfirmed, tt.onEnter...).
+				AfterEnter(models.OrderStatusConfirmed, record("after", nil))
+
+			order := &models.Order{Status: models.OrderStatusPending}
+			err := machine.Fire(context.Background(), &Transition{Order: order, From: order.Status, To: models.OrderStatusConfirmed})
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Fire() = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if order.Status != tt.wantStatus {
+				t.Errorf("order status = %s, want %s", order.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCommittedRunsEveryHook(t *testing.T) {
+	first, second := stdErrors.New("first"), stdErrors.New("second")
+	ran := 0
+	hook := func(err error) Hook {
+		return func(ctx context.Context, t *Transition) error {
+			ran++
+			return err
+		}
+	}
+	machine := New().
+		AddTransition(models.OrderStatusPending, models.OrderStatusCancelled).
+		AfterEnter(models.OrderStatusCancelled, hook(first), hook(nil), hook(second))
+
+	errs := machine.Committed(context.Background(), &Transition{Order: &models.Order{}, To: models.OrderStatusCancelled})
+	if ran != 3 {
+		t.Errorf("Committed() ran %d hooks, want 3", ran)
+	}
+	if len(errs) != 2 || errs[0] != first || errs[1] != second {
+		t.Errorf("Committed() = %v, want [first second]", errs)
+	}
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	machine := New().
+		AddTransition(models.OrderStatusPending, models.OrderStatusConfirmed).
+		AddTransition(models.OrderStatusPending, models.OrderStatusCancelled).
+		AddTransition(models.OrderStatusConfirmed, models.OrderStatusCancelled)
+
+	got := machine.AllowedTransitions(models.OrderStatusPending)
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	want := []models.OrderStatus{models.OrderStatusCancelled, models.OrderStatusConfirmed}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AllowedTransitions(PENDING) = %v, want %v", got, want)
+	}
+	if got := machine.AllowedTransitions(models.OrderStatusCancelled); len(got) != 0 {
+		t.Errorf("AllowedTransitions(CANCELLED) = %v, want none", got)
+	}
+	if !machine.HasState(models.OrderStatusCancelled) || machine.HasState(models.OrderStatusShipped) {
+		t.Error("HasState() does not match the registered transitions")
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	guard := RequireRole(models.RoleSystem, models.RoleAdmin)
+	tests := []struct {
+		role     string
+		wantCode int
+	}{
+		{models.RoleSystem, 0},
+		{models.RoleAdmin, 0},
+		{models.RoleSeller, 403},
+		{models.RoleBuyer, 403},
+		{"", 403},
+	}
+	for _, tt := range tests {
+		err := guard(context.Background(), &Transition{Actor: models.Actor{Role: tt.role}, To: models.OrderStatusDelivered})
+		if errorCode(err) != tt.wantCode || (tt.wantCode == 0) != (err == nil) {
+			t.Errorf("RequireRole() for %q = %v, want code %d", tt.role, err, tt.wantCode)
+		}
+	}
+}
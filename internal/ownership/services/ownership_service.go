@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	productModels "github.com/devbenho/luka-platform/internal/product/models"
+	productRepo "github.com/devbenho/luka-platform/internal/product/repositories"
+	storeModels "github.com/devbenho/luka-platform/internal/store/models"
+	storeRepo "github.com/devbenho/luka-platform/internal/store/repositories"
+	warehouseModels "github.com/devbenho/luka-platform/internal/warehouse/models"
+	warehouseRepo "github.com/devbenho/luka-platform/internal/warehouse/repositories"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/principal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IOwnershipService authorizes the principal of a request to manage a store
+// and what belongs to it. A store is governed by its owner, and its products,
+// inventories and warehouses by the owner of their store. Admins may manage
+// every store.
+type IOwnershipService interface {
+	AuthorizeStore(ctx context.Context, storeID primitive.ObjectID) (*storeModels.Store, error)
+	AuthorizeProduct(ctx context.Context, productID primitive.ObjectID) (*productModels.Product, error)
+	AuthorizeWarehouse(ctx context.Context, warehouseID primitive.ObjectID) (*warehouseModels.Warehouse, error)
+	AuthorizeSeller(ctx context.Context, actor orderModels.Actor, storeID primitive.ObjectID) error
+}
+
+type OwnershipService struct {
+	storeRepo     storeRepo.IStoreRepository
+	productRepo   productRepo.IProductRepository
+	warehouseRepo warehouseRepo.IWarehouseRepository
+}
+
+func NewOwnershipService(
+	storeRepo storeRepo.IStoreRepository,
+	productRepo productRepo.IProductRepository,
+	warehouseRepo warehouseRepo.IWarehouseRepository,
+) IOwnershipService {
+	return &OwnershipService{
+		storeRepo:     storeRepo,
+		productRepo:   productRepo,
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+// AuthorizeStore returns the store when the principal owns it.
+func (s *OwnershipService) AuthorizeStore(ctx context.Context, storeID primitive.ObjectID) (*storeModels.Store, error) {
+	p, ok := principal.FromContext(ctx)
+	if !ok {
+		return nil, errors.NewUnauthorizedError("user not authenticated")
+	}
+
+	store, err := s.storeRepo.GetStoreByID(ctx, storeID.Hex())
+	if err != nil || store.DeletedAt != nil {
+		return nil, errors.NewNotFoundError("store", storeID.Hex())
+	}
+	if !p.IsAdmin() && p.UserID != store.OwnerId {
+		return nil, errors.NewError(errors.UnauthorizedType, 403, "only the store owner may manage this store")
+	}
+	return store, nil
+}
+
+// AuthorizeProduct returns the product when the principal owns its store.
+func (s *OwnershipService) AuthorizeProduct(ctx context.Context, productID primitive.ObjectID) (*productModels.Product, error) {
+	product, err := s.productRepo.GetProductByID(ctx, productID.Hex())
+	if err != nil || product.DeletedAt != nil {
+		return nil, errors.NewNotFoundError("product", productID.Hex())
+	}
+	if _, err := s.AuthorizeStore(ctx, product.StoreID); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// AuthorizeWarehouse returns the warehouse when the principal owns its store.
+func (s *OwnershipService) AuthorizeWarehouse(ctx context.Context, warehouseID primitive.ObjectID) (*warehouseModels.Warehouse, error) {
+	warehouse, err := s.warehouseRepo.GetWarehouseByID(ctx, warehouseID)
+	if err != nil {
+		return nil, errors.NewNotFoundError("warehouse", warehouseID.Hex())
+	}
+	if _, err := s.AuthorizeStore(ctx, warehouse.StoreID); err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// AuthorizeSeller lets an order actor act for the store that sold an order:
+// privileged actors always, users only when they own the store. Orders
+// without a store have no seller.
+func (s *OwnershipService) AuthorizeSeller(ctx context.Context, actor orderModels.Actor, storeID primitive.ObjectID) error {
+	if actor.IsPrivileged() {
+		return nil
+	}
+	if !storeID.IsZero() {
+		store, err := s.storeRepo.GetStoreByID(ctx, storeID.Hex())
+		if err != nil && !stdErrors.Is(err, mongo.ErrNoDocuments) {
+			return errors.Wrap(err, "fetching store")
+		}
+		if err == nil && store.DeletedAt == nil && store.OwnerId == actor.ID {
+			return nil
+		}
+	}
+	return errors.NewError(errors.UnauthorizedType, 403, "only the owner of the store may do this")
+}
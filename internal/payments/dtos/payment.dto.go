@@ -0,0 +1,16 @@
+package dtos
+
+import "github.com/devbenho/luka-platform/pkg/money"
+
+type AuthorizePaymentRequest struct {
+	// PaymentMethod is the provider token of the buyer's card or wallet.
+	PaymentMethod string `json:"payment_method" validate:"required,max=200"`
+	// Capture takes the money right away instead of only holding it.
+	Capture bool `json:"capture"`
+}
+
+type RefundPaymentRequest struct {
+	// Amount defaults to everything captured and not refunded yet.
+	Amount *money.Money `json:"amount"`
+	Reason string       `json:"reason" validate:"max=500"`
+}
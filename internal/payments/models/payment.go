@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusAuthorized        PaymentStatus = "AUTHORIZED"
+	PaymentStatusCaptured          PaymentStatus = "CAPTURED"
+	PaymentStatusPartiallyRefunded PaymentStatus = "PARTIALLY_REFUNDED"
+	// PaymentStatusRefunding marks a payment whose refund has been sent to
+	// the provider, so that a concurrent refund cannot be sent as well. The
+	// refund is described by the payment's RefundClaim.
+	PaymentStatusRefunding PaymentStatus = "REFUNDING"
+	PaymentStatusRefunded  PaymentStatus = "REFUNDED"
+	PaymentStatusVoided    PaymentStatus = "VOIDED"
+	PaymentStatusFailed    PaymentStatus = "FAILED"
+)
+
+type TransactionType string
+
+const (
+	TransactionAuthorize TransactionType = "AUTHORIZE"
+	TransactionCapture   TransactionType = "CAPTURE"
+	TransactionRefund    TransactionType = "REFUND"
+	TransactionVoid      TransactionType = "VOID"
+)
+
+// Transaction is one call made to the payment provider and its outcome.
+type Transaction struct {
+	Type      TransactionType `bson:"type" json:"type"`
+	Amount    money.Money     `bson:"amount" json:"amount"`
+	Reference string          `bson:"reference,omitempty" json:"reference,omitempty"`
+	Success   bool            `bson:"success" json:"success"`
+	Error     string          `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt time.Time       `bson:"created_at" json:"created_at"`
+}
+
+// RefundClaim is a refund that is being sent to the provider. It is saved
+// before the provider is called, so that a refund whose outcome was never
+// saved can be looked up at the provider by its reference.
+type RefundClaim struct {
+	Reference string      `bson:"reference" json:"reference"`
+	Amount    money.Money `bson:"amount" json:"amount"`
+	// From is the status the payment goes back to if no refund was made.
+	From      PaymentStatus `bson:"from" json:"from"`
+	ClaimedAt time.Time     `bson:"claimed_at" json:"claimed_at"`
+}
+
+// Payment is the money collected for one order. Funds are first authorized,
+// then captured; captured funds can be refunded in parts, authorized funds
+// that were never captured can be voided.
+type Payment struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrderID        primitive.ObjectID `bson:"order_id" json:"order_id"`
+	CustomerID     primitive.ObjectID `bson:"customer_id" json:"customer_id"`
+	Provider       string             `bson:"provider" json:"provider"`
+	ProviderRef    string             `bson:"provider_ref,omitempty" json:"provider_ref,omitempty"`
+	Status         PaymentStatus      `bson:"status" json:"status"`
+	Amount         money.Money        `bson:"amount" json:"amount"`
+	CapturedAmount money.Money        `bson:"captured_amount" json:"captured_amount"`
+	RefundedAmount money.Money        `bson:"refunded_amount" json:"refunded_amount"`
+	FailureReason  string             `bson:"failure_reason,omitempty" json:"failure_reason,omitempty"`
+	// ReversalPending is set when the order is cancelled and stays set: the
+	// payment is voided or refunded as long as it still holds money.
+	ReversalPending bool `bson:"reversal_pending,omitempty" json:"reversal_pending,omitempty"`
+	// RefundClaim is set while the payment is REFUNDING.
+	RefundClaim *RefundClaim `bson:"refund_claim,omitempty" json:"refund_claim,omitempty"`
+	// Active mirrors IsActive so that a unique index can allow only one
+	// active payment per order. The repository keeps it up to date.
+	Active       bool          `bson:"active" json:"-"`
+	Transactions []Transaction `bson:"transactions" json:"transactions"`
+	CreatedAt    time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// IsActive reports whether the payment holds or has taken the buyer's money.
+func (p *Payment) IsActive() bool {
+	switch p.Status {
+	case PaymentStatusAuthorized, PaymentStatusCaptured, PaymentStatusPartiallyRefunded, PaymentStatusRefunding:
+		return true
+	}
+	return false
+}
+
+// Refundable is the captured amount not refunded yet.
+func (p *Payment) Refundable() (money.Money, error) {
+	return p.CapturedAmount.Sub(p.RefundedAmount)
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookEvent records a provider event that has been processed. Its ID joins
+// the provider name and the provider's event ID, so a redelivered event
+// collides with the first delivery and is not applied twice.
+type WebhookEvent struct {
+	ID         string             `bson:"_id" json:"id"`
+	Provider   string             `bson:"provider" json:"provider"`
+	EventID    string             `bson:"event_id" json:"event_id"`
+	Type       string             `bson:"type" json:"type"`
+	PaymentID  primitive.ObjectID `bson:"payment_id,omitempty" json:"payment_id,omitempty"`
+	OrderID    primitive.ObjectID `bson:"order_id,omitempty" json:"order_id,omitempty"`
+	Payload    string             `bson:"payload" json:"-"`
+	ReceivedAt time.Time          `bson:"received_at" json:"received_at"`
+}
+
+func WebhookEventID(provider, eventID string) string {
+	return provider + ":" + eventID
+}
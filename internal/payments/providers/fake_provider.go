@@ -0,0 +1,151 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const FakeProviderName = "fake"
+
+// Payment method tokens the fake provider treats specially. Any other token
+// is accepted.
+const (
+	FakeMethodDeclined        = "fake_declined"
+	FakeMethodCaptureDeclined = "fake_capture_declined"
+)
+
+type fakeAuthorization struct {
+	method     string
+	authorized money.Money
+	captured   money.Money
+	refunded   money.Money
+	voided     bool
+	// refunds are the refunds made, by reference.
+	refunds map[string]*Result
+}
+
+// FakeProvider is an in-process provider for tests and local development. It
+// keeps authorizations in memory and enforces the same amount rules a real
+// gateway would.
+type FakeProvider struct {
+	mu             sync.Mutex
+	authorizations map[string]*fakeAuthorization
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		authorizations: map[string]*fakeAuthorization{},
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return FakeProviderName
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	if req.PaymentMethod == FakeMethodDeclined {
+		return nil, fmt.Errorf("card was declined: %w", ErrDeclined)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ref := "fake_auth_" + primitive.NewObjectID().Hex()
+	p.authorizations[ref] = &fakeAuthorization{
+		method:     req.PaymentMethod,
+		authorized: req.Amount,
+		captured:   req.Amount.Zero(),
+		refunded:   req.Amount.Zero(),
+		refunds:    map[string]*Result{},
+	}
+	return p.result(ref), nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, providerRef string, amount money.Money) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	auth, err := p.find(providerRef)
+	if err != nil {
+		return nil, err
+	}
+	if auth.method == FakeMethodCaptureDeclined {
+		return nil, fmt.Errorf("capture was declined: %w", ErrDeclined)
+	}
+	if auth.voided || !auth.captured.IsZero() {
+		return nil, fmt.Errorf("authorization %s cannot be captured", providerRef)
+	}
+	if cmp, err := amount.Cmp(auth.authorized); err != nil || cmp > 0 {
+		return nil, fmt.Errorf("capture exceeds the authorized amount of %s", auth.authorized)
+	}
+	auth.captured = amount
+	return p.result(providerRef), nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, providerRef, reference string, amount money.Money) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	auth, err := p.find(providerRef)
+	if err != nil {
+		return nil, err
+	}
+	if result, ok := auth.refunds[reference]; ok {
+		return result, nil
+	}
+	refunded, err := auth.refunded.Add(amount)
+	if err != nil {
+		return nil, err
+	}
+	if cmp, err := refunded.Cmp(auth.captured); err != nil || cmp > 0 {
+		return nil, fmt.Errorf("refund exceeds the captured amount of %s", auth.captured)
+	}
+	auth.refunded = refunded
+	result := p.result(providerRef)
+	auth.refunds[reference] = result
+	return result, nil
+}
+
+func (p *FakeProvider) FindRefund(ctx context.Context, providerRef, reference string) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	auth, err := p.find(providerRef)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := auth.refunds[reference]
+	if !ok {
+		return nil, fmt.Errorf("refund %s: %w", reference, ErrNotFound)
+	}
+	return result, nil
+}
+
+func (p *FakeProvider) Void(ctx context.Context, providerRef string) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	auth, err := p.find(providerRef)
+	if err != nil {
+		return nil, err
+	}
+	if !auth.captured.IsZero() {
+		return nil, fmt.Errorf("authorization %s has been captured", providerRef)
+	}
+	auth.voided = true
+	return p.result(providerRef), nil
+}
+
+func (p *FakeProvider) find(providerRef string) (*fakeAuthorization, error) {
+	auth, ok := p.authorizations[providerRef]
+	if !ok {
+		return nil, fmt.Errorf("unknown authorization %s", providerRef)
+	}
+	return auth, nil
+}
+
+func (p *FakeProvider) result(providerRef string) *Result {
+	return &Result{
+		ProviderRef:   providerRef,
+		TransactionID: "fake_txn_" + primitive.NewObjectID().Hex(),
+	}
+}
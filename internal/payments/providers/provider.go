@@ -0,0 +1,59 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devbenho/luka-platform/pkg/money"
+)
+
+// ErrDeclined is returned when the provider refuses a payment operation for
+// a business reason, such as insufficient funds, rather than a technical one.
+var ErrDeclined = errors.New("payment declined")
+
+// ErrNotFound is returned when the provider has no record of what was looked
+// up, such as a refund it never received.
+var ErrNotFound = errors.New("not found at the payment provider")
+
+type AuthorizeRequest struct {
+	// Reference identifies the payment on our side, so that retries of the
+	// same authorization can be recognised by the provider.
+	Reference string
+	Amount    money.Money
+	// PaymentMethod is the provider's token for the buyer's card or wallet.
+	PaymentMethod string
+}
+
+// Result is what the provider answered to an operation. ProviderRef is the
+// provider's identifier of the authorization it applies to.
+type Result struct {
+	ProviderRef   string
+	TransactionID string
+}
+
+// IPaymentProvider is implemented by every payment gateway. Amounts passed to
+// Capture and Refund may be lower than the authorized or captured amount.
+//
+// Refund takes a reference identifying the refund on our side. The provider
+// makes at most one refund per reference, answering a retry with the first
+// refund, and FindRefund looks a refund up by it.
+type IPaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, providerRef string, amount money.Money) (*Result, error)
+	Refund(ctx context.Context, providerRef, reference string, amount money.Money) (*Result, error)
+	// FindRefund fails with ErrNotFound when no refund was made under
+	// reference.
+	FindRefund(ctx context.Context, providerRef, reference string) (*Result, error)
+	Void(ctx context.Context, providerRef string) (*Result, error)
+}
+
+// New returns the provider configured under name.
+func New(name string) (IPaymentProvider, error) {
+	switch name {
+	case "", FakeProviderName:
+		return NewFakeProvider(), nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", name)
+}
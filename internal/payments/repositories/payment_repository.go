@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/payments/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IPaymentRepository interface {
+	// CreatePayment fails with a duplicate key error, which
+	// mongo.IsDuplicateKeyError detects, when the order already has an
+	// active payment.
+	CreatePayment(ctx context.Context, payment *models.Payment) (*models.Payment, error)
+	GetPaymentByID(ctx context.Context, id string) (*models.Payment, error)
+	GetPaymentByProviderRef(ctx context.Context, provider, providerRef string) (*models.Payment, error)
+	ListPaymentsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.Payment, error)
+	CountCapturedPayments(ctx context.Context, orderID primitive.ObjectID) (int64, error)
+	UpdatePayment(ctx context.Context, payment *models.Payment, expected models.PaymentStatus) error
+	MarkForReversal(ctx context.Context, orderID primitive.ObjectID) error
+	ListPendingReversals(ctx context.Context) ([]models.Payment, error)
+	ListRefundClaimsBefore(ctx context.Context, before time.Time) ([]models.Payment, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type PaymentRepository struct {
+	db database.IDatabase
+}
+
+func NewPaymentRepository(db database.IDatabase) IPaymentRepository {
+	return &PaymentRepository{
+		db: db,
+	}
+}
+
+func (r *PaymentRepository) CreatePayment(ctx context.Context, payment *models.Payment) (*models.Payment, error) {
+	payment.ID = primitive.NewObjectID()
+	payment.CreatedAt = time.Now()
+	payment.UpdatedAt = time.Now()
+	payment.Active = payment.IsActive()
+	if err := r.db.Create(ctx, "payments", payment); err != nil {
+		return nil, fmt.Errorf("failed to create payment in db: %w", err)
+	}
+	return payment, nil
+}
+
+func (r *PaymentRepository) GetPaymentByID(ctx context.Context, id string) (*models.Payment, error) {
+	var payment models.Payment
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment ID: %w", err)
+	}
+	filter := bson.M{"_id": objID}
+	if err := r.db.FindOne(ctx, "payments", filter, &payment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("payment not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get payment from db: %w", err)
+	}
+	return &payment, nil
+}
+
+func (r *PaymentRepository) GetPaymentByProviderRef(ctx context.Context, provider, providerRef string) (*models.Payment, error) {
+	var payment models.Payment
+	filter := bson.M{"provider": provider, "provider_ref": providerRef}
+	if err := r.db.FindOne(ctx, "payments", filter, &payment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("payment not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get payment from db: %w", err)
+	}
+	return &payment, nil
+}
+
+func (r *PaymentRepository) ListPaymentsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.Payment, error) {
+	payments := []models.Payment{}
+	filter := bson.M{"order_id": orderID}
+	if err := r.db.Find(ctx, "payments", filter, &payments); err != nil {
+		return nil, fmt.Errorf("failed to list payments from db: %w", err)
+	}
+	return payments, nil
+}
+
+// CountCapturedPayments counts the payments of an order whose money has been
+// taken, including those refunded in part since or being refunded.
+func (r *PaymentRepository) CountCapturedPayments(ctx context.Context, orderID primitive.ObjectID) (int64, error) {
+	filter := bson.M{
+		"order_id": orderID,
+		"status": bson.M{"$in": []models.PaymentStatus{
+			models.PaymentStatusCaptured,
+			models.PaymentStatusPartiallyRefunded,
+			models.PaymentStatusRefunding,
+		}},
+	}
+	count, err := r.db.Count(ctx, "payments", filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count payments: %w", err)
+	}
+	return count, nil
+}
+
+// UpdatePayment saves the payment only if it is still in the expected status,
+// returning mongo.ErrNoDocuments when someone else changed it first.
+func (r *PaymentRepository) UpdatePayment(ctx context.Context, payment *models.Payment, expected models.PaymentStatus) error {
+	payment.UpdatedAt = time.Now()
+	payment.Active = payment.IsActive()
+	filter := bson.M{"_id": payment.ID, "status": expected}
+	update := bson.M{"$set": payment}
+	if payment.RefundClaim == nil {
+		update["$unset"] = bson.M{"refund_claim": ""}
+	}
+	var updated models.Payment
+	return r.db.FindOneAndUpdate(ctx, "payments", filter, update, &updated)
+}
+
+// activeStatuses are the statuses of payments that hold or have taken money.
+var activeStatuses = []models.PaymentStatus{
+	models.PaymentStatusAuthorized,
+	models.PaymentStatusCaptured,
+	models.PaymentStatusPartiallyRefunded,
+	models.PaymentStatusRefunding,
+}
+
+// MarkForReversal flags every active payment of an order to be voided or
+// refunded. Only the flag is written, so that it can run in the transaction
+// that cancels the order without racing the payment's other updates.
+func (r *PaymentRepository) MarkForReversal(ctx context.Context, orderID primitive.ObjectID) error {
+	filter := bson.M{"order_id": orderID, "status": bson.M{"$in": activeStatuses}}
+	update := bson.M{"$set": bson.M{"reversal_pending": true, "updated_at": time.Now()}}
+	if err := r.db.UpdateMany(ctx, "payments", filter, update); err != nil {
+		return fmt.Errorf("failed to mark payments for reversal: %w", err)
+	}
+	return nil
+}
+
+// ListPendingReversals returns the payments flagged for reversal that still
+// hold or have taken money. Payments being refunded are left out until their
+// refund is settled.
+func (r *PaymentRepository) ListPendingReversals(ctx context.Context) ([]models.Payment, error) {
+	payments := []models.Payment{}
+	filter := bson.M{"reversal_pending": true, "status": bson.M{"$in": []models.PaymentStatus{
+		models.PaymentStatusAuthorized,
+		models.PaymentStatusCaptured,
+		models.PaymentStatusPartiallyRefunded,
+	}}}
+	if err := r.db.Find(ctx, "payments", filter, &payments); err != nil {
+		return nil, fmt.Errorf("failed to list payments to reverse: %w", err)
+	}
+	return payments, nil
+}
+
+// ListRefundClaimsBefore returns the payments whose refund was claimed
+// before the given time and whose outcome has not been saved yet.
+func (r *PaymentRepository) ListRefundClaimsBefore(ctx context.Context, before time.Time) ([]models.Payment, error) {
+	payments := []models.Payment{}
+	filter := bson.M{
+		"status":                  models.PaymentStatusRefunding,
+		"refund_claim.claimed_at": bson.M{"$lt": before},
+	}
+	if err := r.db.Find(ctx, "payments", filter, &payments); err != nil {
+		return nil, fmt.Errorf("failed to list refund claims: %w", err)
+	}
+	return payments, nil
+}
+
+// EnsureIndexes creates the index that allows a single active payment per
+// order, so that two concurrent authorizations cannot both be recorded.
+func (r *PaymentRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, database.DatabaseTimeout)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "order_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"active": true}),
+		},
+	}
+	if _, err := r.db.GetDB().Collection("payments").Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("failed to create payment indexes: %w", err)
+	}
+	return nil
+}
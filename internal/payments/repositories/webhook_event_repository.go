@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/devbenho/luka-platform/internal/payments/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IWebhookEventRepository interface {
+	// CreateEvent stores a processed event. Storing an event twice fails
+	// with a duplicate key error, which mongo.IsDuplicateKeyError detects.
+	CreateEvent(ctx context.Context, event *models.WebhookEvent) error
+	EventExists(ctx context.Context, id string) (bool, error)
+}
+
+type WebhookEventRepository struct {
+	db database.IDatabase
+}
+
+func NewWebhookEventRepository(db database.IDatabase) IWebhookEventRepository {
+	return &WebhookEventRepository{
+		db: db,
+	}
+}
+
+func (r *WebhookEventRepository) CreateEvent(ctx context.Context, event *models.WebhookEvent) error {
+	if err := r.db.Create(ctx, "webhook_events", event); err != nil {
+		return fmt.Errorf("failed to create webhook event in db: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookEventRepository) EventExists(ctx context.Context, id string) (bool, error) {
+	var event models.WebhookEvent
+	err := r.db.FindOne(ctx, "webhook_events", bson.M{"_id": id}, &event)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get webhook event from db: %w", err)
+	}
+	return true, nil
+}
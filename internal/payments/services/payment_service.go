@@ -0,0 +1,426 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"log"
+	"time"
+
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	orderDtos "github.com/devbenho/luka-platform/internal/orders/order_dtos"
+	orderSvc "github.com/devbenho/luka-platform/internal/orders/services"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	"github.com/devbenho/luka-platform/internal/payments/dtos"
+	"github.com/devbenho/luka-platform/internal/payments/models"
+	"github.com/devbenho/luka-platform/internal/payments/providers"
+	"github.com/devbenho/luka-platform/internal/payments/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IPaymentService interface {
+	AuthorizePayment(ctx context.Context, orderID string, actor orderModels.Actor, dto dtos.AuthorizePaymentRequest) (*models.Payment, error)
+	CapturePayment(ctx context.Context, id string, actor orderModels.Actor) (*models.Payment, error)
+	RefundPayment(ctx context.Context, id string, actor orderModels.Actor, dto dtos.RefundPaymentRequest) (*models.Payment, error)
+	VoidPayment(ctx context.Context, id string, actor orderModels.Actor) (*models.Payment, error)
+	GetPaymentByID(ctx context.Context, id string, actor orderModels.Actor) (*models.Payment, error)
+	ListPaymentsByOrder(ctx context.Context, orderID string, actor orderModels.Actor) ([]models.Payment, error)
+}
+
+type PaymentService struct {
+	db           database.IDatabase
+	repo         repositories.IPaymentRepository
+	provider     providers.IPaymentProvider
+	orderService orderSvc.IOrderService
+	ownership    ownershipSvc.IOwnershipService
+	validator    *validation.Validator
+}
+
+func NewPaymentService(
+	db database.IDatabase,
+	repo repositories.IPaymentRepository,
+	provider providers.IPaymentProvider,
+	orderService orderSvc.IOrderService,
+	ownership ownershipSvc.IOwnershipService,
+	validator *validation.Validator,
+) *PaymentService {
+	return &PaymentService{
+		db:           db,
+		repo:         repo,
+		provider:     provider,
+		orderService: orderService,
+		ownership:    ownership,
+		validator:    validator,
+	}
+}
+
+// AuthorizePayment holds the order total on the buyer's payment method. A
+// declined authorization is still recorded, as a failed payment, so that
+// the attempt shows up in the order's payment history.
+func (s *PaymentService) AuthorizePayment(ctx context.Context, orderID string, actor orderModels.Actor, dto dtos.AuthorizePaymentRequest) (*models.Payment, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.CustomerID != actor.ID {
+		return nil, errors.NewError(errors.UnauthorizedType, 403, "only the customer who placed the order may pay for it")
+	}
+	if order.Status != orderModels.OrderStatusPending && order.Status != orderModels.OrderStatusConfirmed {
+		return nil, errors.NewConflictError("order is not awaiting payment")
+	}
+	// This check spares the provider call in the common case; the unique
+	// index on active payments settles concurrent requests.
+	payments, err := s.repo.ListPaymentsByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing payments")
+	}
+	for _, payment := range payments {
+		if payment.IsActive() {
+			return nil, errors.NewConflictError("order already has an active payment")
+		}
+	}
+
+	payment := &models.Payment{
+		OrderID:        order.ID,
+		CustomerID:     order.CustomerID,
+		Provider:       s.provider.Name(),
+		Amount:         order.TotalAmount,
+		CapturedAmount: order.TotalAmount.Zero(),
+		RefundedAmount: order.TotalAmount.Zero(),
+	}
+	result, providerErr := s.provider.Authorize(ctx, providers.AuthorizeRequest{
+		Reference:     order.ID.Hex(),
+		Amount:        order.TotalAmount,
+		PaymentMethod: dto.PaymentMethod,
+	})
+	record(payment, models.TransactionAuthorize, order.TotalAmount, result, providerErr)
+	if providerErr != nil {
+		payment.Status = models.PaymentStatusFailed
+		payment.FailureReason = providerErr.Error()
+	} else {
+		payment.Status = models.PaymentStatusAuthorized
+		payment.ProviderRef = result.ProviderRef
+	}
+
+	created, err := s.repo.CreatePayment(ctx, payment)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request recorded an active payment first: release
+		// the money this one held so that the order is only paid once.
+		if _, voidErr := s.provider.Void(ctx, payment.ProviderRef); voidErr != nil {
+			log.Printf("order %s: voiding duplicate authorization %s: %v", order.ID.Hex(), payment.ProviderRef, voidErr)
+		}
+		return nil, errors.NewConflictError("order already has an active payment")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "creating payment")
+	}
+	if providerErr != nil {
+		return nil, paymentError(providerErr)
+	}
+	if dto.Capture {
+		return s.capture(ctx, created)
+	}
+	return created, nil
+}
+
+// CapturePayment takes the authorized money and moves the order to
+// PROCESSING.
+func (s *PaymentService) CapturePayment(ctx context.Context, id string, actor orderModels.Actor) (*models.Payment, error) {
+	payment, err := s.getPayment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizePayerOrSeller(ctx, payment, actor); err != nil {
+		return nil, err
+	}
+	return s.capture(ctx, payment)
+}
+
+func (s *PaymentService) capture(ctx context.Context, payment *models.Payment) (*models.Payment, error) {
+	if payment.Status != models.PaymentStatusAuthorized {
+		return nil, errors.NewConflictError("only authorized payments can be captured")
+	}
+	if payment.ReversalPending {
+		return nil, errors.NewConflictError("the order was cancelled and its payment is being given back")
+	}
+
+	result, providerErr := s.provider.Capture(ctx, payment.ProviderRef, payment.Amount)
+	record(payment, models.TransactionCapture, payment.Amount, result, providerErr)
+	if providerErr != nil {
+		if err := save(ctx, s.repo, payment, models.PaymentStatusAuthorized); err != nil {
+			return nil, err
+		}
+		return nil, paymentError(providerErr)
+	}
+
+	payment.Status = models.PaymentStatusCaptured
+	payment.CapturedAmount = payment.Amount
+	err := s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		if err := save(sessCtx, s.repo, payment, models.PaymentStatusAuthorized); err != nil {
+			return err
+		}
+		return s.orderService.UpdateOrderStatus(sessCtx, payment.OrderID.Hex(), orderModels.SystemActor, orderDtos.UpdateOrderStatusRequest{
+			Status: orderModels.OrderStatusProcessing,
+			Note:   "payment " + payment.ID.Hex() + " captured",
+		})
+	})
+	if err != nil {
+		// The provider already took the money but the order cannot move on,
+		// typically because it was cancelled meanwhile: give it back.
+		s.reverseCapture(ctx, payment)
+		return nil, errors.Wrap(err, "capturing payment")
+	}
+	return payment, nil
+}
+
+// RefundPayment gives back some or all of the captured money. Only the owner
+// of the order's store may refund it.
+func (s *PaymentService) RefundPayment(ctx context.Context, id string, actor orderModels.Actor, dto dtos.RefundPaymentRequest) (*models.Payment, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+
+	payment, err := s.getPayment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeSeller(ctx, payment, actor); err != nil {
+		return nil, err
+	}
+	if payment.Status != models.PaymentStatusCaptured && payment.Status != models.PaymentStatusPartiallyRefunded {
+		return nil, errors.NewConflictError("only captured payments can be refunded")
+	}
+	refundable, err := payment.Refundable()
+	if err != nil {
+		return nil, errors.Wrap(err, "totalling refunds")
+	}
+	amount := refundable
+	if dto.Amount != nil {
+		amount = *dto.Amount
+	}
+	if cmp, err := amount.Cmp(refundable); err != nil || cmp > 0 || amount.IsZero() || amount.IsNegative() {
+		return nil, errors.NewError(
+			errors.ValidationErrorType,
+			400,
+			"refund must be a positive amount up to "+refundable.String(),
+			errors.WithField("amount"),
+		)
+	}
+
+	if err := refund(ctx, s.repo, s.provider, payment, amount); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// VoidPayment releases authorized money that was never captured.
+func (s *PaymentService) VoidPayment(ctx context.Context, id string, actor orderModels.Actor) (*models.Payment, error) {
+	payment, err := s.getPayment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizePayerOrSeller(ctx, payment, actor); err != nil {
+		return nil, err
+	}
+	if payment.Status != models.PaymentStatusAuthorized {
+		return nil, errors.NewConflictError("only authorized payments can be voided")
+	}
+	if err := void(ctx, s.repo, s.provider, payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetPaymentByID returns a payment to the customer of its order, the owner
+// of the order's store or a privileged actor.
+func (s *PaymentService) GetPaymentByID(ctx context.Context, id string, actor orderModels.Actor) (*models.Payment, error) {
+	payment, err := s.getPayment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.orderService.GetOrder(ctx, payment.OrderID.Hex(), actor); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (s *PaymentService) getPayment(ctx context.Context, id string) (*models.Payment, error) {
+	if id == "" {
+		return nil, errors.NewBadRequestError("payment ID is required")
+	}
+	payment, err := s.repo.GetPaymentByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching payment")
+	}
+	return payment, nil
+}
+
+// ListPaymentsByOrder returns the payments of an order to its customer, the
+// owner of its store or a privileged actor.
+func (s *PaymentService) ListPaymentsByOrder(ctx context.Context, orderID string, actor orderModels.Actor) ([]models.Payment, error) {
+	order, err := s.orderService.GetOrder(ctx, orderID, actor)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.repo.ListPaymentsByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing payments")
+	}
+	return payments, nil
+}
+
+// reverseCapture refunds a capture whose order could not be moved on. Errors
+// are only logged: the capture has failed for the caller either way, and the
+// payment record keeps the provider's answer.
+func (s *PaymentService) reverseCapture(ctx context.Context, payment *models.Payment) {
+	result, providerErr := s.provider.Refund(ctx, payment.ProviderRef, payment.ID.Hex(), payment.Amount)
+	record(payment, models.TransactionRefund, payment.Amount, result, providerErr)
+	if providerErr != nil {
+		log.Printf("payment %s: reversing capture: %v", payment.ID.Hex(), providerErr)
+	} else {
+		payment.Status = models.PaymentStatusRefunded
+		payment.RefundedAmount = payment.Amount
+	}
+	if err := save(ctx, s.repo, payment, models.PaymentStatusAuthorized); err != nil {
+		log.Printf("payment %s: saving reversed capture: %v", payment.ID.Hex(), err)
+	}
+}
+
+// refund gives amount of a captured payment back. The refund is claimed by
+// moving the payment to REFUNDING before the provider is called, so that two
+// concurrent refunds cannot both reach the provider; the claim is released
+// whatever the provider answers. A claim whose outcome could not be saved is
+// settled later by ReversalService.ReconcileRefunds.
+func refund(ctx context.Context, repo repositories.IPaymentRepository, provider providers.IPaymentProvider, payment *models.Payment, amount money.Money) error {
+	claimed := payment.Status
+	payment.Status = models.PaymentStatusRefunding
+	payment.RefundClaim = &models.RefundClaim{
+		Reference: primitive.NewObjectID().Hex(),
+		Amount:    amount,
+		From:      claimed,
+		ClaimedAt: time.Now(),
+	}
+	if err := save(ctx, repo, payment, claimed); err != nil {
+		return err
+	}
+
+	result, providerErr := provider.Refund(ctx, payment.ProviderRef, payment.RefundClaim.Reference, amount)
+	if err := settleRefund(ctx, repo, payment, result, providerErr); err != nil {
+		return err
+	}
+	if providerErr != nil {
+		return paymentError(providerErr)
+	}
+	return nil
+}
+
+// settleRefund records the provider's answer to a claimed refund and releases
+// the claim: the refunded amount grows if the refund was made, otherwise the
+// payment goes back to the status it was claimed from.
+func settleRefund(ctx context.Context, repo repositories.IPaymentRepository, payment *models.Payment, result *providers.Result, providerErr error) error {
+	claim := payment.RefundClaim
+	record(payment, models.TransactionRefund, claim.Amount, result, providerErr)
+	if providerErr != nil {
+		payment.Status = claim.From
+	} else {
+		refunded, err := payment.RefundedAmount.Add(claim.Amount)
+		if err != nil {
+			return errors.Wrap(err, "totalling refunds")
+		}
+		payment.RefundedAmount = refunded
+		payment.Status = models.PaymentStatusPartiallyRefunded
+		if cmp, err := refunded.Cmp(payment.CapturedAmount); err == nil && cmp >= 0 {
+			payment.Status = models.PaymentStatusRefunded
+		}
+	}
+	payment.RefundClaim = nil
+	return save(ctx, repo, payment, models.PaymentStatusRefunding)
+}
+
+// void releases an authorized payment.
+func void(ctx context.Context, repo repositories.IPaymentRepository, provider providers.IPaymentProvider, payment *models.Payment) error {
+	result, providerErr := provider.Void(ctx, payment.ProviderRef)
+	record(payment, models.TransactionVoid, payment.Amount, result, providerErr)
+	if providerErr == nil {
+		payment.Status = models.PaymentStatusVoided
+	}
+	if err := save(ctx, repo, payment, models.PaymentStatusAuthorized); err != nil {
+		return err
+	}
+	if providerErr != nil {
+		return paymentError(providerErr)
+	}
+	return nil
+}
+
+// save persists a payment, failing with a conflict when another request
+// changed its status first.
+func save(ctx context.Context, repo repositories.IPaymentRepository, payment *models.Payment, expected models.PaymentStatus) error {
+	err := repo.UpdatePayment(ctx, payment, expected)
+	if err == mongo.ErrNoDocuments {
+		return errors.NewConflictError("payment was modified concurrently")
+	}
+	if err != nil {
+		return errors.Wrap(err, "updating payment")
+	}
+	return nil
+}
+
+// record appends the outcome of a provider call to the payment.
+func record(payment *models.Payment, kind models.TransactionType, amount money.Money, result *providers.Result, err error) {
+	transaction := models.Transaction{
+		Type:      kind,
+		Amount:    amount,
+		Success:   err == nil,
+		CreatedAt: time.Now(),
+	}
+	if err != nil {
+		transaction.Error = err.Error()
+	} else if result != nil {
+		transaction.Reference = result.TransactionID
+	}
+	payment.Transactions = append(payment.Transactions, transaction)
+}
+
+func paymentError(err error) error {
+	if stdErrors.Is(err, providers.ErrDeclined) {
+		return errors.NewError(errors.PaymentFailed, 402, err.Error())
+	}
+	return errors.NewError(errors.PaymentFailed, 502, "payment provider error", errors.WithCause(err))
+}
+
+// authorizePayerOrSeller lets the customer who paid, the owner of the order's
+// store and privileged actors through.
+func (s *PaymentService) authorizePayerOrSeller(ctx context.Context, payment *models.Payment, actor orderModels.Actor) error {
+	if actor.ID == payment.CustomerID {
+		return nil
+	}
+	return s.authorizeSeller(ctx, payment, actor)
+}
+
+// authorizeSeller lets the owner of the order's store and privileged actors
+// through.
+func (s *PaymentService) authorizeSeller(ctx context.Context, payment *models.Payment, actor orderModels.Actor) error {
+	if actor.IsPrivileged() {
+		return nil
+	}
+	order, err := s.orderService.GetOrderByID(ctx, payment.OrderID.Hex())
+	if err != nil {
+		return err
+	}
+	return s.ownership.AuthorizeSeller(ctx, actor, order.StoreID)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/payments/models"
+	"github.com/devbenho/luka-platform/internal/payments/providers"
+	"github.com/devbenho/luka-platform/internal/payments/repositories"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReversalService gives the buyer's money back when an order is cancelled. It
+// only depends on the payments and their provider so that the order state
+// machine can use it; the payment service itself depends on orders.
+//
+// Reversing happens in two steps. The transaction that cancels the order
+// marks its payments for reversal, and the provider is only called once that
+// transaction has committed, so that a retried or rolled back cancellation
+// never gives the money back twice. Payments whose reversal failed stay
+// marked and are retried by the ReversalSweeper.
+type ReversalService struct {
+	repo     repositories.IPaymentRepository
+	provider providers.IPaymentProvider
+}
+
+func NewReversalService(repo repositories.IPaymentRepository, provider providers.IPaymentProvider) *ReversalService {
+	return &ReversalService{
+		repo:     repo,
+		provider: provider,
+	}
+}
+
+// MarkForReversal flags the active payments of an order to be given back. It
+// does not call the provider and is meant to run in the cancel transaction.
+func (s *ReversalService) MarkForReversal(ctx context.Context, orderID primitive.ObjectID) error {
+	if err := s.repo.MarkForReversal(ctx, orderID); err != nil {
+		return errors.Wrap(err, "marking payments for reversal")
+	}
+	return nil
+}
+
+// ReverseOrderPayments voids the marked authorized payments of an order and
+// refunds what its marked captured payments have not refunded yet. It must
+// run after the cancellation has committed. Every payment is tried; the first
+// error is returned.
+func (s *ReversalService) ReverseOrderPayments(ctx context.Context, orderID primitive.ObjectID) error {
+	payments, err := s.repo.ListPaymentsByOrder(ctx, orderID)
+	if err != nil {
+		return errors.Wrap(err, "listing payments")
+	}
+	var firstErr error
+	for i := range payments {
+		if !payments[i].ReversalPending {
+			continue
+		}
+		if err := s.reversePayment(ctx, &payments[i]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ReversePendingPayments retries every marked payment that still holds money,
+// returning how many were given back.
+func (s *ReversalService) ReversePendingPayments(ctx context.Context) (int, error) {
+	payments, err := s.repo.ListPendingReversals(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "listing payments to reverse")
+	}
+	reversed := 0
+	var firstErr error
+	for i := range payments {
+		err := s.reversePayment(ctx, &payments[i])
+		if err == nil {
+			reversed++
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return reversed, firstErr
+}
+
+// ReconcileRefunds settles the refunds claimed more than timeout ago whose
+// outcome was never saved, because the process stopped or the save failed
+// after the provider was called. Each is looked up at the provider: a refund
+// it made is recorded, and a payment it has no refund for goes back to the
+// status it was claimed from so that it can be refunded again. Claims the
+// provider could not be asked about are kept for the next run. It returns
+// how many claims were settled.
+func (s *ReversalService) ReconcileRefunds(ctx context.Context, timeout time.Duration) (int, error) {
+	payments, err := s.repo.ListRefundClaimsBefore(ctx, time.Now().Add(-timeout))
+	if err != nil {
+		return 0, errors.Wrap(err, "listing refund claims")
+	}
+	settled := 0
+	var firstErr error
+	for i := range payments {
+		payment := &payments[i]
+		result, providerErr := s.provider.FindRefund(ctx, payment.ProviderRef, payment.RefundClaim.Reference)
+		if providerErr != nil && !stdErrors.Is(providerErr, providers.ErrNotFound) {
+			if firstErr == nil {
+				firstErr = paymentError(providerErr)
+			}
+			continue
+		}
+		if err := settleRefund(ctx, s.repo, payment, result, providerErr); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		settled++
+	}
+	return settled, firstErr
+}
+
+// reversePayment gives one payment's money back. A refund is claimed by a
+// committed conditional write before the provider is called, see refund. A
+// payment that is being refunded is skipped: the refund in flight settles
+// it, or ReconcileRefunds does once the claim has timed out, and what is
+// still refundable then is refunded on the next pass.
+func (s *ReversalService) reversePayment(ctx context.Context, payment *models.Payment) error {
+	switch payment.Status {
+	case models.PaymentStatusAuthorized:
+		return void(ctx, s.repo, s.provider, payment)
+	case models.PaymentStatusCaptured, models.PaymentStatusPartiallyRefunded:
+		amount, err := payment.Refundable()
+		if err != nil {
+			return errors.Wrap(err, "totalling refunds")
+		}
+		if amount.IsZero() {
+			return nil
+		}
+		return refund(ctx, s.repo, s.provider, payment, amount)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/payments/models"
+	"github.com/devbenho/luka-platform/internal/payments/providers"
+	"github.com/devbenho/luka-platform/internal/payments/repositories"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakePaymentRepo stores payments by ID and applies conditional updates.
+type fakePaymentRepo struct {
+	repositories.IPaymentRepository
+	payments map[primitive.ObjectID]models.Payment
+}
+
+func (r *fakePaymentRepo) ListRefundClaimsBefore(ctx context.Context, before time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	for _, payment := range r.payments {
+		if payment.Status == models.PaymentStatusRefunding && payment.RefundClaim.ClaimedAt.Before(before) {
+			payments = append(payments, payment)
+		}
+	}
+	return payments, nil
+}
+
+func (r *fakePaymentRepo) UpdatePayment(ctx context.Context, payment *models.Payment, expected models.PaymentStatus) error {
+	if r.payments[payment.ID].Status != expected {
+		return fmt.Errorf("payment %s is not %s", payment.ID.Hex(), expected)
+	}
+	r.payments[payment.ID] = *payment
+	return nil
+}
+
+// refundLookup answers FindRefund with a fixed outcome.
+type refundLookup struct {
+	providers.IPaymentProvider
+	err error
+}
+
+func (p refundLookup) FindRefund(ctx context.Context, providerRef, reference string) (*providers.Result, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &providers.Result{ProviderRef: providerRef, TransactionID: "txn_" + reference}, nil
+}
+
+func TestReconcileRefunds(t *testing.T) {
+	usd := func(amount int64) money.Money {
+		m, _ := money.New(amount, "USD")
+		return m
+	}
+
+	tests := []struct {
+		name         string
+		refunded     int64
+		claimAge     time.Duration
+		lookupErr    error
+		wantSettled  int
+		wantErr      bool
+		wantStatus   models.PaymentStatus
+		wantRefunded int64
+	}{
+		{"refund made completes the claim", 0, time.Hour, nil, 1, false, models.PaymentStatusPartiallyRefunded, 400},
+		{"last refund made refunds the payment", 600, time.Hour, nil, 1, false, models.PaymentStatusRefunded, 1000},
+		{"no refund made releases the claim", 0, time.Hour, fmt.Errorf("refund: %w", providers.ErrNotFound), 1, false, models.PaymentStatusCaptured, 0},
+		{"unreachable provider keeps the claim", 0, time.Hour, stdErrors.New("timeout"), 0, true, models.PaymentStatusRefunding, 0},
+		{"recent claims are left alone", 0, time.Second, nil, 0, false, models.PaymentStatusRefunding, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := models.PaymentStatusCaptured
+			if tt.refunded > 0 {
+				from = models.PaymentStatusPartiallyRefunded
+			}
+			payment := models.Payment{
+				ID:             primitive.NewObjectID(),
+				ProviderRef:    "auth_1",
+				Status:         models.PaymentStatusRefunding,
+				Amount:         usd(1000),
+				CapturedAmount: usd(1000),
+				RefundedAmount: usd(tt.refunded),
+				RefundClaim: &models.RefundClaim{
+					Reference: "ref_1",
+					Amount:    usd(400),
+					From:      from,
+					ClaimedAt: time.Now().Add(-tt.claimAge),
+				},
+			}
+			repo := &fakePaymentRepo{payments: map[primitive.ObjectID]models.Payment{payment.ID: payment}}
+			service := NewReversalService(repo, refundLookup{err: tt.lookupErr})
+
+			settled, err := service.ReconcileRefunds(context.Background(), time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReconcileRefunds() error = %v, want error %t", err, tt.wantErr)
+			}
+			if settled != tt.wantSettled {
+				t.Errorf("ReconcileRefunds() settled %d, want %d", settled, tt.wantSettled)
+			}
+			got := repo.payments[payment.ID]
+			if got.Status != tt.wantStatus || got.RefundedAmount.Amount != tt.wantRefunded {
+				t.Errorf("payment is %s with %d refunded, want %s with %d", got.Status, got.RefundedAmount.Amount, tt.wantStatus, tt.wantRefunded)
+			}
+			if settled := got.Status != models.PaymentStatusRefunding; settled != (got.RefundClaim == nil) {
+				t.Errorf("refund claim = %+v with status %s", got.RefundClaim, got.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReversalSweeper periodically settles refunds that were interrupted and
+// retries giving back the money of cancelled orders whose reversal failed.
+type ReversalSweeper struct {
+	service *ReversalService
+	// claimTimeout is how long a refund claim may stay unsettled before it
+	// is reconciled with the provider. It must exceed the time a provider
+	// call can take, so that no refund is still in flight.
+	claimTimeout time.Duration
+	interval     time.Duration
+	logger       *zap.Logger
+}
+
+func NewReversalSweeper(service *ReversalService, claimTimeout, interval time.Duration, logger *zap.Logger) *ReversalSweeper {
+	return &ReversalSweeper{
+		service:      service,
+		claimTimeout: claimTimeout,
+		interval:     interval,
+		logger:       logger,
+	}
+}
+
+// Run sweeps until ctx is cancelled. It is meant to be started in its own goroutine.
+func (s *ReversalSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Settled claims may leave money to give back, which the
+			// reversal below picks up.
+			settled, err := s.service.ReconcileRefunds(ctx, s.claimTimeout)
+			if err != nil {
+				s.logger.Error("reconciling interrupted refunds", zap.Error(err))
+			}
+			if settled > 0 {
+				s.logger.Info("reconciled interrupted refunds", zap.Int("count", settled))
+			}
+			reversed, err := s.service.ReversePendingPayments(ctx)
+			if err != nil {
+				s.logger.Error("reversing payments of cancelled orders", zap.Error(err))
+			}
+			if reversed > 0 {
+				s.logger.Info("reversed payments of cancelled orders", zap.Int("count", reversed))
+			}
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"log"
+	"time"
+
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	orderDtos "github.com/devbenho/luka-platform/internal/orders/order_dtos"
+	orderSvc "github.com/devbenho/luka-platform/internal/orders/services"
+	"github.com/devbenho/luka-platform/internal/payments/models"
+	"github.com/devbenho/luka-platform/internal/payments/repositories"
+	"github.com/devbenho/luka-platform/internal/payments/webhooks"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// errDuplicateEvent aborts the transaction of an event that a concurrent
+// delivery has already recorded.
+var errDuplicateEvent = stdErrors.New("duplicate webhook event")
+
+type IWebhookService interface {
+	// HandleEvent verifies and applies a provider event. It reports whether
+	// the event had already been processed, in which case nothing changed.
+	HandleEvent(ctx context.Context, provider string, payload []byte, signature string) (*models.WebhookEvent, bool, error)
+}
+
+type WebhookService struct {
+	db           database.IDatabase
+	repo         repositories.IPaymentRepository
+	eventRepo    repositories.IWebhookEventRepository
+	orderService orderSvc.IOrderService
+	secrets      map[string]string
+	tolerance    time.Duration
+}
+
+// NewWebhookService accepts events from the providers secrets has a signing
+// secret for. Signatures older or newer than tolerance are refused.
+func NewWebhookService(
+	db database.IDatabase,
+	repo repositories.IPaymentRepository,
+	eventRepo repositories.IWebhookEventRepository,
+	orderService orderSvc.IOrderService,
+	secrets map[string]string,
+	tolerance time.Duration,
+) *WebhookService {
+	return &WebhookService{
+		db:           db,
+		repo:         repo,
+		eventRepo:    eventRepo,
+		orderService: orderService,
+		secrets:      secrets,
+		tolerance:    tolerance,
+	}
+}
+
+func (s *WebhookService) HandleEvent(ctx context.Context, provider string, payload []byte, signature string) (*models.WebhookEvent, bool, error) {
+	secret, ok := s.secrets[provider]
+	if !ok || secret == "" {
+		return nil, false, errors.NewNotFoundError("webhook provider", provider)
+	}
+	if err := webhooks.Verify(secret, signature, payload, time.Now(), s.tolerance); err != nil {
+		return nil, false, errors.NewError(errors.UnauthorizedType, 401, err.Error())
+	}
+
+	var event webhooks.Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, false, errors.NewBadRequestError("invalid webhook payload")
+	}
+	if event.ID == "" || event.Type == "" {
+		return nil, false, errors.NewBadRequestError("webhook event must have an id and a type")
+	}
+
+	record := &models.WebhookEvent{
+		ID:         models.WebhookEventID(provider, event.ID),
+		Provider:   provider,
+		EventID:    event.ID,
+		Type:       event.Type,
+		OrderID:    event.Data.OrderID,
+		Payload:    string(payload),
+		ReceivedAt: time.Now(),
+	}
+	duplicate := false
+	err := s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		exists, err := s.eventRepo.EventExists(sessCtx, record.ID)
+		if err != nil {
+			return errors.Wrap(err, "checking webhook event")
+		}
+		if exists {
+			duplicate = true
+			return nil
+		}
+
+		// The event is applied and recorded in one transaction: if applying
+		// fails, the provider's retry will find no record and try again.
+		if err := s.apply(sessCtx, provider, event, record); err != nil {
+			return err
+		}
+		if err := s.eventRepo.CreateEvent(sessCtx, record); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return errDuplicateEvent
+			}
+			return errors.Wrap(err, "recording webhook event")
+		}
+		return nil
+	})
+	if stdErrors.Is(err, errDuplicateEvent) {
+		return record, true, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "handling webhook event")
+	}
+	return record, duplicate, nil
+}
+
+// apply updates the payment an event is about and moves its order on. Event
+// types this platform does not act on are only recorded.
+func (s *WebhookService) apply(ctx context.Context, provider string, event webhooks.Event, record *models.WebhookEvent) error {
+	switch event.Type {
+	case webhooks.EventPaymentSucceeded, webhooks.EventPaymentFailed, webhooks.EventPaymentRefunded:
+	default:
+		log.Printf("webhook %s: ignoring event type %s", record.ID, event.Type)
+		return nil
+	}
+
+	payment, err := s.findPayment(ctx, provider, event)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		if event.Type == webhooks.EventPaymentRefunded {
+			log.Printf("webhook %s: no payment to refund", record.ID)
+			return nil
+		}
+		if payment, err = s.createPayment(ctx, provider, event); err != nil {
+			return err
+		}
+	}
+	record.PaymentID = payment.ID
+	record.OrderID = payment.OrderID
+
+	expected := payment.Status
+	var to orderModels.OrderStatus
+	switch event.Type {
+	case webhooks.EventPaymentSucceeded:
+		if payment.Status != models.PaymentStatusAuthorized {
+			return nil
+		}
+		amount := payment.Amount
+		if event.Data.Amount != nil {
+			amount = *event.Data.Amount
+		}
+		// A settlement for another amount or currency than the order's
+		// total is refused, so that the order is never processed on a
+		// payment that does not cover it.
+		if cmp, err := amount.Cmp(payment.Amount); err != nil || cmp != 0 {
+			log.Printf("webhook %s: settled %s for payment %s of %s", record.ID, amount.String(), payment.ID.Hex(), payment.Amount.String())
+			return errors.NewError(
+				errors.PaymentFailed,
+				422,
+				"settled amount does not match the payment",
+				errors.WithMetadata(map[string]interface{}{
+					"expected": payment.Amount,
+					"received": amount,
+				}),
+			)
+		}
+		payment.Status = models.PaymentStatusCaptured
+		payment.CapturedAmount = amount
+		appendEvent(payment, models.TransactionCapture, amount, event.ID, "")
+		to = orderModels.OrderStatusProcessing
+	case webhooks.EventPaymentFailed:
+		if payment.Status != models.PaymentStatusAuthorized {
+			return nil
+		}
+		reason := event.Data.Reason
+		if reason == "" {
+			reason = "payment failed"
+		}
+		payment.Status = models.PaymentStatusFailed
+		payment.FailureReason = reason
+		appendEvent(payment, models.TransactionAuthorize, payment.Amount, event.ID, reason)
+		to = orderModels.OrderStatusCancelled
+	case webhooks.EventPaymentRefunded:
+		if payment.Status != models.PaymentStatusCaptured && payment.Status != models.PaymentStatusPartiallyRefunded {
+			return nil
+		}
+		refundable, err := payment.Refundable()
+		if err != nil {
+			return errors.Wrap(err, "totalling refunds")
+		}
+		amount := refundable
+		if event.Data.Amount != nil {
+			amount = *event.Data.Amount
+		}
+		// A refund of more than is left, typically a replayed or reordered
+		// event, is refused so that it cannot cancel an order whose money
+		// was not all given back.
+		if cmp, err := amount.Cmp(refundable); err != nil || cmp > 0 || amount.IsZero() || amount.IsNegative() {
+			log.Printf("webhook %s: refunded %s for payment %s with %s refundable", record.ID, amount.String(), payment.ID.Hex(), refundable.String())
+			return errors.NewError(
+				errors.PaymentFailed,
+				422,
+				"refunded amount must be positive and at most what is refundable",
+				errors.WithMetadata(map[string]interface{}{
+					"refundable": refundable,
+					"received":   amount,
+				}),
+			)
+		}
+		if payment.RefundedAmount, err = payment.RefundedAmount.Add(amount); err != nil {
+			return errors.Wrap(err, "totalling refunds")
+		}
+		payment.Status = models.PaymentStatusPartiallyRefunded
+		if cmp, err := payment.RefundedAmount.Cmp(payment.CapturedAmount); err == nil && cmp >= 0 {
+			payment.Status = models.PaymentStatusRefunded
+			to = orderModels.OrderStatusCancelled
+		}
+		appendEvent(payment, models.TransactionRefund, amount, event.ID, "")
+	}
+
+	if err := s.repo.UpdatePayment(ctx, payment, expected); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.NewConflictError("payment was modified concurrently")
+		}
+		return errors.Wrap(err, "updating payment")
+	}
+	if to == "" {
+		return nil
+	}
+	return s.moveOrder(ctx, payment, to, "payment webhook "+record.ID)
+}
+
+// moveOrder applies the order transition an event implies when the order is
+// still in a state it applies to: a payment settling after the order was
+// cancelled, or a refund after it shipped, leaves the order alone.
+func (s *WebhookService) moveOrder(ctx context.Context, payment *models.Payment, to orderModels.OrderStatus, note string) error {
+	order, err := s.orderService.GetOrderByID(ctx, payment.OrderID.Hex())
+	if err != nil {
+		return err
+	}
+
+	switch order.Status {
+	case orderModels.OrderStatusPending, orderModels.OrderStatusConfirmed:
+	case orderModels.OrderStatusProcessing:
+		if to != orderModels.OrderStatusCancelled {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	return s.orderService.UpdateOrderStatus(ctx, order.ID.Hex(), orderModels.SystemActor, orderDtos.UpdateOrderStatusRequest{
+		Status: to,
+		Note:   note,
+	})
+}
+
+// findPayment looks the event's payment up by the provider's reference, or
+// returns nil when the provider knows a payment this platform never started.
+func (s *WebhookService) findPayment(ctx context.Context, provider string, event webhooks.Event) (*models.Payment, error) {
+	if event.Data.ProviderRef == "" {
+		return nil, nil
+	}
+	payment, err := s.repo.GetPaymentByProviderRef(ctx, provider, event.Data.ProviderRef)
+	if stdErrors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "finding payment")
+	}
+	return payment, nil
+}
+
+// createPayment records a payment the buyer made directly with the provider,
+// such as on a hosted checkout page, for the order named in the event.
+func (s *WebhookService) createPayment(ctx context.Context, provider string, event webhooks.Event) (*models.Payment, error) {
+	if event.Data.OrderID.IsZero() {
+		return nil, errors.NewBadRequestError("webhook event names neither a known payment nor an order")
+	}
+	order, err := s.orderService.GetOrderByID(ctx, event.Data.OrderID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &models.Payment{
+		OrderID:        order.ID,
+		CustomerID:     order.CustomerID,
+		Provider:       provider,
+		ProviderRef:    event.Data.ProviderRef,
+		Status:         models.PaymentStatusAuthorized,
+		Amount:         order.TotalAmount,
+		CapturedAmount: order.TotalAmount.Zero(),
+		RefundedAmount: order.TotalAmount.Zero(),
+	}
+	created, err := s.repo.CreatePayment(ctx, payment)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errors.NewConflictError("order already has an active payment")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "creating payment")
+	}
+	return created, nil
+}
+
+// appendEvent records what a provider event did to the payment. A non-empty
+// failure marks the transaction as unsuccessful.
+func appendEvent(payment *models.Payment, kind models.TransactionType, amount money.Money, eventID, failure string) {
+	payment.Transactions = append(payment.Transactions, models.Transaction{
+		Type:      kind,
+		Amount:    amount,
+		Reference: eventID,
+		Success:   failure == "",
+		Error:     failure,
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,59 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Emitter posts signed events the way a payment provider would. It stands in
+// for real providers in tests and local development.
+type Emitter struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewEmitter sends events to url, the full webhook route of one provider,
+// such as http://localhost:2707/api/v1/webhooks/payments/fake.
+func NewEmitter(url, secret string) *Emitter {
+	return &Emitter{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Emit signs and delivers event, failing unless it is acknowledged with a
+// 2xx status. Emitting the same event twice exercises deduplication.
+func (e *Emitter) Emit(ctx context.Context, event Event) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(e.secret, time.Now(), payload))
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver event: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("event %s rejected with status %d: %s", event.ID, resp.StatusCode, body)
+	}
+	return nil
+}
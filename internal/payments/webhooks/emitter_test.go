@@ -0,0 +1,29 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEmitterSignsEvents(t *testing.T) {
+	const secret = "whsec_test"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		if err := Verify(secret, r.Header.Get(SignatureHeader), payload, time.Now(), time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	event := Event{ID: "evt_1", Type: EventPaymentSucceeded}
+	if err := NewEmitter(server.URL, secret).Emit(context.Background(), event); err != nil {
+		t.Fatalf("Emit() with the shared secret: %v", err)
+	}
+	if err := NewEmitter(server.URL, "other").Emit(context.Background(), event); err == nil {
+		t.Fatal("Emit() with another secret was accepted")
+	}
+}
@@ -0,0 +1,34 @@
+package webhooks
+
+import (
+	"time"
+
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event types payment providers notify us about.
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+	EventPaymentRefunded  = "payment.refunded"
+)
+
+// Event is the body of a payment webhook. ID is unique per provider and is
+// what redeliveries of the same event are recognised by.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      EventData `json:"data"`
+}
+
+// EventData identifies the payment by the provider's reference and, for
+// payments started outside the platform, by the order it pays for.
+type EventData struct {
+	OrderID     primitive.ObjectID `json:"order_id,omitempty"`
+	ProviderRef string             `json:"provider_ref,omitempty"`
+	// Amount defaults to the whole payment.
+	Amount *money.Money `json:"amount,omitempty"`
+	Reason string       `json:"reason,omitempty"`
+}
@@ -0,0 +1,52 @@
+package webhooks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"id":"evt_1","type":"payment.succeeded"}`)
+	sentAt := time.Unix(1700000000, 0)
+	signed := Sign(secret, sentAt, payload)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		payload []byte
+		now     time.Time
+		want    error
+	}{
+		{"valid", secret, signed, payload, sentAt, nil},
+		{"within tolerance", secret, signed, payload, sentAt.Add(4 * time.Minute), nil},
+		{"clock behind sender", secret, signed, payload, sentAt.Add(-4 * time.Minute), nil},
+		{"rotated secret", secret, signed + ",v1=deadbeef", payload, sentAt, nil},
+		{"missing header", secret, "", payload, sentAt, ErrMissingSignature},
+		{"no timestamp", secret, "v1=" + compute(secret, "1700000000", payload), payload, sentAt, ErrInvalidSignature},
+		{"no signature", secret, "t=1700000000", payload, sentAt, ErrInvalidSignature},
+		{"malformed timestamp", secret, "t=soon,v1=abc", payload, sentAt, ErrInvalidSignature},
+		{"wrong secret", "other", signed, payload, sentAt, ErrInvalidSignature},
+		{"tampered payload", secret, signed, []byte(`{"id":"evt_2"}`), sentAt, ErrInvalidSignature},
+		{"replayed later", secret, signed, payload, sentAt.Add(6 * time.Minute), ErrStaleSignature},
+		{"from the future", secret, signed, payload, sentAt.Add(-6 * time.Minute), ErrStaleSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.payload, tt.now, 5*time.Minute)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignFormat(t *testing.T) {
+	got := Sign("secret", time.Unix(42, 0), []byte("{}"))
+	want := "t=42,v1=" + compute("secret", "42", []byte("{}"))
+	if got != want {
+		t.Fatalf("Sign() = %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package dtos
 
 import (
 	"github.com/devbenho/luka-platform/internal/product/models"
+	"github.com/devbenho/luka-platform/pkg/money"
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -9,10 +10,11 @@ import (
 type CreateProductRequest struct {
 	Name        string                `json:"name" binding:"required"`
 	Description string                `json:"description" binding:"required"`
-	Price       float64               `json:"price" binding:"required"`
+	Price       money.Money           `json:"price" binding:"required"`
 	StoreID     primitive.ObjectID    `json:"store_id" binding:"required"`
 	Categories  []*primitive.ObjectID `json:"categories" binding:"required"`
 	Images      []string              `json:"images" bson:"images" binding:"required"`
+	WeightGrams int64                 `json:"weight_grams" binding:"min=0"`
 }
 
 type CreateProductResponse struct {
@@ -27,6 +29,7 @@ func (r *CreateProductRequest) ToProduct() *models.Product {
 		StoreID:     r.StoreID,
 		Categories:  r.Categories,
 		Images:      r.Images,
+		WeightGrams: r.WeightGrams,
 	}
 }
 
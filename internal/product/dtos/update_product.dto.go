@@ -2,6 +2,7 @@ package dtos
 
 import (
 	"github.com/devbenho/luka-platform/internal/product/models"
+	"github.com/devbenho/luka-platform/pkg/money"
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -9,10 +10,11 @@ import (
 type UpdateProductRequest struct {
 	Name        *string               `json:"name"`
 	Description *string               `json:"description"`
-	Price       *float64              `json:"price"`
+	Price       *money.Money          `json:"price"`
 	StoreID     *primitive.ObjectID   `json:"store_id"`
 	Categories  []*primitive.ObjectID `json:"categories"`
 	Images      *[]string             `json:"images"`
+	WeightGrams *int64                `json:"weight_grams" binding:"omitempty,min=0"`
 }
 
 type UpdateProductResponse struct {
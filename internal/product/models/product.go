@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/devbenho/luka-platform/pkg/money"
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -11,10 +12,11 @@ type Product struct {
 	ID          primitive.ObjectID    `json:"id" bson:"_id"`
 	Name        string                `json:"name" bson:"name"`
 	Description interface{}           `json:"description" bson:"description"`
-	Price       float64               `json:"price" bson:"price"`
+	Price       money.Money           `json:"price" bson:"price"`
 	StoreID     primitive.ObjectID    `json:"store_id" bson:"store_id"`
 	Categories  []*primitive.ObjectID `json:"categories" bson:"categories"`
 	Images      []string              `json:"images" bson:"images"`
+	WeightGrams int64                 `json:"weight_grams" bson:"weight_grams"`
 	CreatedAt   time.Time             `json:"created_at" bson:"created_at"`
 	UpdatedAt   time.Time             `json:"updated_at" bson:"updated_at"`
 	DeletedAt   *time.Time            `json:"deleted_at" bson:"deleted_at"`
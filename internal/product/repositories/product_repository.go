@@ -63,10 +63,11 @@ func (r *ProductRepository) UpdateProduct(ctx context.Context, id string, produc
 	filter := bson.M{"_id": objID}
 	update := bson.M{
 		"$set": bson.M{
-			"name":        product.Name,
-			"description": product.Description,
-			"price":       product.Price,
-			"updated_at":  product.UpdatedAt,
+			"name":         product.Name,
+			"description":  product.Description,
+			"price":        product.Price,
+			"weight_grams": product.WeightGrams,
+			"updated_at":   product.UpdatedAt,
 		},
 	}
 	return r.db.Update(ctx, "products", filter, update)
@@ -4,10 +4,10 @@ import (
 	"context"
 	"time"
 
+	ownership "github.com/devbenho/luka-platform/internal/ownership/services"
 	"github.com/devbenho/luka-platform/internal/product/dtos"
 	"github.com/devbenho/luka-platform/internal/product/models"
 	"github.com/devbenho/luka-platform/internal/product/repositories"
-	storeRepo "github.com/devbenho/luka-platform/internal/store/repositories"
 	"github.com/devbenho/luka-platform/internal/utils"
 	"github.com/devbenho/luka-platform/pkg/errors"
 	"github.com/devbenho/luka-platform/pkg/validation"
@@ -22,15 +22,15 @@ type IProductService interface {
 
 type ProductService struct {
 	repo      repositories.IProductRepository
-	storeRepo storeRepo.IStoreRepository
+	ownership ownership.IOwnershipService
 	validator *validation.Validator
 }
 
-func NewProductService(repository repositories.IProductRepository, storeRepo storeRepo.IStoreRepository, validator *validation.Validator) IProductService {
+func NewProductService(repository repositories.IProductRepository, ownership ownership.IOwnershipService, validator *validation.Validator) IProductService {
 	return &ProductService{
 		repo:      repository,
 		validator: validator,
-		storeRepo: storeRepo,
+		ownership: ownership,
 	}
 }
 
@@ -38,10 +38,12 @@ func (s *ProductService) CreateProduct(ctx context.Context, product *dtos.Create
 	if err := s.validator.ValidateStruct(product); err != nil {
 		return nil, errors.Wrap(err, "validating product")
 	}
+	if err := product.Price.Validate(); err != nil {
+		return nil, errors.NewError(errors.ValidationErrorType, 400, err.Error(), errors.WithField("price"))
+	}
 
-	_, err := s.storeRepo.GetStoreByID(ctx, product.StoreID.Hex())
-	if err != nil {
-		return nil, errors.NewNotFoundError("store", product.StoreID.Hex())
+	if _, err := s.ownership.AuthorizeStore(ctx, product.StoreID); err != nil {
+		return nil, err
 	}
 
 	productResult, err := s.repo.CreateProduct(ctx, product.ToProduct())
@@ -76,6 +78,12 @@ func (s *ProductService) UpdateProduct(ctx context.Context, id string, product *
 		return nil, err
 	}
 
+	if product.Price != nil {
+		if err := product.Price.Validate(); err != nil {
+			return nil, errors.NewError(errors.ValidationErrorType, 400, err.Error(), errors.WithField("price"))
+		}
+	}
+
 	existingProduct, err := s.repo.GetProductByID(ctx, id)
 	if err != nil {
 		return nil, errors.Wrap(err, "finding product")
@@ -83,6 +91,15 @@ func (s *ProductService) UpdateProduct(ctx context.Context, id string, product *
 	if existingProduct.DeletedAt != nil {
 		return nil, errors.NewNotFoundError("product", id)
 	}
+	if _, err := s.ownership.AuthorizeStore(ctx, existingProduct.StoreID); err != nil {
+		return nil, err
+	}
+	// Moving a product takes owning the store it moves to as well.
+	if product.StoreID != nil && *product.StoreID != existingProduct.StoreID {
+		if _, err := s.ownership.AuthorizeStore(ctx, *product.StoreID); err != nil {
+			return nil, err
+		}
+	}
 
 	utils.Copy(existingProduct, product)
 	if err := s.repo.UpdateProduct(ctx, id, existingProduct); err != nil {
@@ -100,6 +117,9 @@ func (s *ProductService) DeleteProduct(ctx context.Context, id string) error {
 	if product.DeletedAt != nil {
 		return errors.NewNotFoundError("product", id)
 	}
+	if _, err := s.ownership.AuthorizeStore(ctx, product.StoreID); err != nil {
+		return err
+	}
 
 	now := time.Now()
 	product.DeletedAt = &now
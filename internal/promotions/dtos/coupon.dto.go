@@ -0,0 +1,44 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/promotions/models"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CreateCouponRequest struct {
+	Code               string               `json:"code" validate:"required,alphanum,min=3,max=50"`
+	Description        string               `json:"description" validate:"max=200"`
+	Type               models.DiscountType  `json:"type" validate:"required,oneof=PERCENTAGE FIXED_AMOUNT FREE_SHIPPING"`
+	PercentBasisPoints int64                `json:"percent_basis_points" validate:"required_if=Type PERCENTAGE,gte=0,lte=10000"`
+	Amount             *money.Money         `json:"amount" validate:"required_if=Type FIXED_AMOUNT"`
+	MinCartValue       *money.Money         `json:"min_cart_value"`
+	MaxUses            int                  `json:"max_uses" validate:"gte=0"`
+	MaxUsesPerCustomer int                  `json:"max_uses_per_customer" validate:"gte=0"`
+	ProductIDs         []primitive.ObjectID `json:"product_ids"`
+	CategoryIDs        []primitive.ObjectID `json:"category_ids"`
+	StoreIDs           []primitive.ObjectID `json:"store_ids"`
+	StartsAt           *time.Time           `json:"starts_at"`
+	EndsAt             *time.Time           `json:"ends_at"`
+}
+
+func (r *CreateCouponRequest) ToCoupon() *models.Coupon {
+	return &models.Coupon{
+		Code:               models.NormalizeCode(r.Code),
+		Description:        r.Description,
+		Type:               r.Type,
+		PercentBasisPoints: r.PercentBasisPoints,
+		Amount:             r.Amount,
+		MinCartValue:       r.MinCartValue,
+		MaxUses:            r.MaxUses,
+		MaxUsesPerCustomer: r.MaxUsesPerCustomer,
+		ProductIDs:         r.ProductIDs,
+		CategoryIDs:        r.CategoryIDs,
+		StoreIDs:           r.StoreIDs,
+		StartsAt:           r.StartsAt,
+		EndsAt:             r.EndsAt,
+		Active:             true,
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DiscountType string
+
+const (
+	DiscountTypePercentage   DiscountType = "PERCENTAGE"
+	DiscountTypeFixedAmount  DiscountType = "FIXED_AMOUNT"
+	DiscountTypeFreeShipping DiscountType = "FREE_SHIPPING"
+)
+
+// Coupon is a discount code. Product, category and store scopes restrict
+// which items the discount applies to; when all are empty it applies to the
+// whole cart. A zero usage limit means unlimited.
+type Coupon struct {
+	ID                 primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Code               string               `bson:"code" json:"code"`
+	Description        string               `bson:"description,omitempty" json:"description,omitempty"`
+	Type               DiscountType         `bson:"type" json:"type"`
+	PercentBasisPoints int64                `bson:"percent_basis_points,omitempty" json:"percent_basis_points,omitempty"`
+	Amount             *money.Money         `bson:"amount,omitempty" json:"amount,omitempty"`
+	MinCartValue       *money.Money         `bson:"min_cart_value,omitempty" json:"min_cart_value,omitempty"`
+	MaxUses            int                  `bson:"max_uses" json:"max_uses"`
+	MaxUsesPerCustomer int                  `bson:"max_uses_per_customer" json:"max_uses_per_customer"`
+	UsedCount          int                  `bson:"used_count" json:"used_count"`
+	ProductIDs         []primitive.ObjectID `bson:"product_ids,omitempty" json:"product_ids,omitempty"`
+	CategoryIDs        []primitive.ObjectID `bson:"category_ids,omitempty" json:"category_ids,omitempty"`
+	StoreIDs           []primitive.ObjectID `bson:"store_ids,omitempty" json:"store_ids,omitempty"`
+	StartsAt           *time.Time           `bson:"starts_at,omitempty" json:"starts_at,omitempty"`
+	EndsAt             *time.Time           `bson:"ends_at,omitempty" json:"ends_at,omitempty"`
+	Active             bool                 `bson:"active" json:"active"`
+	CreatedAt          time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt          time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// NormalizeCode makes coupon codes case-insensitive.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsValidAt reports whether the coupon is active and inside its validity
+// window.
+func (c *Coupon) IsValidAt(now time.Time) bool {
+	if !c.Active {
+		return false
+	}
+	if c.StartsAt != nil && now.Before(*c.StartsAt) {
+		return false
+	}
+	if c.EndsAt != nil && !now.Before(*c.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// IsScoped reports whether the coupon only applies to some items.
+func (c *Coupon) IsScoped() bool {
+	return len(c.ProductIDs) > 0 || len(c.CategoryIDs) > 0 || len(c.StoreIDs) > 0
+}
+
+// AppliesTo reports whether the coupon covers an item. Scopes are additive:
+// an item matching any listed product, category or store is covered.
+func (c *Coupon) AppliesTo(item DiscountableItem) bool {
+	if !c.IsScoped() {
+		return true
+	}
+	if containsID(c.ProductIDs, item.ProductID) || containsID(c.StoreIDs, item.StoreID) {
+		return true
+	}
+	for _, category := range item.Categories {
+		if category != nil && containsID(c.CategoryIDs, *category) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DiscountableItem is a cart line a coupon may apply to. Amount is the line
+// total at the listed price.
+type DiscountableItem struct {
+	ProductID  primitive.ObjectID
+	StoreID    primitive.ObjectID
+	Categories []*primitive.ObjectID
+	Amount     money.Money
+}
+
+// Discount is the result of applying a coupon to a cart. ItemAmounts holds
+// the discount of each item, in the order the items were given, and adds up
+// to Amount.
+type Discount struct {
+	CouponID     primitive.ObjectID
+	Code         string
+	Type         DiscountType
+	FreeShipping bool
+	// ShippingStoreIDs lists the stores whose shipping a free shipping coupon
+	// waives: those selling at least one item the coupon applies to.
+	ShippingStoreIDs []primitive.ObjectID
+	Amount           money.Money
+	ItemAmounts      []money.Money
+}
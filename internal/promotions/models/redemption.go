@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Redemption records one use of a coupon by a customer.
+type Redemption struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CouponID   primitive.ObjectID `bson:"coupon_id" json:"coupon_id"`
+	CustomerID primitive.ObjectID `bson:"customer_id" json:"customer_id"`
+	CheckoutID primitive.ObjectID `bson:"checkout_id" json:"checkout_id"`
+	Amount     money.Money        `bson:"amount" json:"amount"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
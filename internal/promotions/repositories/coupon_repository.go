@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/promotions/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ICouponRepository interface {
+	// CreateCoupon fails with a duplicate key error, which
+	// mongo.IsDuplicateKeyError detects, when the code is taken.
+	CreateCoupon(ctx context.Context, coupon *models.Coupon) (*models.Coupon, error)
+	GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error)
+	ListCoupons(ctx context.Context, page database.PageRequest) ([]models.Coupon, *database.Page, error)
+	DeactivateCoupon(ctx context.Context, id string) error
+	IncrementUsage(ctx context.Context, id primitive.ObjectID) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type CouponRepository struct {
+	db database.IDatabase
+}
+
+func NewCouponRepository(db database.IDatabase) ICouponRepository {
+	return &CouponRepository{
+		db: db,
+	}
+}
+
+func (r *CouponRepository) CreateCoupon(ctx context.Context, coupon *models.Coupon) (*models.Coupon, error) {
+	coupon.ID = primitive.NewObjectID()
+	coupon.CreatedAt = time.Now()
+	coupon.UpdatedAt = time.Now()
+	if err := r.db.Create(ctx, "coupons", coupon); err != nil {
+		return nil, fmt.Errorf("failed to create coupon in db: %w", err)
+	}
+	return coupon, nil
+}
+
+func (r *CouponRepository) GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error) {
+	var coupon models.Coupon
+	filter := bson.M{"code": models.NormalizeCode(code)}
+	if err := r.db.FindOne(ctx, "coupons", filter, &coupon); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("coupon not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get coupon from db: %w", err)
+	}
+	return &coupon, nil
+}
+
+func (r *CouponRepository) ListCoupons(ctx context.Context, page database.PageRequest) ([]models.Coupon, *database.Page, error) {
+	coupons := []models.Coupon{}
+	result, err := r.db.FindPage(ctx, "coupons", database.Query{
+		Sort: bson.D{{Key: "created_at", Value: -1}},
+		Page: page,
+	}, &coupons)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list coupons from db: %w", err)
+	}
+	return coupons, result, nil
+}
+
+func (r *CouponRepository) DeactivateCoupon(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid coupon ID: %w", err)
+	}
+	filter := bson.M{"_id": objID}
+	update := bson.M{"$set": bson.M{"active": false, "updated_at": time.Now()}}
+	return r.db.Update(ctx, "coupons", filter, update)
+}
+
+// IncrementUsage counts one more use of the coupon as long as its usage limit
+// allows it. It returns mongo.ErrNoDocuments once the limit is reached.
+func (r *CouponRepository) IncrementUsage(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":    id,
+		"active": true,
+		"$or": bson.A{
+			bson.M{"max_uses": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$used_count", "$max_uses"}}},
+		},
+	}
+	update := bson.M{
+		"$inc": bson.M{"used_count": 1},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	var coupon models.Coupon
+	return r.db.FindOneAndUpdate(ctx, "coupons", filter, update, &coupon)
+}
+
+// EnsureIndexes creates the index that keeps coupon codes unique, so that two
+// concurrent creations cannot both take a code.
+func (r *CouponRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, database.DatabaseTimeout)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}
+	if _, err := r.db.GetDB().Collection("coupons").Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("failed to create coupon indexes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/promotions/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IRedemptionRepository interface {
+	CreateRedemption(ctx context.Context, redemption *models.Redemption) error
+	CountRedemptions(ctx context.Context, couponID, customerID primitive.ObjectID) (int64, error)
+}
+
+type RedemptionRepository struct {
+	db database.IDatabase
+}
+
+func NewRedemptionRepository(db database.IDatabase) IRedemptionRepository {
+	return &RedemptionRepository{
+		db: db,
+	}
+}
+
+func (r *RedemptionRepository) CreateRedemption(ctx context.Context, redemption *models.Redemption) error {
+	redemption.ID = primitive.NewObjectID()
+	redemption.CreatedAt = time.Now()
+	if err := r.db.Create(ctx, "coupon_redemptions", redemption); err != nil {
+		return fmt.Errorf("failed to record coupon redemption: %w", err)
+	}
+	return nil
+}
+
+func (r *RedemptionRepository) CountRedemptions(ctx context.Context, couponID, customerID primitive.ObjectID) (int64, error) {
+	filter := bson.M{"coupon_id": couponID, "customer_id": customerID}
+	count, err := r.db.Count(ctx, "coupon_redemptions", filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/promotions/dtos"
+	"github.com/devbenho/luka-platform/internal/promotions/models"
+	"github.com/devbenho/luka-platform/internal/promotions/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IPromotionService interface {
+	CreateCoupon(ctx context.Context, dto *dtos.CreateCouponRequest) (*models.Coupon, error)
+	GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error)
+	ListCoupons(ctx context.Context, page database.PageRequest) ([]models.Coupon, *database.Page, error)
+	DeactivateCoupon(ctx context.Context, id string) error
+	ApplyCoupon(ctx context.Context, code string, customerID primitive.ObjectID, items []models.DiscountableItem) (*models.Discount, error)
+	RedeemCoupon(ctx context.Context, discount *models.Discount, customerID, checkoutID primitive.ObjectID) error
+}
+
+type PromotionService struct {
+	repo           repositories.ICouponRepository
+	redemptionRepo repositories.IRedemptionRepository
+	validator      *validation.Validator
+}
+
+func NewPromotionService(repo repositories.ICouponRepository, redemptionRepo repositories.IRedemptionRepository, validator *validation.Validator) IPromotionService {
+	return &PromotionService{
+		repo:           repo,
+		redemptionRepo: redemptionRepo,
+		validator:      validator,
+	}
+}
+
+func (s *PromotionService) CreateCoupon(ctx context.Context, dto *dtos.CreateCouponRequest) (*models.Coupon, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+	for field, amount := range map[string]*money.Money{"amount": dto.Amount, "min_cart_value": dto.MinCartValue} {
+		if amount == nil {
+			continue
+		}
+		if err := amount.Validate(); err != nil || amount.IsNegative() {
+			return nil, errors.NewError(errors.ValidationErrorType, 400, "invalid "+field, errors.WithField(field))
+		}
+	}
+	if dto.StartsAt != nil && dto.EndsAt != nil && !dto.EndsAt.After(*dto.StartsAt) {
+		return nil, errors.NewError(errors.ValidationErrorType, 400, "ends_at must be after starts_at", errors.WithField("ends_at"))
+	}
+
+	// This check spares the insert in the common case; the unique index on
+	// codes settles concurrent requests.
+	if _, err := s.repo.GetCouponByCode(ctx, dto.Code); err == nil {
+		return nil, errors.NewConflictError("coupon code already exists")
+	}
+
+	coupon, err := s.repo.CreateCoupon(ctx, dto.ToCoupon())
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errors.NewConflictError("coupon code already exists")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "creating coupon")
+	}
+	return coupon, nil
+}
+
+func (s *PromotionService) GetCouponByCode(ctx context.Context, code string) (*models.Coupon, error) {
+	coupon, err := s.repo.GetCouponByCode(ctx, code)
+	if err != nil {
+		return nil, errors.NewNotFoundError("coupon", code)
+	}
+	return coupon, nil
+}
+
+func (s *PromotionService) ListCoupons(ctx context.Context, page database.PageRequest) ([]models.Coupon, *database.Page, error) {
+	if err := s.validator.ValidateStruct(page); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, nil, validationErrors
+		}
+		return nil, nil, err
+	}
+	coupons, result, err := s.repo.ListCoupons(ctx, page)
+	if stdErrors.Is(err, database.ErrInvalidCursor) {
+		return nil, nil, errors.NewBadRequestError("invalid cursor")
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "listing coupons")
+	}
+	return coupons, result, nil
+}
+
+func (s *PromotionService) DeactivateCoupon(ctx context.Context, id string) error {
+	if err := s.repo.DeactivateCoupon(ctx, id); err != nil {
+		return errors.Wrap(err, "deactivating coupon")
+	}
+	return nil
+}
+
+// ApplyCoupon checks that a customer may use a coupon on a cart and computes
+// the discount of every item. It does not count the use; RedeemCoupon does
+// that once the order is placed.
+func (s *PromotionService) ApplyCoupon(ctx context.Context, code string, customerID primitive.ObjectID, items []models.DiscountableItem) (*models.Discount, error) {
+	if len(items) == 0 {
+		return nil, invalidCoupon("the cart is empty")
+	}
+	coupon, err := s.repo.GetCouponByCode(ctx, code)
+	if err != nil {
+		return nil, invalidCoupon("coupon does not exist")
+	}
+	if err := s.checkUsable(ctx, coupon, customerID); err != nil {
+		return nil, err
+	}
+
+	amounts := make([]money.Money, len(items))
+	for i, item := range items {
+		amounts[i] = item.Amount
+	}
+	cartTotal, err := money.Sum(amounts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "totalling cart")
+	}
+	if coupon.MinCartValue != nil {
+		cmp, err := cartTotal.Cmp(*coupon.MinCartValue)
+		if err != nil {
+			return nil, invalidCoupon("coupon is not valid for this currency")
+		}
+		if cmp < 0 {
+			return nil, invalidCoupon("cart value is below the coupon minimum of " + coupon.MinCartValue.String())
+		}
+	}
+
+	var eligible []int
+	var eligibleAmounts []money.Money
+	for i, item := range items {
+		if coupon.AppliesTo(item) {
+			eligible = append(eligible, i)
+			eligibleAmounts = append(eligibleAmounts, item.Amount)
+		}
+	}
+	if len(eligible) == 0 {
+		return nil, invalidCoupon("coupon does not apply to any item in the cart")
+	}
+	eligibleTotal, err := money.Sum(eligibleAmounts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "totalling cart")
+	}
+
+	discount := &models.Discount{
+		CouponID:    coupon.ID,
+		Code:        coupon.Code,
+		Type:        coupon.Type,
+		Amount:      cartTotal.Zero(),
+		ItemAmounts: make([]money.Money, len(items)),
+	}
+	for i, item := range items {
+		discount.ItemAmounts[i] = item.Amount.Zero()
+	}
+
+	switch coupon.Type {
+	case models.DiscountTypePercentage:
+		for _, i := range eligible {
+			amount, err := items[i].Amount.MulFraction(coupon.PercentBasisPoints, 10000)
+			if err != nil {
+				return nil, errors.Wrap(err, "computing discount")
+			}
+			discount.ItemAmounts[i] = amount
+		}
+	case models.DiscountTypeFixedAmount:
+		if coupon.Amount == nil {
+			return nil, invalidCoupon("coupon has no amount")
+		}
+		fixed, err := money.Min(*coupon.Amount, eligibleTotal)
+		if err != nil {
+			return nil, invalidCoupon("coupon is not valid for this currency")
+		}
+		if err := allocate(discount.ItemAmounts, items, eligible, fixed, eligibleTotal); err != nil {
+			return nil, err
+		}
+	case models.DiscountTypeFreeShipping:
+		discount.FreeShipping = true
+		seen := map[primitive.ObjectID]bool{}
+		for _, i := range eligible {
+			if storeID := items[i].StoreID; !seen[storeID] {
+				seen[storeID] = true
+				discount.ShippingStoreIDs = append(discount.ShippingStoreIDs, storeID)
+			}
+		}
+	}
+
+	if discount.Amount, err = money.Sum(discount.ItemAmounts...); err != nil {
+		return nil, errors.Wrap(err, "totalling discount")
+	}
+	return discount, nil
+}
+
+// RedeemCoupon counts a use of the coupon behind a discount and records who
+// used it. It should run in the transaction that places the order, so that a
+// failed order does not use up the coupon.
+func (s *PromotionService) RedeemCoupon(ctx context.Context, discount *models.Discount, customerID, checkoutID primitive.ObjectID) error {
+	coupon, err := s.repo.GetCouponByCode(ctx, discount.Code)
+	if err != nil {
+		return invalidCoupon("coupon does not exist")
+	}
+	if err := s.checkUsable(ctx, coupon, customerID); err != nil {
+		return err
+	}
+
+	err = s.repo.IncrementUsage(ctx, coupon.ID)
+	if err == mongo.ErrNoDocuments {
+		return invalidCoupon("coupon has reached its usage limit")
+	}
+	if err != nil {
+		return errors.Wrap(err, "counting coupon use")
+	}
+
+	redemption := &models.Redemption{
+		CouponID:   coupon.ID,
+		CustomerID: customerID,
+		CheckoutID: checkoutID,
+		Amount:     discount.Amount,
+	}
+	if err := s.redemptionRepo.CreateRedemption(ctx, redemption); err != nil {
+		return errors.Wrap(err, "redeeming coupon")
+	}
+	return nil
+}
+
+func (s *PromotionService) checkUsable(ctx context.Context, coupon *models.Coupon, customerID primitive.ObjectID) error {
+	if !coupon.IsValidAt(time.Now()) {
+		return invalidCoupon("coupon is not active")
+	}
+	if coupon.MaxUses > 0 && coupon.UsedCount >= coupon.MaxUses {
+		return invalidCoupon("coupon has reached its usage limit")
+	}
+	if coupon.MaxUsesPerCustomer > 0 {
+		used, err := s.redemptionRepo.CountRedemptions(ctx, coupon.ID, customerID)
+		if err != nil {
+			return errors.Wrap(err, "checking coupon usage")
+		}
+		if used >= int64(coupon.MaxUsesPerCustomer) {
+			return invalidCoupon("coupon has already been used the maximum number of times")
+		}
+	}
+	return nil
+}
+
+// allocate spreads a fixed discount over the eligible items in proportion to
+// their amounts. The last item takes the rounding remainder so that the item
+// discounts add up to exactly the fixed amount.
+func allocate(result []money.Money, items []models.DiscountableItem, eligible []int, fixed, eligibleTotal money.Money) error {
+	if eligibleTotal.IsZero() {
+		return nil
+	}
+	remaining := fixed
+	for n, i := range eligible {
+		share := remaining
+		var err error
+		if n < len(eligible)-1 {
+			if share, err = fixed.MulFraction(items[i].Amount.Amount, eligibleTotal.Amount); err != nil {
+				return errors.Wrap(err, "allocating discount")
+			}
+		}
+		if remaining, err = remaining.Sub(share); err != nil {
+			return errors.Wrap(err, "allocating discount")
+		}
+		result[i] = share
+	}
+	return nil
+}
+
+func invalidCoupon(message string) error {
+	return errors.NewError(errors.InvalidCoupon, 422, message)
+}
@@ -0,0 +1,21 @@
+package dtos
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type CreateReturnRequest struct {
+	Items  []CreateReturnItemRequest `json:"items" validate:"required,min=1,dive"`
+	Reason string                    `json:"reason" validate:"required,max=500"`
+}
+
+type CreateReturnItemRequest struct {
+	ProductID primitive.ObjectID `json:"product_id" validate:"required"`
+	Quantity  int                `json:"quantity" validate:"required,gt=0"`
+}
+
+type ReviewReturnRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+type ReceiveReturnRequest struct {
+	WarehouseID primitive.ObjectID `json:"warehouse_id" validate:"required"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "REQUESTED"
+	ReturnStatusApproved  ReturnStatus = "APPROVED"
+	ReturnStatusRejected  ReturnStatus = "REJECTED"
+	ReturnStatusReceived  ReturnStatus = "RECEIVED"
+)
+
+type ReturnItem struct {
+	ProductID    primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Quantity     int                `bson:"quantity" json:"quantity"`
+	UnitPrice    money.Money        `bson:"unit_price" json:"unit_price"`
+	TaxAmount    money.Money        `bson:"tax_amount" json:"tax_amount"`
+	RefundAmount money.Money        `bson:"refund_amount" json:"refund_amount"`
+}
+
+// ReturnRequest is a buyer's request to send back some or all items of a
+// delivered order. It moves REQUESTED -> APPROVED -> RECEIVED, or
+// REQUESTED -> REJECTED.
+type ReturnRequest struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	OrderID      primitive.ObjectID  `bson:"order_id" json:"order_id"`
+	CustomerID   primitive.ObjectID  `bson:"customer_id" json:"customer_id"`
+	Items        []ReturnItem        `bson:"items" json:"items"`
+	Reason       string              `bson:"reason" json:"reason"`
+	Status       ReturnStatus        `bson:"status" json:"status"`
+	RefundAmount money.Money         `bson:"refund_amount" json:"refund_amount"`
+	ReviewedBy   primitive.ObjectID  `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	ReviewNote   string              `bson:"review_note,omitempty" json:"review_note,omitempty"`
+	WarehouseID  *primitive.ObjectID `bson:"warehouse_id,omitempty" json:"warehouse_id,omitempty"`
+	ReceivedAt   *time.Time          `bson:"received_at,omitempty" json:"received_at,omitempty"`
+	CreatedAt    time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// IsActive reports whether the request still counts against the order's
+// returnable quantities.
+func (r *ReturnRequest) IsActive() bool {
+	return r.Status != ReturnStatusRejected
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/returns/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IReturnRepository interface {
+	CreateReturn(ctx context.Context, request *models.ReturnRequest) (*models.ReturnRequest, error)
+	GetReturnByID(ctx context.Context, id string) (*models.ReturnRequest, error)
+	ListReturnsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.ReturnRequest, error)
+	UpdateReturn(ctx context.Context, request *models.ReturnRequest, expected models.ReturnStatus) error
+	LockOrderReturns(ctx context.Context, orderID primitive.ObjectID) error
+}
+
+type ReturnRepository struct {
+	db database.IDatabase
+}
+
+func NewReturnRepository(db database.IDatabase) IReturnRepository {
+	return &ReturnRepository{
+		db: db,
+	}
+}
+
+func (r *ReturnRepository) CreateReturn(ctx context.Context, request *models.ReturnRequest) (*models.ReturnRequest, error) {
+	request.ID = primitive.NewObjectID()
+	request.CreatedAt = time.Now()
+	request.UpdatedAt = time.Now()
+	if err := r.db.Create(ctx, "returns", request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (r *ReturnRepository) GetReturnByID(ctx context.Context, id string) (*models.ReturnRequest, error) {
+	var request models.ReturnRequest
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid return ID: %w", err)
+	}
+	filter := bson.M{"_id": objID}
+	if err := r.db.FindOne(ctx, "returns", filter, &request); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("return not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get return from db: %w", err)
+	}
+	return &request, nil
+}
+
+func (r *ReturnRepository) ListReturnsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.ReturnRequest, error) {
+	requests := []models.ReturnRequest{}
+	filter := bson.M{"order_id": orderID}
+	if err := r.db.Find(ctx, "returns", filter, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// UpdateReturn saves the request only if it is still in the expected status,
+// returning mongo.ErrNoDocuments when someone else moved it first.
+func (r *ReturnRepository) UpdateReturn(ctx context.Context, request *models.ReturnRequest, expected models.ReturnStatus) error {
+	request.UpdatedAt = time.Now()
+	filter := bson.M{"_id": request.ID, "status": expected}
+	update := bson.M{"$set": request}
+	var updated models.ReturnRequest
+	return r.db.FindOneAndUpdate(ctx, "returns", filter, update, &updated)
+}
+
+// LockOrderReturns writes the order's lock document. Two transactions opening
+// returns for the same order both write it, so one of them conflicts and is
+// retried after the other committed.
+func (r *ReturnRepository) LockOrderReturns(ctx context.Context, orderID primitive.ObjectID) error {
+	filter := bson.M{"_id": orderID}
+	update := bson.M{"$inc": bson.M{"version": 1}}
+	var lock bson.M
+	if err := r.db.FindOneAndUpsert(ctx, "return_locks", filter, update, &lock); err != nil {
+		return fmt.Errorf("failed to lock returns of order: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	inventoryRepo "github.com/devbenho/luka-platform/internal/inventory/repositories"
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	orderDtos "github.com/devbenho/luka-platform/internal/orders/order_dtos"
+	orderSvc "github.com/devbenho/luka-platform/internal/orders/services"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	"github.com/devbenho/luka-platform/internal/returns/dtos"
+	"github.com/devbenho/luka-platform/internal/returns/models"
+	"github.com/devbenho/luka-platform/internal/returns/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IReturnService interface {
+	CreateReturn(ctx context.Context, orderID string, actor orderModels.Actor, dto dtos.CreateReturnRequest) (*models.ReturnRequest, error)
+	GetReturnByID(ctx context.Context, id string, actor orderModels.Actor) (*models.ReturnRequest, error)
+	ListReturnsByOrder(ctx context.Context, orderID string, actor orderModels.Actor) ([]models.ReturnRequest, error)
+	ApproveReturn(ctx context.Context, id string, actor orderModels.Actor, dto dtos.ReviewReturnRequest) (*models.ReturnRequest, error)
+	RejectReturn(ctx context.Context, id string, actor orderModels.Actor, dto dtos.ReviewReturnRequest) (*models.ReturnRequest, error)
+	ReceiveReturn(ctx context.Context, id string, actor orderModels.Actor, dto dtos.ReceiveReturnRequest) (*models.ReturnRequest, error)
+}
+
+type ReturnService struct {
+	db            database.IDatabase
+	repo          repositories.IReturnRepository
+	inventoryRepo inventoryRepo.IInventoryRepository
+	orderService  orderSvc.IOrderService
+	ownership     ownershipSvc.IOwnershipService
+	validator     *validation.Validator
+}
+
+func NewReturnService(
+	db database.IDatabase,
+	repo repositories.IReturnRepository,
+	inventoryRepo inventoryRepo.IInventoryRepository,
+	orderService orderSvc.IOrderService,
+	ownership ownershipSvc.IOwnershipService,
+	validator *validation.Validator,
+) *ReturnService {
+	return &ReturnService{
+		db:            db,
+		repo:          repo,
+		inventoryRepo: inventoryRepo,
+		orderService:  orderService,
+		ownership:     ownership,
+		validator:     validator,
+	}
+}
+
+// CreateReturn opens a return for items of a delivered order. Each item may
+// only be returned up to the quantity ordered less what earlier, non-rejected
+// returns already claimed.
+func (s *ReturnService) CreateReturn(ctx context.Context, orderID string, actor orderModels.Actor, dto dtos.CreateReturnRequest) (*models.ReturnRequest, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.CustomerID != actor.ID {
+		return nil, errors.NewError(errors.UnauthorizedType, 403, "only the customer who placed the order may return it")
+	}
+	if order.Status != orderModels.OrderStatusDelivered && order.Status != orderModels.OrderStatusPartiallyReturned {
+		return nil, errors.NewConflictError("only delivered orders can be returned")
+	}
+
+	// Claimed quantities are counted and the return inserted in one
+	// transaction that also writes the order's return lock, so that
+	// concurrent requests cannot claim the same units.
+	var created *models.ReturnRequest
+	err = s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		if err := s.repo.LockOrderReturns(sessCtx, order.ID); err != nil {
+			return errors.Wrap(err, "locking order returns")
+		}
+		request, err := s.buildReturn(sessCtx, order, dto)
+		if err != nil {
+			return err
+		}
+		created, err = s.repo.CreateReturn(sessCtx, request)
+		if err != nil {
+			return errors.Wrap(err, "creating return")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// buildReturn prices the requested items against what is still returnable.
+func (s *ReturnService) buildReturn(ctx context.Context, order *orderModels.Order, dto dtos.CreateReturnRequest) (*models.ReturnRequest, error) {
+	returnable, err := s.returnableQuantities(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &models.ReturnRequest{
+		OrderID:    order.ID,
+		CustomerID: order.CustomerID,
+		Reason:     dto.Reason,
+		Status:     models.ReturnStatusRequested,
+	}
+	for _, item := range dto.Items {
+		line, ok := returnable[item.ProductID]
+		if !ok {
+			return nil, errors.NewBadRequestError("product " + item.ProductID.Hex() + " is not part of the order")
+		}
+		if item.Quantity > line.quantity {
+			return nil, errors.NewError(
+				errors.BadRequestType,
+				400,
+				"return quantity exceeds the returnable quantity",
+				errors.WithMetadata(map[string]interface{}{
+					"product_id": item.ProductID.Hex(),
+					"requested":  item.Quantity,
+					"returnable": line.quantity,
+				}),
+			)
+		}
+		// The buyer gets back what they paid for the units: the discounted
+		// price plus, pro rata, any tax that was added on top of it. Shares
+		// are taken of what is still unrefunded, so the units returned last
+		// get the rounding remainder and the returns of an item add up to
+		// exactly what was paid for it.
+		tax, err := line.exclusiveTax.MulFraction(int64(item.Quantity), int64(line.quantity))
+		if err != nil {
+			return nil, errors.Wrap(err, "prorating tax")
+		}
+		paid, err := line.paid.MulFraction(int64(item.Quantity), int64(line.quantity))
+		if err != nil {
+			return nil, errors.Wrap(err, "prorating refund")
+		}
+		refund, err := paid.Add(tax)
+		if err != nil {
+			return nil, errors.Wrap(err, "totalling refund")
+		}
+		line.quantity -= item.Quantity
+		if line.paid, err = line.paid.Sub(paid); err != nil {
+			return nil, errors.Wrap(err, "prorating refund")
+		}
+		if line.exclusiveTax, err = line.exclusiveTax.Sub(tax); err != nil {
+			return nil, errors.Wrap(err, "prorating tax")
+		}
+		returnable[item.ProductID] = line
+
+		request.Items = append(request.Items, models.ReturnItem{
+			ProductID:    item.ProductID,
+			Quantity:     item.Quantity,
+			UnitPrice:    line.unitPrice,
+			TaxAmount:    tax,
+			RefundAmount: refund,
+		})
+		if request.RefundAmount, err = request.RefundAmount.Add(refund); err != nil {
+			return nil, errors.Wrap(err, "totalling refund")
+		}
+	}
+
+	return request, nil
+}
+
+// GetReturnByID returns a return to the customer of its order, the owner of
+// the order's store or a privileged actor.
+func (s *ReturnService) GetReturnByID(ctx context.Context, id string, actor orderModels.Actor) (*models.ReturnRequest, error) {
+	request, err := s.getReturn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.orderService.GetOrder(ctx, request.OrderID.Hex(), actor); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (s *ReturnService) getReturn(ctx context.Context, id string) (*models.ReturnRequest, error) {
+	if id == "" {
+		return nil, errors.NewBadRequestError("return ID is required")
+	}
+	request, err := s.repo.GetReturnByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching return")
+	}
+	return request, nil
+}
+
+// ListReturnsByOrder returns the returns of an order to its customer, the
+// owner of its store or a privileged actor.
+func (s *ReturnService) ListReturnsByOrder(ctx context.Context, orderID string, actor orderModels.Actor) ([]models.ReturnRequest, error) {
+	order, err := s.orderService.GetOrder(ctx, orderID, actor)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.repo.ListReturnsByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing returns")
+	}
+	return requests, nil
+}
+
+func (s *ReturnService) ApproveReturn(ctx context.Context, id string, actor orderModels.Actor, dto dtos.ReviewReturnRequest) (*models.ReturnRequest, error) {
+	return s.review(ctx, id, actor, dto, models.ReturnStatusApproved)
+}
+
+func (s *ReturnService) RejectReturn(ctx context.Context, id string, actor orderModels.Actor, dto dtos.ReviewReturnRequest) (*models.ReturnRequest, error) {
+	return s.review(ctx, id, actor, dto, models.ReturnStatusRejected)
+}
+
+// ReceiveReturn restocks the items of an approved return into the chosen
+// warehouse and moves the order to PARTIALLY_RETURNED or RETURNED, all in one
+// transaction.
+func (s *ReturnService) ReceiveReturn(ctx context.Context, id string, actor orderModels.Actor, dto dtos.ReceiveReturnRequest) (*models.ReturnRequest, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+	var received *models.ReturnRequest
+	err := s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		request, err := s.getReturn(sessCtx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorizeSeller(sessCtx, actor, request); err != nil {
+			return err
+		}
+		if request.Status != models.ReturnStatusApproved {
+			return errors.NewConflictError("only approved returns can be received")
+		}
+
+		for _, item := range request.Items {
+			_, err := s.inventoryRepo.RestockWarehouse(sessCtx, item.ProductID, dto.WarehouseID, item.Quantity)
+			if err == mongo.ErrNoDocuments {
+				return errors.NewBadRequestError("warehouse does not stock product " + item.ProductID.Hex())
+			}
+			if err != nil {
+				return errors.Wrap(err, "restocking returned items")
+			}
+		}
+
+		now := time.Now()
+		warehouseID := dto.WarehouseID
+		request.Status = models.ReturnStatusReceived
+		request.WarehouseID = &warehouseID
+		request.ReceivedAt = &now
+		if err := s.save(sessCtx, request, models.ReturnStatusApproved); err != nil {
+			return err
+		}
+
+		if err := s.updateOrderStatus(sessCtx, request); err != nil {
+			return err
+		}
+		received = request
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "receiving return")
+	}
+	return received, nil
+}
+
+func (s *ReturnService) review(ctx context.Context, id string, actor orderModels.Actor, dto dtos.ReviewReturnRequest, to models.ReturnStatus) (*models.ReturnRequest, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+	request, err := s.getReturn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeSeller(ctx, actor, request); err != nil {
+		return nil, err
+	}
+	if request.Status != models.ReturnStatusRequested {
+		return nil, errors.NewConflictError("return has already been reviewed")
+	}
+
+	request.Status = to
+	request.ReviewedBy = actor.ID
+	request.ReviewNote = dto.Note
+	if err := s.save(ctx, request, models.ReturnStatusRequested); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// save persists a status change, failing with a conflict when another request
+// moved the return out of the expected status first.
+func (s *ReturnService) save(ctx context.Context, request *models.ReturnRequest, expected models.ReturnStatus) error {
+	err := s.repo.UpdateReturn(ctx, request, expected)
+	if err == mongo.ErrNoDocuments {
+		return errors.NewConflictError("return was modified concurrently")
+	}
+	if err != nil {
+		return errors.Wrap(err, "updating return")
+	}
+	return nil
+}
+
+// updateOrderStatus marks the order as fully returned once every ordered unit
+// has been received back, and as partially returned otherwise.
+func (s *ReturnService) updateOrderStatus(ctx context.Context, request *models.ReturnRequest) error {
+	order, err := s.orderService.GetOrderByID(ctx, request.OrderID.Hex())
+	if err != nil {
+		return err
+	}
+	requests, err := s.repo.ListReturnsByOrder(ctx, order.ID)
+	if err != nil {
+		return errors.Wrap(err, "listing returns")
+	}
+
+	received := map[primitive.ObjectID]int{}
+	for _, r := range requests {
+		if r.ID == request.ID {
+			r = *request
+		}
+		if r.Status != models.ReturnStatusReceived {
+			continue
+		}
+		for _, item := range r.Items {
+			received[item.ProductID] += item.Quantity
+		}
+	}
+
+	status := orderModels.OrderStatusReturned
+	for _, item := range order.Items {
+		if received[item.ProductID] < item.Quantity {
+			status = orderModels.OrderStatusPartiallyReturned
+			break
+		}
+	}
+
+	return s.orderService.UpdateOrderStatus(ctx, order.ID.Hex(), orderModels.SystemActor, orderDtos.UpdateOrderStatusRequest{
+		Status: status,
+		Note:   "return " + request.ID.Hex() + " received",
+	})
+}
+
+// returnableLine is what can still be returned of one product: the units and
+// what was paid for them, less what earlier returns refund.
+type returnableLine struct {
+	quantity     int
+	unitPrice    money.Money
+	paid         money.Money
+	exclusiveTax money.Money
+}
+
+// returnableQuantities lists, per product of the order, how many units can
+// still be returned and what is left to refund for them.
+func (s *ReturnService) returnableQuantities(ctx context.Context, order *orderModels.Order) (map[primitive.ObjectID]returnableLine, error) {
+	lines := map[primitive.ObjectID]returnableLine{}
+	for _, item := range order.Items {
+		line := lines[item.ProductID]
+		line.quantity += item.Quantity
+		line.unitPrice = item.UnitPrice
+		price, err := item.DiscountedPrice()
+		if err != nil {
+			return nil, errors.Wrap(err, "totalling price")
+		}
+		if line.paid, err = line.paid.Add(price); err != nil {
+			return nil, errors.Wrap(err, "totalling price")
+		}
+		if !item.TaxInclusive {
+			if line.exclusiveTax, err = line.exclusiveTax.Add(item.TaxAmount); err != nil {
+				return nil, errors.Wrap(err, "totalling tax")
+			}
+		}
+		lines[item.ProductID] = line
+	}
+
+	requests, err := s.repo.ListReturnsByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing returns")
+	}
+	for _, r := range requests {
+		if !r.IsActive() {
+			continue
+		}
+		for _, item := range r.Items {
+			line := lines[item.ProductID]
+			line.quantity -= item.Quantity
+			paid, err := item.RefundAmount.Sub(item.TaxAmount)
+			if err != nil {
+				return nil, errors.Wrap(err, "totalling refunds")
+			}
+			if line.paid, err = line.paid.Sub(paid); err != nil {
+				return nil, errors.Wrap(err, "totalling refunds")
+			}
+			if line.exclusiveTax, err = line.exclusiveTax.Sub(item.TaxAmount); err != nil {
+				return nil, errors.Wrap(err, "totalling refunds")
+			}
+			lines[item.ProductID] = line
+		}
+	}
+	return lines, nil
+}
+
+// authorizeSeller only lets the owner of the store that sold the order, and
+// privileged actors, review or receive its returns.
+func (s *ReturnService) authorizeSeller(ctx context.Context, actor orderModels.Actor, request *models.ReturnRequest) error {
+	if actor.IsPrivileged() {
+		return nil
+	}
+	order, err := s.orderService.GetOrderByID(ctx, request.OrderID.Hex())
+	if err != nil {
+		return err
+	}
+	return s.ownership.AuthorizeSeller(ctx, actor, order.StoreID)
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/internal/returns/dtos"
+	"github.com/devbenho/luka-platform/internal/returns/models"
+	"github.com/devbenho/luka-platform/internal/returns/repositories"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeReturnRepo keeps the returns of a single order.
+type fakeReturnRepo struct {
+	repositories.IReturnRepository
+	requests []models.ReturnRequest
+}
+
+func (r *fakeReturnRepo) ListReturnsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.ReturnRequest, error) {
+	return r.requests, nil
+}
+
+type returned struct {
+	quantity int
+	rejected bool
+}
+
+func TestBuildReturnRefundsExactlyWhatWasPaid(t *testing.T) {
+	usd := func(amount int64) money.Money {
+		m, _ := money.New(amount, "USD")
+		return m
+	}
+
+	tests := []struct {
+		name         string
+		ordered      int
+		paid         int64
+		tax          int64
+		taxInclusive bool
+		returns      []returned
+		wantRefunds  []int64
+		wantTaxes    []int64
+	}{
+		{"one at a time", 3, 11, 0, false, []returned{{1, false}, {1, false}, {1, false}}, []int64{4, 4, 3}, []int64{0, 0, 0}},
+		{"two then one", 3, 10, 0, false, []returned{{2, false}, {1, false}}, []int64{7, 3}, []int64{0, 0}},
+		{"all at once", 3, 11, 0, false, []returned{{3, false}}, []int64{11}, []int64{0}},
+		{"tax added on top", 3, 11, 1, false, []returned{{1, false}, {1, false}, {1, false}}, []int64{4, 5, 3}, []int64{0, 1, 0}},
+		{"tax included in the price", 3, 11, 2, true, []returned{{1, false}, {1, false}, {1, false}}, []int64{4, 4, 3}, []int64{0, 0, 0}},
+		{"rejected returns refund nothing", 3, 11, 0, false, []returned{{2, true}, {1, false}, {2, false}}, []int64{7, 4, 7}, []int64{0, 0, 0}},
+		{"many units", 7, 100, 5, false, []returned{{1, false}, {2, false}, {1, false}, {3, false}}, []int64{15, 30, 15, 45}, []int64{1, 1, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productID := primitive.NewObjectID()
+			order := &orderModels.Order{
+				ID: primitive.NewObjectID(),
+				Items: []orderModels.OrderItem{{
+					ProductID:      productID,
+					Quantity:       tt.ordered,
+					TotalPrice:     usd(tt.paid),
+					DiscountAmount: usd(0),
+					TaxInclusive:   tt.taxInclusive,
+					TaxAmount:      usd(tt.tax),
+				}},
+			}
+			repo := &fakeReturnRepo{}
+			service := &ReturnService{repo: repo}
+
+			var refunds, taxes []int64
+			var refunded, taxed int64
+			for _, r := range tt.returns {
+				request, err := service.buildReturn(context.Background(), order, dtos.CreateReturnRequest{
+					Items: []dtos.CreateReturnItemRequest{{ProductID: productID, Quantity: r.quantity}},
+				})
+				if err != nil {
+					t.Fatalf("buildReturn() = %v", err)
+				}
+				if r.rejected {
+					request.Status = models.ReturnStatusRejected
+				} else {
+					refunded += request.RefundAmount.Amount
+					taxed += request.Items[0].TaxAmount.Amount
+				}
+				repo.requests = append(repo.requests, *request)
+				refunds = append(refunds, request.RefundAmount.Amount)
+				taxes = append(taxes, request.Items[0].TaxAmount.Amount)
+			}
+			if !reflect.DeepEqual(refunds, tt.wantRefunds) || !reflect.DeepEqual(taxes, tt.wantTaxes) {
+				t.Errorf("refunds %v with tax %v, want %v with tax %v", refunds, taxes, tt.wantRefunds, tt.wantTaxes)
+			}
+			// Tax included in the price is refunded as part of it.
+			wantTax := tt.tax
+			if tt.taxInclusive {
+				wantTax = 0
+			}
+			if refunded != tt.paid+wantTax || taxed != wantTax {
+				t.Errorf("returns refund %d with %d of tax, want %d with %d", refunded, taxed, tt.paid+wantTax, wantTax)
+			}
+		})
+	}
+}
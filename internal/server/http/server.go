@@ -1,20 +1,41 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 
 	config "github.com/devbenho/luka-platform/configs"
+	cartRepo "github.com/devbenho/luka-platform/internal/cart/repositories"
+	inventorySvc "github.com/devbenho/luka-platform/internal/inventory/services"
+	orderRepo "github.com/devbenho/luka-platform/internal/orders/repositories"
+	paymentRepo "github.com/devbenho/luka-platform/internal/payments/repositories"
+	paymentSvc "github.com/devbenho/luka-platform/internal/payments/services"
+	promoRepo "github.com/devbenho/luka-platform/internal/promotions/repositories"
+	userRepo "github.com/devbenho/luka-platform/internal/user/repositories"
 	"github.com/devbenho/luka-platform/internal/utils"
 	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/tokens"
 	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/devbenho/luka-platform/ports/http/analytics"
+	"github.com/devbenho/luka-platform/ports/http/cart"
 	"github.com/devbenho/luka-platform/ports/http/categories"
 	"github.com/devbenho/luka-platform/ports/http/inventories"
+	"github.com/devbenho/luka-platform/ports/http/invoices"
 	"github.com/devbenho/luka-platform/ports/http/orders"
+	"github.com/devbenho/luka-platform/ports/http/payments"
 	"github.com/devbenho/luka-platform/ports/http/products"
+	"github.com/devbenho/luka-platform/ports/http/promotions"
+	"github.com/devbenho/luka-platform/ports/http/returns"
+	"github.com/devbenho/luka-platform/ports/http/shipments"
+	"github.com/devbenho/luka-platform/ports/http/shipping"
 	"github.com/devbenho/luka-platform/ports/http/stores"
+	"github.com/devbenho/luka-platform/ports/http/taxes"
 	"github.com/devbenho/luka-platform/ports/http/users"
+	"github.com/devbenho/luka-platform/ports/http/warehouses"
+	"github.com/devbenho/luka-platform/ports/http/wellknown"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -27,18 +48,28 @@ type Server struct {
 	validator *validation.Validator
 	db        database.IDatabase
 	logger    *zap.Logger
+	tokens    *tokens.TokenService
+	shared    services
 }
 
 func NewServer(validator *validation.Validator, db database.IDatabase, logger *zap.Logger) Server {
 	cfg, _ := config.LoadConfig()
 	router := gin.Default()
 
+	tokenService, err := tokens.NewTokenService(*cfg)
+	if err != nil {
+		log.Fatalf("Creating token service: %v", err)
+	}
+	tokens.SetDefault(tokenService)
+
 	return Server{
 		engine:    router,
 		cfg:       cfg,
 		validator: validator,
 		db:        db,
 		logger:    logger,
+		tokens:    tokenService,
+		shared:    newServices(db, validator, *cfg),
 	}
 }
 
@@ -59,6 +90,12 @@ func (s Server) Run() error {
 		log.Fatalf("MapRoutes Error: %v", err)
 	}
 
+	if err := s.EnsureIndexes(context.Background()); err != nil {
+		s.logger.Error("creating database indexes", zap.Error(err))
+	}
+
+	s.StartWorkers(context.Background())
+
 	s.engine.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, utils.NewSuccessResponse(http.StatusOK, "pong", nil))
 	})
@@ -75,12 +112,55 @@ func (s Server) GetEngine() *gin.Engine {
 }
 
 func (s Server) MapRoutes() error {
+	middleware.UseTokenRevocations(userRepo.NewTokenRepository(s.db))
+	wellknown.Routes(s.engine.Group("/.well-known"), s.tokens)
+
+	shared := s.shared
+
 	v1 := s.engine.Group("/api/v1")
-	users.Routes(v1, s.db, s.validator, *s.cfg)
+	users.Routes(v1, s.db, s.validator, *s.cfg, shared.inventory)
 	stores.Routes(v1, s.db, s.validator, *s.cfg)
 	categories.Routes(v1, s.db, s.validator, *s.cfg)
 	products.Routes(v1, s.db, s.validator, *s.cfg)
 	inventories.Routes(v1, s.db, s.validator, *s.cfg)
-	orders.Routes(v1, s.db, s.validator, *s.cfg)
+	warehouses.Routes(v1, s.db, s.validator, *s.cfg)
+	orders.Routes(v1, s.db, s.validator, *s.cfg, shared.orders)
+	returns.Routes(v1, s.db, s.validator, *s.cfg, shared.orders, shared.ownership)
+	taxes.Routes(v1, s.db, s.validator, *s.cfg)
+	promotions.Routes(v1, s.db, s.validator, *s.cfg)
+	cart.Routes(v1, s.db, s.validator, *s.cfg, shared.products, shared.inventory, shared.orders)
+	shipping.Routes(v1, s.db, s.validator, *s.cfg, shared.ownership)
+	shipments.Routes(v1, s.db, s.validator, *s.cfg, shared.orders, shared.ownership)
+	payments.Routes(v1, s.db, s.validator, *s.cfg, shared.orders, shared.ownership, shared.paymentProvider)
+	invoices.Routes(v1, s.db, s.validator, *s.cfg, shared.ownership)
+	analytics.Routes(v1, s.db, s.validator, *s.cfg)
 	return nil
 }
+
+// EnsureIndexes creates the database indexes the repositories query through.
+// Creating an index that already exists is a no-op.
+func (s Server) EnsureIndexes(ctx context.Context) error {
+	if err := orderRepo.NewOrderRepository(s.db).EnsureIndexes(ctx); err != nil {
+		return err
+	}
+	if err := cartRepo.NewCartRepository(s.db).EnsureIndexes(ctx); err != nil {
+		return err
+	}
+	if err := middleware.EnsureIdempotencyIndexes(ctx, s.db); err != nil {
+		return err
+	}
+	if err := paymentRepo.NewPaymentRepository(s.db).EnsureIndexes(ctx); err != nil {
+		return err
+	}
+	if err := promoRepo.NewCouponRepository(s.db).EnsureIndexes(ctx); err != nil {
+		return err
+	}
+	return userRepo.NewTokenRepository(s.db).EnsureIndexes(ctx)
+}
+
+// StartWorkers launches the background jobs that run alongside the HTTP server.
+func (s Server) StartWorkers(ctx context.Context) {
+	go inventorySvc.NewReservationSweeper(s.shared.reservations, s.cfg.Reservations.SweepInterval, s.logger).Run(ctx)
+	go paymentSvc.NewReversalSweeper(s.shared.paymentReversals, s.cfg.Payments.RefundClaimTimeout, s.cfg.Payments.ReversalInterval, s.logger).Run(ctx)
+	go tokens.NewKeyRotator(s.tokens, s.logger).Run(ctx)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"log"
+
+	config "github.com/devbenho/luka-platform/configs"
+	inventoryRepo "github.com/devbenho/luka-platform/internal/inventory/repositories"
+	inventorySvc "github.com/devbenho/luka-platform/internal/inventory/services"
+	invoiceRepo "github.com/devbenho/luka-platform/internal/invoices/repositories"
+	invoiceSvc "github.com/devbenho/luka-platform/internal/invoices/services"
+	orderRepo "github.com/devbenho/luka-platform/internal/orders/repositories"
+	orderSvc "github.com/devbenho/luka-platform/internal/orders/services"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	paymentProviders "github.com/devbenho/luka-platform/internal/payments/providers"
+	paymentRepo "github.com/devbenho/luka-platform/internal/payments/repositories"
+	paymentSvc "github.com/devbenho/luka-platform/internal/payments/services"
+	productRepo "github.com/devbenho/luka-platform/internal/product/repositories"
+	productSvc "github.com/devbenho/luka-platform/internal/product/services"
+	promoRepo "github.com/devbenho/luka-platform/internal/promotions/repositories"
+	promoSvc "github.com/devbenho/luka-platform/internal/promotions/services"
+	shipmentRepo "github.com/devbenho/luka-platform/internal/shipments/repositories"
+	shippingRepo "github.com/devbenho/luka-platform/internal/shipping/repositories"
+	shippingSvc "github.com/devbenho/luka-platform/internal/shipping/services"
+	storeRepo "github.com/devbenho/luka-platform/internal/store/repositories"
+	taxRepo "github.com/devbenho/luka-platform/internal/tax/repositories"
+	taxSvc "github.com/devbenho/luka-platform/internal/tax/services"
+	warehouseRepo "github.com/devbenho/luka-platform/internal/warehouse/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/validation"
+)
+
+// services are the services several route groups share. The order service in
+// particular depends on most of the platform, so it is built once here and
+// handed to the routes that need it.
+type services struct {
+	ownership ownershipSvc.IOwnershipService
+	inventory inventorySvc.IInventoryService
+	products  productSvc.IProductService
+	orders    orderSvc.IOrderService
+	// Cancelling an order gives its payments back through the same gateway
+	// the payment routes use.
+	paymentProvider paymentProviders.IPaymentProvider
+	// The background workers release expired holds and retry reversals with
+	// the same services the routes use.
+	reservations     *inventorySvc.ReservationService
+	paymentReversals *paymentSvc.ReversalService
+}
+
+func newServices(db database.IDatabase, validator *validation.Validator, cfg config.Config) services {
+	// Initialize repositories
+	orderRepository := orderRepo.NewOrderRepository(db)
+	orderHistoryRepository := orderRepo.NewOrderHistoryRepository(db)
+	checkoutRepository := orderRepo.NewCheckoutRepository(db)
+	inventoryRepository := inventoryRepo.NewInventoryRepository(db)
+	reservationRepository := inventoryRepo.NewReservationRepository(db)
+	productRepository := productRepo.NewProductRepository(db)
+	storeRepository := storeRepo.NewStoreRepository(db)
+	taxRuleRepository := taxRepo.NewTaxRuleRepository(db)
+	couponRepository := promoRepo.NewCouponRepository(db)
+	redemptionRepository := promoRepo.NewRedemptionRepository(db)
+	shippingMethodRepository := shippingRepo.NewShippingMethodRepository(db)
+	warehouseRepository := warehouseRepo.NewWarehouseRepository(db)
+	shipmentRepository := shipmentRepo.NewShipmentRepository(db)
+	paymentRepository := paymentRepo.NewPaymentRepository(db)
+	invoiceRepository := invoiceRepo.NewInvoiceRepository(db)
+
+	// Initialize services
+	paymentProvider, err := paymentProviders.New(cfg.Payments.Provider)
+	if err != nil {
+		log.Fatalf("Payment provider: %v", err)
+	}
+	ownershipService := ownershipSvc.NewOwnershipService(storeRepository, productRepository, warehouseRepository)
+	inventoryService := inventorySvc.NewInventoryService(inventoryRepository, ownershipService, validator)
+	reservationService := inventorySvc.NewReservationService(db, reservationRepository, inventoryRepository)
+	productService := productSvc.NewProductService(productRepository, ownershipService, validator)
+	taxCalculator := taxSvc.NewRuleBasedCalculator(taxRuleRepository)
+	promotionService := promoSvc.NewPromotionService(couponRepository, redemptionRepository, validator)
+	shippingService := shippingSvc.NewShippingService(shippingMethodRepository, ownershipService, validator)
+	invoiceService := invoiceSvc.NewInvoiceService(db, invoiceRepository, orderRepository, storeRepository, productRepository, ownershipService)
+	paymentReversalService := paymentSvc.NewReversalService(paymentRepository, paymentProvider)
+	orderStateMachine := orderSvc.NewOrderStateMachine(reservationService, paymentRepository, paymentReversalService, shipmentRepository, invoiceService, orderSvc.NewLogNotifier(), ownershipService)
+	orderService := orderSvc.NewOrderService(db, orderRepository, orderHistoryRepository, checkoutRepository, inventoryService, reservationService, productService, taxCalculator, promotionService, shippingService, warehouseRepository, ownershipService, validator, orderStateMachine, cfg.Reservations.TTL)
+
+	return services{
+		ownership:        ownershipService,
+		inventory:        inventoryService,
+		products:         productService,
+		orders:           orderService,
+		paymentProvider:  paymentProvider,
+		reservations:     reservationService,
+		paymentReversals: paymentReversalService,
+	}
+}
@@ -0,0 +1,35 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/shipments/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShipOrderRequest lists the packages an order leaves in. Together they must
+// hold every unit of the order.
+type ShipOrderRequest struct {
+	Packages []PackageRequest `json:"packages" validate:"required,min=1,dive"`
+	Note     string           `json:"note" validate:"max=500"`
+}
+
+type PackageRequest struct {
+	WarehouseID    primitive.ObjectID   `json:"warehouse_id" validate:"required"`
+	Carrier        string               `json:"carrier" validate:"required,max=100"`
+	TrackingNumber string               `json:"tracking_number" validate:"required,max=100"`
+	Items          []PackageItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type PackageItemRequest struct {
+	ProductID primitive.ObjectID `json:"product_id" validate:"required"`
+	Quantity  int                `json:"quantity" validate:"required,gt=0"`
+}
+
+type AddTrackingEventRequest struct {
+	Status      models.ShipmentStatus `json:"status" validate:"required,oneof=IN_TRANSIT OUT_FOR_DELIVERY DELIVERED EXCEPTION"`
+	Description string                `json:"description" validate:"max=500"`
+	Location    string                `json:"location" validate:"max=200"`
+	// OccurredAt is when the carrier recorded the event; it defaults to now.
+	OccurredAt *time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ShipmentStatus string
+
+const (
+	ShipmentStatusShipped        ShipmentStatus = "SHIPPED"
+	ShipmentStatusInTransit      ShipmentStatus = "IN_TRANSIT"
+	ShipmentStatusOutForDelivery ShipmentStatus = "OUT_FOR_DELIVERY"
+	ShipmentStatusDelivered      ShipmentStatus = "DELIVERED"
+	ShipmentStatusException      ShipmentStatus = "EXCEPTION"
+)
+
+type ShipmentItem struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+}
+
+// TrackingEvent is a scan or status update reported by the carrier.
+type TrackingEvent struct {
+	Status      ShipmentStatus `bson:"status" json:"status"`
+	Description string         `bson:"description,omitempty" json:"description,omitempty"`
+	Location    string         `bson:"location,omitempty" json:"location,omitempty"`
+	OccurredAt  time.Time      `bson:"occurred_at" json:"occurred_at"`
+	RecordedAt  time.Time      `bson:"recorded_at" json:"recorded_at"`
+}
+
+// Shipment is one package of an order, sent from a single warehouse. An
+// order may ship in several packages; it is delivered once all of them are.
+type Shipment struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrderID        primitive.ObjectID `bson:"order_id" json:"order_id"`
+	StoreID        primitive.ObjectID `bson:"store_id" json:"store_id"`
+	WarehouseID    primitive.ObjectID `bson:"warehouse_id" json:"warehouse_id"`
+	Carrier        string             `bson:"carrier" json:"carrier"`
+	TrackingNumber string             `bson:"tracking_number" json:"tracking_number"`
+	Items          []ShipmentItem     `bson:"items" json:"items"`
+	Status         ShipmentStatus     `bson:"status" json:"status"`
+	Events         []TrackingEvent    `bson:"events" json:"events"`
+	ShippedBy      primitive.ObjectID `bson:"shipped_by,omitempty" json:"shipped_by,omitempty"`
+	ShippedAt      time.Time          `bson:"shipped_at" json:"shipped_at"`
+	DeliveredAt    *time.Time         `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+func (s *Shipment) IsDelivered() bool {
+	return s.Status == ShipmentStatusDelivered
+}
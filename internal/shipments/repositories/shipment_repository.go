@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/shipments/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IShipmentRepository interface {
+	CreateShipment(ctx context.Context, shipment *models.Shipment) (*models.Shipment, error)
+	GetShipmentByID(ctx context.Context, id string) (*models.Shipment, error)
+	ListShipmentsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.Shipment, error)
+	CountShipments(ctx context.Context, orderID primitive.ObjectID) (int64, error)
+	AddTrackingEvent(ctx context.Context, id primitive.ObjectID, event models.TrackingEvent) (*models.Shipment, error)
+}
+
+type ShipmentRepository struct {
+	db database.IDatabase
+}
+
+func NewShipmentRepository(db database.IDatabase) IShipmentRepository {
+	return &ShipmentRepository{
+		db: db,
+	}
+}
+
+func (r *ShipmentRepository) CreateShipment(ctx context.Context, shipment *models.Shipment) (*models.Shipment, error) {
+	shipment.ID = primitive.NewObjectID()
+	shipment.CreatedAt = time.Now()
+	shipment.UpdatedAt = time.Now()
+	if err := r.db.Create(ctx, "shipments", shipment); err != nil {
+		return nil, fmt.Errorf("failed to create shipment in db: %w", err)
+	}
+	return shipment, nil
+}
+
+func (r *ShipmentRepository) GetShipmentByID(ctx context.Context, id string) (*models.Shipment, error) {
+	var shipment models.Shipment
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shipment ID: %w", err)
+	}
+	filter := bson.M{"_id": objID}
+	if err := r.db.FindOne(ctx, "shipments", filter, &shipment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("shipment not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get shipment from db: %w", err)
+	}
+	return &shipment, nil
+}
+
+func (r *ShipmentRepository) ListShipmentsByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.Shipment, error) {
+	shipments := []models.Shipment{}
+	filter := bson.M{"order_id": orderID}
+	if err := r.db.Find(ctx, "shipments", filter, &shipments); err != nil {
+		return nil, fmt.Errorf("failed to list shipments from db: %w", err)
+	}
+	return shipments, nil
+}
+
+func (r *ShipmentRepository) CountShipments(ctx context.Context, orderID primitive.ObjectID) (int64, error) {
+	count, err := r.db.Count(ctx, "shipments", bson.M{"order_id": orderID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count shipments: %w", err)
+	}
+	return count, nil
+}
+
+// AddTrackingEvent appends an event and moves the shipment to its status. It
+// returns mongo.ErrNoDocuments when the shipment has already been delivered.
+func (r *ShipmentRepository) AddTrackingEvent(ctx context.Context, id primitive.ObjectID, event models.TrackingEvent) (*models.Shipment, error) {
+	set := bson.M{
+		"status":     event.Status,
+		"updated_at": time.Now(),
+	}
+	if event.Status == models.ShipmentStatusDelivered {
+		set["delivered_at"] = event.OccurredAt
+	}
+	filter := bson.M{"_id": id, "status": bson.M{"$ne": models.ShipmentStatusDelivered}}
+	update := bson.M{
+		"$push": bson.M{"events": event},
+		"$set":  set,
+	}
+	var updated models.Shipment
+	if err := r.db.FindOneAndUpdate(ctx, "shipments", filter, update, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
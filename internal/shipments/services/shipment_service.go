@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	orderDtos "github.com/devbenho/luka-platform/internal/orders/order_dtos"
+	orderSvc "github.com/devbenho/luka-platform/internal/orders/services"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	"github.com/devbenho/luka-platform/internal/shipments/dtos"
+	"github.com/devbenho/luka-platform/internal/shipments/models"
+	"github.com/devbenho/luka-platform/internal/shipments/repositories"
+	warehouseRepo "github.com/devbenho/luka-platform/internal/warehouse/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IShipmentService interface {
+	ShipOrder(ctx context.Context, orderID string, actor orderModels.Actor, dto dtos.ShipOrderRequest) ([]models.Shipment, error)
+	GetShipmentByID(ctx context.Context, id string, actor orderModels.Actor) (*models.Shipment, error)
+	ListShipmentsByOrder(ctx context.Context, orderID string, actor orderModels.Actor) ([]models.Shipment, error)
+	AddTrackingEvent(ctx context.Context, id string, actor orderModels.Actor, dto dtos.AddTrackingEventRequest) (*models.Shipment, error)
+}
+
+type ShipmentService struct {
+	db            database.IDatabase
+	repo          repositories.IShipmentRepository
+	warehouseRepo warehouseRepo.IWarehouseRepository
+	orderService  orderSvc.IOrderService
+	ownership     ownershipSvc.IOwnershipService
+	validator     *validation.Validator
+}
+
+func NewShipmentService(
+	db database.IDatabase,
+	repo repositories.IShipmentRepository,
+	warehouseRepo warehouseRepo.IWarehouseRepository,
+	orderService orderSvc.IOrderService,
+	ownership ownershipSvc.IOwnershipService,
+	validator *validation.Validator,
+) *ShipmentService {
+	return &ShipmentService{
+		db:            db,
+		repo:          repo,
+		warehouseRepo: warehouseRepo,
+		orderService:  orderService,
+		ownership:     ownership,
+		validator:     validator,
+	}
+}
+
+// ShipOrder records the packages a processed order leaves in and moves the
+// order to SHIPPED in the same transaction. Only the owner of the order's
+// store may ship it. The packages must hold exactly
+// the units that were ordered, each taken from a warehouse of the store.
+func (s *ShipmentService) ShipOrder(ctx context.Context, orderID string, actor orderModels.Actor, dto dtos.ShipOrderRequest) ([]models.Shipment, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+	var shipped []models.Shipment
+	err := s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		shipped = nil
+		order, err := s.orderService.GetOrderByID(sessCtx, orderID)
+		if err != nil {
+			return err
+		}
+		if err := s.ownership.AuthorizeSeller(sessCtx, actor, order.StoreID); err != nil {
+			return err
+		}
+		if order.Status != orderModels.OrderStatusProcessing {
+			return errors.NewConflictError("only orders being processed can be shipped")
+		}
+		if err := s.checkPackages(sessCtx, order, dto.Packages); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, pkg := range dto.Packages {
+			shipment := &models.Shipment{
+				OrderID:        order.ID,
+				StoreID:        order.StoreID,
+				WarehouseID:    pkg.WarehouseID,
+				Carrier:        pkg.Carrier,
+				TrackingNumber: pkg.TrackingNumber,
+				Status:         models.ShipmentStatusShipped,
+				Events: []models.TrackingEvent{{
+					Status:      models.ShipmentStatusShipped,
+					Description: "handed over to " + pkg.Carrier,
+					OccurredAt:  now,
+					RecordedAt:  now,
+				}},
+				ShippedBy: actor.ID,
+				ShippedAt: now,
+			}
+			for _, item := range pkg.Items {
+				shipment.Items = append(shipment.Items, models.ShipmentItem{ProductID: item.ProductID, Quantity: item.Quantity})
+			}
+			created, err := s.repo.CreateShipment(sessCtx, shipment)
+			if err != nil {
+				return errors.Wrap(err, "creating shipment")
+			}
+			shipped = append(shipped, *created)
+		}
+
+		return s.orderService.UpdateOrderStatus(sessCtx, orderID, actor, orderDtos.UpdateOrderStatusRequest{
+			Status: orderModels.OrderStatusShipped,
+			Note:   dto.Note,
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "shipping order")
+	}
+	return shipped, nil
+}
+
+// GetShipmentByID returns a shipment to the customer of its order, the owner
+// of the order's store or a privileged actor.
+func (s *ShipmentService) GetShipmentByID(ctx context.Context, id string, actor orderModels.Actor) (*models.Shipment, error) {
+	shipment, err := s.getShipment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.orderService.GetOrder(ctx, shipment.OrderID.Hex(), actor); err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+
+func (s *ShipmentService) getShipment(ctx context.Context, id string) (*models.Shipment, error) {
+	if id == "" {
+		return nil, errors.NewBadRequestError("shipment ID is required")
+	}
+	shipment, err := s.repo.GetShipmentByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching shipment")
+	}
+	return shipment, nil
+}
+
+// ListShipmentsByOrder returns the shipments of an order to its customer, the
+// owner of its store or a privileged actor.
+func (s *ShipmentService) ListShipmentsByOrder(ctx context.Context, orderID string, actor orderModels.Actor) ([]models.Shipment, error) {
+	order, err := s.orderService.GetOrder(ctx, orderID, actor)
+	if err != nil {
+		return nil, err
+	}
+	shipments, err := s.repo.ListShipmentsByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing shipments")
+	}
+	return shipments, nil
+}
+
+// AddTrackingEvent appends a carrier event to a shipment of a store the actor
+// owns. When it delivers the last outstanding package of an order, the order
+// moves to DELIVERED.
+func (s *ShipmentService) AddTrackingEvent(ctx context.Context, id string, actor orderModels.Actor, dto dtos.AddTrackingEventRequest) (*models.Shipment, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+	event := models.TrackingEvent{
+		Status:      dto.Status,
+		Description: dto.Description,
+		Location:    dto.Location,
+		OccurredAt:  time.Now(),
+		RecordedAt:  time.Now(),
+	}
+	if dto.OccurredAt != nil {
+		event.OccurredAt = *dto.OccurredAt
+	}
+
+	var updated *models.Shipment
+	err := s.db.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		shipment, err := s.getShipment(sessCtx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.ownership.AuthorizeSeller(sessCtx, actor, shipment.StoreID); err != nil {
+			return err
+		}
+		updated, err = s.repo.AddTrackingEvent(sessCtx, shipment.ID, event)
+		if err == mongo.ErrNoDocuments {
+			return errors.NewConflictError("shipment has already been delivered")
+		}
+		if err != nil {
+			return errors.Wrap(err, "adding tracking event")
+		}
+		if !updated.IsDelivered() {
+			return nil
+		}
+		return s.deliverOrder(sessCtx, updated.OrderID)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "tracking shipment")
+	}
+	return updated, nil
+}
+
+// deliverOrder marks a shipped order as delivered once all of its packages
+// have arrived.
+func (s *ShipmentService) deliverOrder(ctx context.Context, orderID primitive.ObjectID) error {
+	shipments, err := s.repo.ListShipmentsByOrder(ctx, orderID)
+	if err != nil {
+		return errors.Wrap(err, "listing shipments")
+	}
+	for _, shipment := range shipments {
+		if !shipment.IsDelivered() {
+			return nil
+		}
+	}
+
+	order, err := s.orderService.GetOrderByID(ctx, orderID.Hex())
+	if err != nil {
+		return err
+	}
+	if order.Status != orderModels.OrderStatusShipped {
+		return nil
+	}
+	return s.orderService.UpdateOrderStatus(ctx, orderID.Hex(), orderModels.SystemActor, orderDtos.UpdateOrderStatusRequest{
+		Status: orderModels.OrderStatusDelivered,
+		Note:   "all shipments delivered",
+	})
+}
+
+// checkPackages makes sure the packages ship every ordered unit exactly once
+// and only leave from warehouses of the order's store.
+func (s *ShipmentService) checkPackages(ctx context.Context, order *orderModels.Order, packages []dtos.PackageRequest) error {
+	remaining := map[primitive.ObjectID]int{}
+	for _, item := range order.Items {
+		remaining[item.ProductID] += item.Quantity
+	}
+
+	warehouses := map[primitive.ObjectID]bool{}
+	for _, pkg := range packages {
+		if !warehouses[pkg.WarehouseID] {
+			warehouse, err := s.warehouseRepo.GetWarehouseByID(ctx, pkg.WarehouseID)
+			if err != nil {
+				return errors.NewNotFoundError("warehouse", pkg.WarehouseID.Hex())
+			}
+			if !order.StoreID.IsZero() && warehouse.StoreID != order.StoreID {
+				return errors.NewBadRequestError("warehouse " + pkg.WarehouseID.Hex() + " does not belong to the order's store")
+			}
+			warehouses[pkg.WarehouseID] = true
+		}
+
+		for _, item := range pkg.Items {
+			left, ok := remaining[item.ProductID]
+			if !ok {
+				return errors.NewBadRequestError("product " + item.ProductID.Hex() + " is not part of the order")
+			}
+			remaining[item.ProductID] = left - item.Quantity
+		}
+	}
+
+	for productID, left := range remaining {
+		if left != 0 {
+			return errors.NewError(
+				errors.BadRequestType,
+				400,
+				"packages must contain exactly the ordered quantities",
+				errors.WithMetadata(map[string]interface{}{
+					"product_id": productID.Hex(),
+					"unshipped":  left,
+				}),
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package dtos
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/devbenho/luka-platform/internal/shipping/models"
+	"github.com/devbenho/luka-platform/pkg/money"
+)
+
+type CreateShippingMethodRequest struct {
+	Name      string            `json:"name" validate:"required,max=100"`
+	Type      models.RateType   `json:"type" validate:"required,oneof=FLAT WEIGHT DISTANCE"`
+	BaseCost  money.Money       `json:"base_cost"`
+	Tiers     []models.RateTier `json:"tiers" validate:"required_unless=Type FLAT,dive"`
+	FreeAbove *money.Money      `json:"free_above"`
+	Countries []string          `json:"countries" validate:"dive,iso3166_1_alpha2"`
+}
+
+func (r *CreateShippingMethodRequest) ToShippingMethod() *models.ShippingMethod {
+	tiers := append([]models.RateTier(nil), r.Tiers...)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].UpTo < tiers[j].UpTo })
+
+	countries := make([]string, len(r.Countries))
+	for i, country := range r.Countries {
+		countries[i] = strings.ToUpper(country)
+	}
+
+	return &models.ShippingMethod{
+		Name:      r.Name,
+		Type:      r.Type,
+		BaseCost:  r.BaseCost,
+		Tiers:     tiers,
+		FreeAbove: r.FreeAbove,
+		Countries: countries,
+		Active:    true,
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+// Address is a delivery address. Its location fields match the warehouse
+// Location so distances can be measured between the two; coordinates are
+// only needed for distance-based shipping methods.
+type Address struct {
+	Recipient  string  `bson:"recipient" json:"recipient" validate:"required"`
+	Phone      string  `bson:"phone,omitempty" json:"phone,omitempty"`
+	Address    string  `bson:"address" json:"address" validate:"required"`
+	City       string  `bson:"city" json:"city" validate:"required"`
+	Region     string  `bson:"region,omitempty" json:"region,omitempty" validate:"max=100"`
+	Country    string  `bson:"country" json:"country" validate:"required,iso3166_1_alpha2"`
+	PostalCode string  `bson:"postal_code" json:"postal_code"`
+	Latitude   float64 `bson:"latitude,omitempty" json:"latitude,omitempty" validate:"latitude"`
+	Longitude  float64 `bson:"longitude,omitempty" json:"longitude,omitempty" validate:"longitude"`
+}
+
+// HasCoordinates reports whether the address was geolocated.
+func (a Address) HasCoordinates() bool {
+	return a.Latitude != 0 || a.Longitude != 0
+}
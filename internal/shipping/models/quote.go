@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Origin is a warehouse a shipment leaves from.
+type Origin struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Shipment describes what a store has to deliver for one order.
+type Shipment struct {
+	StoreID     primitive.ObjectID
+	Destination Address
+	Origins     []Origin
+	WeightGrams int64
+	Subtotal    money.Money
+}
+
+// Quote is the price of delivering a shipment with a shipping method.
+type Quote struct {
+	MethodID   primitive.ObjectID `bson:"methodID" json:"method_id"`
+	MethodName string             `bson:"methodName" json:"method_name"`
+	Type       RateType           `bson:"type" json:"type"`
+	Cost       money.Money        `bson:"cost" json:"cost"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RateType string
+
+const (
+	// RateTypeFlat charges BaseCost for every shipment.
+	RateTypeFlat RateType = "FLAT"
+	// RateTypeWeight looks the total weight of the shipment, in kilograms,
+	// up in the rate table.
+	RateTypeWeight RateType = "WEIGHT"
+	// RateTypeDistance looks the distance between the shipping warehouse and
+	// the destination, in kilometres, up in the rate table.
+	RateTypeDistance RateType = "DISTANCE"
+)
+
+// RateTier charges Cost on top of the method's BaseCost for shipments up to
+// UpTo kilograms or kilometres.
+type RateTier struct {
+	UpTo float64     `bson:"up_to" json:"up_to" validate:"gt=0"`
+	Cost money.Money `bson:"cost" json:"cost"`
+}
+
+// ShippingMethod is a way a store ships its orders, such as standard or
+// express delivery, with the rates it charges.
+type ShippingMethod struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StoreID   primitive.ObjectID `bson:"store_id" json:"store_id"`
+	Name      string             `bson:"name" json:"name"`
+	Type      RateType           `bson:"type" json:"type"`
+	BaseCost  money.Money        `bson:"base_cost" json:"base_cost"`
+	Tiers     []RateTier         `bson:"tiers,omitempty" json:"tiers,omitempty"`
+	FreeAbove *money.Money       `bson:"free_above,omitempty" json:"free_above,omitempty"`
+	// Countries limits the method to destinations in these countries; empty
+	// means anywhere.
+	Countries []string   `bson:"countries,omitempty" json:"countries,omitempty"`
+	Active    bool       `bson:"active" json:"active"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `bson:"updated_at" json:"updated_at"`
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+}
+
+// ShipsTo reports whether the method delivers to a country.
+func (m *ShippingMethod) ShipsTo(country string) bool {
+	if len(m.Countries) == 0 {
+		return true
+	}
+	for _, c := range m.Countries {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
+
+// TierFor returns the first tier covering a weight or distance. Tiers are kept
+// sorted by UpTo.
+func (m *ShippingMethod) TierFor(value float64) (RateTier, bool) {
+	for _, tier := range m.Tiers {
+		if value <= tier.UpTo {
+			return tier, true
+		}
+	}
+	return RateTier{}, false
+}
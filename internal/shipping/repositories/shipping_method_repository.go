@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/shipping/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IShippingMethodRepository interface {
+	CreateMethod(ctx context.Context, method *models.ShippingMethod) (*models.ShippingMethod, error)
+	GetMethodByID(ctx context.Context, id primitive.ObjectID) (*models.ShippingMethod, error)
+	ListMethodsByStore(ctx context.Context, storeID primitive.ObjectID) ([]models.ShippingMethod, error)
+	DeleteMethod(ctx context.Context, id primitive.ObjectID) error
+}
+
+type ShippingMethodRepository struct {
+	db database.IDatabase
+}
+
+func NewShippingMethodRepository(db database.IDatabase) IShippingMethodRepository {
+	return &ShippingMethodRepository{
+		db: db,
+	}
+}
+
+func (r *ShippingMethodRepository) CreateMethod(ctx context.Context, method *models.ShippingMethod) (*models.ShippingMethod, error) {
+	method.ID = primitive.NewObjectID()
+	method.CreatedAt = time.Now()
+	method.UpdatedAt = time.Now()
+	if err := r.db.Create(ctx, "shipping_methods", method); err != nil {
+		return nil, fmt.Errorf("failed to create shipping method in db: %w", err)
+	}
+	return method, nil
+}
+
+// GetMethodByID returns mongo.ErrNoDocuments when the method does not exist
+// or was deleted.
+func (r *ShippingMethodRepository) GetMethodByID(ctx context.Context, id primitive.ObjectID) (*models.ShippingMethod, error) {
+	var method models.ShippingMethod
+	filter := bson.M{"_id": id, "deleted_at": nil}
+	if err := r.db.FindOne(ctx, "shipping_methods", filter, &method); err != nil {
+		return nil, err
+	}
+	return &method, nil
+}
+
+func (r *ShippingMethodRepository) ListMethodsByStore(ctx context.Context, storeID primitive.ObjectID) ([]models.ShippingMethod, error) {
+	methods := []models.ShippingMethod{}
+	filter := bson.M{"store_id": storeID, "deleted_at": nil}
+	if err := r.db.Find(ctx, "shipping_methods", filter, &methods); err != nil {
+		return nil, fmt.Errorf("failed to list shipping methods from db: %w", err)
+	}
+	return methods, nil
+}
+
+func (r *ShippingMethodRepository) DeleteMethod(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id}
+	return r.db.SoftDelete(ctx, "shipping_methods", filter)
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	"github.com/devbenho/luka-platform/internal/shipping/dtos"
+	"github.com/devbenho/luka-platform/internal/shipping/models"
+	"github.com/devbenho/luka-platform/internal/shipping/repositories"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/money"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IShippingService interface {
+	CreateMethod(ctx context.Context, storeID string, dto *dtos.CreateShippingMethodRequest) (*models.ShippingMethod, error)
+	ListMethods(ctx context.Context, storeID string) ([]models.ShippingMethod, error)
+	DeleteMethod(ctx context.Context, id string) error
+	// Quote prices a shipment with the cheapest of the store's methods that
+	// can deliver it. When the buyer picked one of the store's methods among
+	// preferred, only that one is considered. It returns nil when the store
+	// has no shipping methods at all.
+	Quote(ctx context.Context, shipment models.Shipment, preferred []primitive.ObjectID) (*models.Quote, error)
+}
+
+type ShippingService struct {
+	repo      repositories.IShippingMethodRepository
+	ownership ownershipSvc.IOwnershipService
+	validator *validation.Validator
+}
+
+func NewShippingService(repo repositories.IShippingMethodRepository, ownership ownershipSvc.IOwnershipService, validator *validation.Validator) IShippingService {
+	return &ShippingService{
+		repo:      repo,
+		ownership: ownership,
+		validator: validator,
+	}
+}
+
+// CreateMethod adds a shipping method to a store the caller owns.
+func (s *ShippingService) CreateMethod(ctx context.Context, storeID string, dto *dtos.CreateShippingMethodRequest) (*models.ShippingMethod, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+	storeObjID, err := primitive.ObjectIDFromHex(storeID)
+	if err != nil {
+		return nil, errors.NewBadRequestError("invalid store ID")
+	}
+	if _, err := s.ownership.AuthorizeStore(ctx, storeObjID); err != nil {
+		return nil, err
+	}
+
+	amounts := []money.Money{dto.BaseCost}
+	for _, tier := range dto.Tiers {
+		amounts = append(amounts, tier.Cost)
+	}
+	if dto.FreeAbove != nil {
+		amounts = append(amounts, *dto.FreeAbove)
+	}
+	for _, amount := range amounts {
+		if amount.Validate() != nil || amount.IsNegative() || !amount.SameCurrency(dto.BaseCost) {
+			return nil, errors.NewError(errors.ValidationErrorType, 400, "rates must be non-negative amounts in one currency", errors.WithField("base_cost"))
+		}
+	}
+
+	method := dto.ToShippingMethod()
+	method.StoreID = storeObjID
+	created, err := s.repo.CreateMethod(ctx, method)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating shipping method")
+	}
+	return created, nil
+}
+
+func (s *ShippingService) ListMethods(ctx context.Context, storeID string) ([]models.ShippingMethod, error) {
+	storeObjID, err := primitive.ObjectIDFromHex(storeID)
+	if err != nil {
+		return nil, errors.NewBadRequestError("invalid store ID")
+	}
+	methods, err := s.repo.ListMethodsByStore(ctx, storeObjID)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing shipping methods")
+	}
+	return methods, nil
+}
+
+// DeleteMethod removes a shipping method from a store the caller owns.
+func (s *ShippingService) DeleteMethod(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.NewBadRequestError("invalid shipping method ID")
+	}
+	method, err := s.repo.GetMethodByID(ctx, objID)
+	if err == mongo.ErrNoDocuments {
+		return errors.NewNotFoundError("shipping method", id)
+	}
+	if err != nil {
+		return errors.Wrap(err, "fetching shipping method")
+	}
+	if _, err := s.ownership.AuthorizeStore(ctx, method.StoreID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteMethod(ctx, objID); err != nil {
+		return errors.Wrap(err, "deleting shipping method")
+	}
+	return nil
+}
+
+func (s *ShippingService) Quote(ctx context.Context, shipment models.Shipment, preferred []primitive.ObjectID) (*models.Quote, error) {
+	methods, err := s.repo.ListMethodsByStore(ctx, shipment.StoreID)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing shipping methods")
+	}
+	if len(methods) == 0 {
+		return nil, nil
+	}
+
+	chosen := map[primitive.ObjectID]bool{}
+	for _, method := range methods {
+		for _, id := range preferred {
+			if method.ID == id {
+				chosen[method.ID] = true
+			}
+		}
+	}
+
+	var best *models.Quote
+	for i := range methods {
+		method := &methods[i]
+		if len(chosen) > 0 && !chosen[method.ID] {
+			continue
+		}
+		quote, ok := price(method, shipment)
+		if !ok {
+			continue
+		}
+		if best == nil {
+			best = quote
+		} else if cmp, err := quote.Cost.Cmp(best.Cost); err == nil && cmp < 0 {
+			best = quote
+		}
+	}
+
+	if best == nil {
+		return nil, errors.NewError(
+			errors.BadRequestType,
+			422,
+			"no shipping method of the store delivers this order",
+			errors.WithMetadata(map[string]interface{}{
+				"store_id": shipment.StoreID,
+			}),
+		)
+	}
+	return best, nil
+}
+
+// price computes what a method charges for a shipment. It reports false when
+// the method cannot carry it: inactive, wrong country or currency, or beyond
+// the last tier of its rate table.
+func price(method *models.ShippingMethod, shipment models.Shipment) (*models.Quote, bool) {
+	if !method.Active || !method.ShipsTo(shipment.Destination.Country) {
+		return nil, false
+	}
+	if !shipment.Subtotal.IsZero() && !method.BaseCost.SameCurrency(shipment.Subtotal) {
+		return nil, false
+	}
+
+	cost := method.BaseCost
+	switch method.Type {
+	case models.RateTypeWeight:
+		tier, ok := method.TierFor(float64(shipment.WeightGrams) / 1000)
+		if !ok {
+			return nil, false
+		}
+		var err error
+		if cost, err = cost.Add(tier.Cost); err != nil {
+			return nil, false
+		}
+	case models.RateTypeDistance:
+		if !shipment.Destination.HasCoordinates() || len(shipment.Origins) == 0 {
+			return nil, false
+		}
+		tier, ok := method.TierFor(farthest(shipment.Origins, shipment.Destination))
+		if !ok {
+			return nil, false
+		}
+		var err error
+		if cost, err = cost.Add(tier.Cost); err != nil {
+			return nil, false
+		}
+	}
+
+	if method.FreeAbove != nil {
+		if cmp, err := shipment.Subtotal.Cmp(*method.FreeAbove); err == nil && cmp >= 0 {
+			cost = cost.Zero()
+		}
+	}
+
+	return &models.Quote{
+		MethodID:   method.ID,
+		MethodName: method.Name,
+		Type:       method.Type,
+		Cost:       cost,
+	}, true
+}
+
+// farthest returns the distance in kilometres from the destination to the
+// farthest warehouse the shipment leaves from.
+func farthest(origins []models.Origin, destination models.Address) float64 {
+	var max float64
+	for _, origin := range origins {
+		if d := haversine(origin.Latitude, origin.Longitude, destination.Latitude, destination.Longitude); d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// haversine returns the great-circle distance in kilometres between two
+// points given in degrees.
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
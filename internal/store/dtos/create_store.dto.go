@@ -7,9 +7,10 @@ import (
 )
 
 type CreateStoreRequest struct {
-	Name             string             `json:"name" validate:"required"`
-	Slug             string             `json:"slug" validate:"required"`
-	OwnerId          primitive.ObjectID `json:"ownerId" validate:"required"`
+	Name string `json:"name" validate:"required"`
+	Slug string `json:"slug" validate:"required"`
+	// OwnerId is the user creating the store, never taken from the request.
+	OwnerId          primitive.ObjectID `json:"-" validate:"required"`
 	Location         models.Location    `json:"location"`
 	StoreType        models.StoreType   `json:"store_type"`
 	SocialMediaLinks map[string]string  `json:"social_media_links"`
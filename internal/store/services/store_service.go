@@ -3,11 +3,14 @@ package services
 import (
 	"context"
 
+	ownership "github.com/devbenho/luka-platform/internal/ownership/services"
 	dtos "github.com/devbenho/luka-platform/internal/store/dtos"
 	"github.com/devbenho/luka-platform/internal/store/models"
 	"github.com/devbenho/luka-platform/internal/store/repositories"
 	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/principal"
 	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type IStoreService interface {
@@ -19,17 +22,26 @@ type IStoreService interface {
 
 type StoreService struct {
 	repo      repositories.IStoreRepository
+	ownership ownership.IOwnershipService
 	validator *validation.Validator
 }
 
-func NewStoreService(repository repositories.IStoreRepository, validator *validation.Validator) IStoreService {
+func NewStoreService(repository repositories.IStoreRepository, ownership ownership.IOwnershipService, validator *validation.Validator) IStoreService {
 	return &StoreService{
 		repo:      repository,
+		ownership: ownership,
 		validator: validator,
 	}
 }
 
+// CreateStore creates a store owned by the principal of ctx.
 func (s *StoreService) CreateStore(ctx context.Context, store *dtos.CreateStoreRequest) (*dtos.CreateStoreResponse, error) {
+	p, ok := principal.FromContext(ctx)
+	if !ok {
+		return nil, errors.NewUnauthorizedError("user not authenticated")
+	}
+	store.OwnerId = p.UserID
+
 	if err := s.validator.ValidateStruct(store); err != nil {
 		if validationErrors, ok := err.(errors.ValidationErrors); ok {
 			return nil, validationErrors
@@ -66,17 +78,9 @@ func (s *StoreService) UpdateStore(ctx context.Context, id string, store *dtos.U
 		return nil, errors.Wrap(err, "validating store update")
 	}
 
-	existingStore, err := s.repo.GetStoreByID(ctx, id)
+	existingStore, err := s.authorizeStore(ctx, id)
 	if err != nil {
-		return nil, errors.Wrap(err, "fetching existing store")
-	}
-	if existingStore.DeletedAt != nil {
-		return nil, errors.NewNotFoundError("store", id)
-	}
-
-	userID, ok := ctx.Value("user_id").(string)
-	if !ok || userID != existingStore.OwnerId.String() {
-		return nil, errors.NewUnauthorizedError("not authorized to update this store")
+		return nil, err
 	}
 
 	updatedStore := store.ToStore()
@@ -91,12 +95,8 @@ func (s *StoreService) UpdateStore(ctx context.Context, id string, store *dtos.U
 }
 
 func (s *StoreService) DeleteStore(ctx context.Context, id string) error {
-	store, err := s.repo.GetStoreByID(ctx, id)
-	if err != nil {
-		return errors.Wrap(err, "fetching store")
-	}
-	if store.DeletedAt != nil {
-		return errors.NewNotFoundError("store", id)
+	if _, err := s.authorizeStore(ctx, id); err != nil {
+		return err
 	}
 
 	if err := s.repo.DeleteStore(ctx, id); err != nil {
@@ -104,3 +104,12 @@ func (s *StoreService) DeleteStore(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+// authorizeStore returns the store when the principal of ctx owns it.
+func (s *StoreService) authorizeStore(ctx context.Context, id string) (*models.Store, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.NewBadRequestError("invalid store ID")
+	}
+	return s.ownership.AuthorizeStore(ctx, objID)
+}
@@ -0,0 +1,28 @@
+package dtos
+
+import (
+	"strings"
+
+	"github.com/devbenho/luka-platform/internal/tax/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CreateTaxRuleRequest struct {
+	Name            string              `json:"name" validate:"required,max=100"`
+	Country         string              `json:"country" validate:"required,iso3166_1_alpha2"`
+	Region          string              `json:"region" validate:"max=100"`
+	CategoryID      *primitive.ObjectID `json:"category_id" validate:"omitempty"`
+	RateBasisPoints int64               `json:"rate_basis_points" validate:"gte=0,lte=10000"`
+	Inclusive       bool                `json:"inclusive"`
+}
+
+func (r *CreateTaxRuleRequest) ToTaxRule() *models.TaxRule {
+	return &models.TaxRule{
+		Name:            r.Name,
+		Country:         strings.ToUpper(r.Country),
+		Region:          r.Region,
+		CategoryID:      r.CategoryID,
+		RateBasisPoints: r.RateBasisPoints,
+		Inclusive:       r.Inclusive,
+	}
+}
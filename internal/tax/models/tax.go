@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/devbenho/luka-platform/pkg/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Location is the destination that decides which tax rules apply.
+type Location struct {
+	Country string
+	Region  string
+}
+
+// TaxableItem is an order line to be taxed. Amount is the line total at the
+// listed price.
+type TaxableItem struct {
+	ProductID  primitive.ObjectID
+	Categories []*primitive.ObjectID
+	Amount     money.Money
+}
+
+// ItemTax is the tax computed for one TaxableItem. For inclusive rules the
+// tax is part of the item's amount, otherwise it comes on top of it.
+type ItemTax struct {
+	ProductID       primitive.ObjectID
+	Rule            string
+	RateBasisPoints int64
+	Inclusive       bool
+	Amount          money.Money
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TaxRule is a tax rate that applies to goods shipped to a country, optionally
+// narrowed to a region within it and to products of one category. Rates are
+// in basis points: 1450 is 14.5%.
+type TaxRule struct {
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name            string              `bson:"name" json:"name"`
+	Country         string              `bson:"country" json:"country"`
+	Region          string              `bson:"region,omitempty" json:"region,omitempty"`
+	CategoryID      *primitive.ObjectID `bson:"category_id,omitempty" json:"category_id,omitempty"`
+	RateBasisPoints int64               `bson:"rate_basis_points" json:"rate_basis_points"`
+	// Inclusive is set where listed prices already include the tax, as is
+	// usual for VAT. Otherwise the tax is added on top of the price.
+	Inclusive bool      `bson:"inclusive" json:"inclusive"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Matches reports whether the rule applies to a destination and a product in
+// the given categories.
+func (r *TaxRule) Matches(location Location, categories []*primitive.ObjectID) bool {
+	if !strings.EqualFold(r.Country, location.Country) {
+		return false
+	}
+	if r.Region != "" && !strings.EqualFold(r.Region, location.Region) {
+		return false
+	}
+	if r.CategoryID == nil {
+		return true
+	}
+	for _, category := range categories {
+		if category != nil && *category == *r.CategoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// Specificity ranks matching rules so that category overrides win over
+// regional rates, which in turn win over country-wide rates.
+func (r *TaxRule) Specificity() int {
+	specificity := 0
+	if r.CategoryID != nil {
+		specificity += 2
+	}
+	if r.Region != "" {
+		specificity++
+	}
+	return specificity
+}
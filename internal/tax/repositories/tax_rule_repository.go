@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/tax/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ITaxRuleRepository interface {
+	CreateRule(ctx context.Context, rule *models.TaxRule) (*models.TaxRule, error)
+	ListRules(ctx context.Context, page database.PageRequest) ([]models.TaxRule, *database.Page, error)
+	ListRulesByCountry(ctx context.Context, country string) ([]models.TaxRule, error)
+	DeleteRule(ctx context.Context, id string) error
+}
+
+type TaxRuleRepository struct {
+	db database.IDatabase
+}
+
+func NewTaxRuleRepository(db database.IDatabase) ITaxRuleRepository {
+	return &TaxRuleRepository{
+		db: db,
+	}
+}
+
+func (r *TaxRuleRepository) CreateRule(ctx context.Context, rule *models.TaxRule) (*models.TaxRule, error) {
+	rule.ID = primitive.NewObjectID()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+	if err := r.db.Create(ctx, "tax_rules", rule); err != nil {
+		return nil, fmt.Errorf("failed to create tax rule in db: %w", err)
+	}
+	return rule, nil
+}
+
+func (r *TaxRuleRepository) ListRules(ctx context.Context, page database.PageRequest) ([]models.TaxRule, *database.Page, error) {
+	rules := []models.TaxRule{}
+	result, err := r.db.FindPage(ctx, "tax_rules", database.Query{
+		Sort: bson.D{{Key: "created_at", Value: -1}},
+		Page: page,
+	}, &rules)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list tax rules from db: %w", err)
+	}
+	return rules, result, nil
+}
+
+func (r *TaxRuleRepository) ListRulesByCountry(ctx context.Context, country string) ([]models.TaxRule, error) {
+	rules := []models.TaxRule{}
+	filter := bson.M{"country": strings.ToUpper(country)}
+	if err := r.db.Find(ctx, "tax_rules", filter, &rules); err != nil {
+		return nil, fmt.Errorf("failed to list tax rules from db: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *TaxRuleRepository) DeleteRule(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid tax rule ID: %w", err)
+	}
+	filter := bson.M{"_id": objID}
+	if err := r.db.Delete(ctx, "tax_rules", filter); err != nil {
+		return fmt.Errorf("failed to delete tax rule from db: %w", err)
+	}
+	return nil
+}
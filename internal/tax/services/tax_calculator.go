@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+
+	"github.com/devbenho/luka-platform/internal/tax/models"
+	"github.com/devbenho/luka-platform/internal/tax/repositories"
+	"github.com/devbenho/luka-platform/pkg/errors"
+)
+
+// ITaxCalculator computes the tax owed on order lines shipped to a location.
+// It returns one ItemTax per item, in the order the items were given.
+type ITaxCalculator interface {
+	Calculate(ctx context.Context, location models.Location, items []models.TaxableItem) ([]models.ItemTax, error)
+}
+
+// RuleBasedCalculator taxes items using the rules stored for the destination
+// country. The most specific matching rule wins; items no rule matches are
+// not taxed.
+type RuleBasedCalculator struct {
+	repo repositories.ITaxRuleRepository
+}
+
+func NewRuleBasedCalculator(repo repositories.ITaxRuleRepository) ITaxCalculator {
+	return &RuleBasedCalculator{
+		repo: repo,
+	}
+}
+
+func (c *RuleBasedCalculator) Calculate(ctx context.Context, location models.Location, items []models.TaxableItem) ([]models.ItemTax, error) {
+	rules, err := c.repo.ListRulesByCountry(ctx, location.Country)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading tax rules")
+	}
+
+	taxes := make([]models.ItemTax, len(items))
+	for i, item := range items {
+		taxes[i] = models.ItemTax{ProductID: item.ProductID, Amount: item.Amount.Zero()}
+
+		rule := selectRule(rules, location, item)
+		if rule == nil {
+			continue
+		}
+		taxes[i].Rule = rule.Name
+		taxes[i].RateBasisPoints = rule.RateBasisPoints
+		taxes[i].Inclusive = rule.Inclusive
+		if rule.Inclusive {
+			// The price already holds the tax: extract it from the gross amount.
+			taxes[i].Amount, err = item.Amount.MulFraction(rule.RateBasisPoints, 10000+rule.RateBasisPoints)
+		} else {
+			taxes[i].Amount, err = item.Amount.MulFraction(rule.RateBasisPoints, 10000)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "applying tax rule %s", rule.Name)
+		}
+	}
+	return taxes, nil
+}
+
+func selectRule(rules []models.TaxRule, location models.Location, item models.TaxableItem) *models.TaxRule {
+	var selected *models.TaxRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(location, item.Categories) {
+			continue
+		}
+		if selected == nil || rule.Specificity() > selected.Specificity() {
+			selected = rule
+		}
+	}
+	return selected
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+
+	"github.com/devbenho/luka-platform/internal/tax/dtos"
+	"github.com/devbenho/luka-platform/internal/tax/models"
+	"github.com/devbenho/luka-platform/internal/tax/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/validation"
+)
+
+type ITaxRuleService interface {
+	CreateRule(ctx context.Context, dto *dtos.CreateTaxRuleRequest) (*models.TaxRule, error)
+	ListRules(ctx context.Context, page database.PageRequest) ([]models.TaxRule, *database.Page, error)
+	DeleteRule(ctx context.Context, id string) error
+}
+
+type TaxRuleService struct {
+	repo      repositories.ITaxRuleRepository
+	validator *validation.Validator
+}
+
+func NewTaxRuleService(repo repositories.ITaxRuleRepository, validator *validation.Validator) ITaxRuleService {
+	return &TaxRuleService{
+		repo:      repo,
+		validator: validator,
+	}
+}
+
+func (s *TaxRuleService) CreateRule(ctx context.Context, dto *dtos.CreateTaxRuleRequest) (*models.TaxRule, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+
+	rule, err := s.repo.CreateRule(ctx, dto.ToTaxRule())
+	if err != nil {
+		return nil, errors.Wrap(err, "creating tax rule")
+	}
+	return rule, nil
+}
+
+func (s *TaxRuleService) ListRules(ctx context.Context, page database.PageRequest) ([]models.TaxRule, *database.Page, error) {
+	if err := s.validator.ValidateStruct(page); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, nil, validationErrors
+		}
+		return nil, nil, err
+	}
+	rules, result, err := s.repo.ListRules(ctx, page)
+	if stdErrors.Is(err, database.ErrInvalidCursor) {
+		return nil, nil, errors.NewBadRequestError("invalid cursor")
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "listing tax rules")
+	}
+	return rules, result, nil
+}
+
+func (s *TaxRuleService) DeleteRule(ctx context.Context, id string) error {
+	if err := s.repo.DeleteRule(ctx, id); err != nil {
+		return errors.Wrap(err, "deleting tax rule")
+	}
+	return nil
+}
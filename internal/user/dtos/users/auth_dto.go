@@ -1,11 +1,33 @@
 package dtos
 
+import "time"
+
 type AuthDTO struct {
 	Login    string `json:"login" validate:"required"` // Can be either username or email
 	Password string `json:"password" validate:"required,min=6"`
+	// CartToken is the guest cart to merge into the user's cart on sign-in.
+	CartToken string `json:"cart_token"`
 }
 
 type AuthResponseDTO struct {
 	Email string `json:"email"`
-	Token string `json:"token"`
+	TokenPairDTO
+}
+
+// TokenPairDTO is a short-lived access token and the refresh token that
+// replaces it. A refresh token can be exchanged only once.
+type TokenPairDTO struct {
+	Token          string    `json:"token"`
+	TokenExpiresAt time.Time `json:"token_expires_at"`
+	RefreshToken   string    `json:"refresh_token"`
+}
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutDTO optionally names the refresh token to revoke along with the
+// access token the request is made with.
+type LogoutDTO struct {
+	RefreshToken string `json:"refresh_token"`
 }
@@ -35,8 +35,8 @@ func (c *CreateUserRequest) ToUser() *models.User {
 }
 
 type CreateUserResponse struct {
-	ID    string `json:"id"`
-	Token string `json:"token"`
+	ID string `json:"id"`
+	TokenPairDTO
 }
 
 type GetUserResponse struct {
@@ -46,7 +46,8 @@ type GetUserResponse struct {
 type UpdateUserRequest struct {
 	Username *string `json:"username,omitempty" validate:"omitempty,min=3,max=20"`
 	Email    *string `json:"email,omitempty" validate:"omitempty,email"`
-	Role     *string `json:"role,omitempty" validate:"omitempty,oneof=buyer seller supplier"`
+	// Role can only be changed by admins.
+	Role *string `json:"role,omitempty" validate:"omitempty,oneof=buyer seller supplier admin"`
 }
 
 func (u *UpdateUserRequest) ToUser() *models.User {
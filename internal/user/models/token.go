@@ -1,5 +1,38 @@
 package models
 
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 type Token struct {
 	Token string `json:"token"`
 }
+
+// RefreshToken is a stored refresh token. Only a hash of the token is kept.
+// Every refresh replaces the token with a new one of the same family, so a
+// family traces one sign-in; presenting a token that was already used means
+// it leaked, and the whole family is revoked.
+type RefreshToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	FamilyID  primitive.ObjectID `bson:"family_id"`
+	TokenHash string             `bson:"token_hash"`
+	// AccessTokenID and AccessExpiresAt identify the access token issued
+	// alongside, which is revoked with the family.
+	AccessTokenID   string     `bson:"access_token_id"`
+	AccessExpiresAt time.Time  `bson:"access_expires_at"`
+	UsedAt          *time.Time `bson:"used_at"`
+	RevokedAt       *time.Time `bson:"revoked_at"`
+	CreatedAt       time.Time  `bson:"created_at"`
+	ExpiresAt       time.Time  `bson:"expires_at"`
+}
+
+// RevokedToken denies an access token, by its ID, until it expires.
+type RevokedToken struct {
+	ID        string             `bson:"_id"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	RevokedAt time.Time          `bson:"revoked_at"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+}
@@ -13,7 +13,7 @@ type User struct {
 	Username  string             `bson:"username" validate:"required,min=3,max=20"`
 	Email     string             `bson:"email" validate:"required,email"`
 	Password  string             `bson:"password" validate:"required,min=6"`
-	Role      string             `bson:"role" validate:"required,oneof=buyer seller supplier user"`
+	Role      string             `bson:"role" validate:"required,oneof=buyer seller supplier admin"`
 	CreatedAt time.Time          `bson:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at"`
 	DeletedAt *time.Time         `bson:"deleted_at"`
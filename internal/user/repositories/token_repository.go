@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/user/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	refreshTokenCollection = "refresh_tokens"
+	revokedTokenCollection = "revoked_tokens"
+)
+
+type ITokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id primitive.ObjectID) (bool, error)
+	RevokeFamily(ctx context.Context, familyID primitive.ObjectID) error
+	RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error
+	IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type TokenRepository struct {
+	db database.IDatabase
+}
+
+func NewTokenRepository(db database.IDatabase) ITokenRepository {
+	return &TokenRepository{
+		db: db,
+	}
+}
+
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	token.ID = primitive.NewObjectID()
+	if err := r.db.Create(ctx, refreshTokenCollection, token); err != nil {
+		return fmt.Errorf("failed to create refresh token in db: %w", err)
+	}
+	return nil
+}
+
+func (r *TokenRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	filter := bson.M{"token_hash": tokenHash}
+	if err := r.db.FindOne(ctx, refreshTokenCollection, filter, &token); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("refresh token not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get refresh token from db: %w", err)
+	}
+	return &token, nil
+}
+
+// MarkRefreshTokenUsed records that the token was exchanged, reporting false
+// when it already had been. Of two concurrent refreshes with the same token
+// only one succeeds.
+func (r *TokenRepository) MarkRefreshTokenUsed(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	var token models.RefreshToken
+	filter := bson.M{"_id": id, "used_at": nil}
+	update := bson.M{"$set": bson.M{"used_at": time.Now()}}
+	if err := r.db.FindOneAndUpdate(ctx, refreshTokenCollection, filter, update, &token); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to mark refresh token used: %w", err)
+	}
+	return true, nil
+}
+
+// RevokeFamily revokes every refresh token of the family along with the
+// access tokens issued with them that have not expired yet.
+func (r *TokenRepository) RevokeFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	now := time.Now()
+	filter := bson.M{"family_id": familyID, "revoked_at": nil}
+	if err := r.db.UpdateMany(ctx, refreshTokenCollection, filter, bson.M{"$set": bson.M{"revoked_at": now}}); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	var family []models.RefreshToken
+	filter = bson.M{"family_id": familyID, "access_expires_at": bson.M{"$gt": now}}
+	if err := r.db.Find(ctx, refreshTokenCollection, filter, &family); err != nil {
+		return fmt.Errorf("failed to list refresh token family: %w", err)
+	}
+	for _, token := range family {
+		revoked := &models.RevokedToken{
+			ID:        token.AccessTokenID,
+			UserID:    token.UserID,
+			RevokedAt: now,
+			ExpiresAt: token.AccessExpiresAt,
+		}
+		if err := r.RevokeAccessToken(ctx, revoked); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeAccessToken denies the access token until it expires. Revoking a
+// token twice is not an error.
+func (r *TokenRepository) RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error {
+	if err := r.db.Create(ctx, revokedTokenCollection, token); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	return nil
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	count, err := r.db.Count(ctx, revokedTokenCollection, bson.M{"_id": tokenID})
+	if err != nil {
+		return false, fmt.Errorf("failed to look up revoked access token: %w", err)
+	}
+	return count > 0, nil
+}
+
+// EnsureIndexes creates the indexes refresh tokens are looked up by. Both
+// collections expire their documents once the tokens would have expired.
+func (r *TokenRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, database.DatabaseTimeout)
+	defer cancel()
+
+	refreshIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "family_id", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}
+	if _, err := r.db.GetDB().Collection(refreshTokenCollection).Indexes().CreateMany(ctx, refreshIndexes); err != nil {
+		return fmt.Errorf("failed to create refresh token indexes: %w", err)
+	}
+
+	revokedIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	if _, err := r.db.GetDB().Collection(revokedTokenCollection).Indexes().CreateOne(ctx, revokedIndex); err != nil {
+		return fmt.Errorf("failed to create revoked token index: %w", err)
+	}
+	return nil
+}
@@ -152,6 +152,9 @@ func (r *userRepository) GetUserByUsername(ctx context.Context, username string)
 
 	err := r.db.FindOne(ctx, "users", filter, user)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return user, nil
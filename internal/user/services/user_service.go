@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	stdErrors "errors"
+	"log"
 	"net/http"
+	"time"
 
 	dtos "github.com/devbenho/luka-platform/internal/user/dtos/users"
 	"github.com/devbenho/luka-platform/internal/user/models"
@@ -10,13 +13,19 @@ import (
 	"github.com/devbenho/luka-platform/internal/utils"
 	"github.com/devbenho/luka-platform/pkg/errors"
 	"github.com/devbenho/luka-platform/pkg/hasher"
+	"github.com/devbenho/luka-platform/pkg/principal"
 	"github.com/devbenho/luka-platform/pkg/tokens"
 	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type IUserService interface {
 	Register(ctx context.Context, dto *dtos.CreateUserRequest) (*dtos.CreateUserResponse, error)
 	Login(ctx context.Context, dto *dtos.AuthDTO) (*dtos.AuthResponseDTO, error)
+	Refresh(ctx context.Context, dto *dtos.RefreshTokenDTO) (*dtos.TokenPairDTO, error)
+	Logout(ctx context.Context, claims tokens.Claims, dto *dtos.LogoutDTO) error
 	GetUserByID(ctx context.Context, id string) (*dtos.UserResponseDTO, error)
 	UpdateUser(ctx context.Context, id string, user *dtos.UpdateUserRequest) (*dtos.UserResponseDTO, error)
 	DeleteUser(ctx context.Context, id string) error
@@ -25,24 +34,35 @@ type IUserService interface {
 	FindUserByUsername(ctx context.Context, username string) (*models.User, error)
 }
 
+// CartMerger moves a guest cart into the cart of a user who just signed in.
+type CartMerger interface {
+	MergeGuestCart(ctx context.Context, userID primitive.ObjectID, guestToken string) error
+}
+
 type UserService struct {
-	validator validation.Validator
-	repo      repositories.IUserRepository
-	token     tokens.TokenService
-	hasher    hasher.Hasher
+	validator  validation.Validator
+	repo       repositories.IUserRepository
+	tokenRepo  repositories.ITokenRepository
+	token      *tokens.TokenService
+	hasher     hasher.Hasher
+	cartMerger CartMerger
 }
 
 func NewUserService(
 	validator *validation.Validator,
 	token *tokens.TokenService,
 	repo repositories.IUserRepository,
+	tokenRepo repositories.ITokenRepository,
 	hasher hasher.Hasher,
+	cartMerger CartMerger,
 ) *UserService {
 	return &UserService{
-		validator: *validator,
-		repo:      repo,
-		token:     *token,
-		hasher:    hasher,
+		validator:  *validator,
+		repo:       repo,
+		tokenRepo:  tokenRepo,
+		token:      token,
+		hasher:     hasher,
+		cartMerger: cartMerger,
 	}
 }
 
@@ -70,22 +90,19 @@ func (s *UserService) Register(ctx context.Context, dto *dtos.CreateUserRequest)
 		return nil, err
 	}
 
+	user = dto.ToUser()
 	if err := s.repo.CreateUser(ctx, user); err != nil {
 		return nil, err
 	}
 
-	payload := map[string]interface{}{
-		"Id":       user.ID.Hex(),
-		"Email":    user.Email,
-		"Role":     user.Role,
-		"Username": user.Username,
+	pair, err := s.issueTokens(ctx, user, primitive.NewObjectID())
+	if err != nil {
+		return nil, err
 	}
 
-	token := tokens.GenerateAccessToken(payload)
-
 	return &dtos.CreateUserResponse{
-		ID:    user.ID.Hex(),
-		Token: token,
+		ID:           user.ID.Hex(),
+		TokenPairDTO: *pair,
 	}, nil
 }
 
@@ -102,17 +119,147 @@ func (s *UserService) Login(ctx context.Context, dto *dtos.AuthDTO) (*dtos.AuthR
 		return nil, errors.NewError(errors.InvalidCredentials, http.StatusUnauthorized, "invalid credentials")
 	}
 
-	payload := map[string]interface{}{
-		"Id":    existUser.ID.Hex(),
-		"Email": existUser.Email,
-		"Role":  existUser.Role,
+	// Every sign-in starts a new family of refresh tokens.
+	pair, err := s.issueTokens(ctx, existUser, primitive.NewObjectID())
+	if err != nil {
+		return nil, err
 	}
 
-	token := tokens.GenerateAccessToken(payload)
+	// A failed merge must not block the sign-in; the guest cart stays
+	// available under its token.
+	if dto.CartToken != "" && s.cartMerger != nil {
+		if err := s.cartMerger.MergeGuestCart(ctx, existUser.ID, dto.CartToken); err != nil {
+			log.Printf("merging guest cart into cart of user %s: %v", existUser.ID.Hex(), err)
+		}
+	}
 
 	return &dtos.AuthResponseDTO{
-		Email: existUser.Email,
-		Token: token,
+		Email:        existUser.Email,
+		TokenPairDTO: *pair,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+// A refresh token works once: presenting it again means it was stolen, so the
+// whole family is revoked and its owner has to sign in again.
+func (s *UserService) Refresh(ctx context.Context, dto *dtos.RefreshTokenDTO) (*dtos.TokenPairDTO, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+
+	token, err := s.tokenRepo.GetRefreshToken(ctx, tokens.HashRefreshToken(dto.RefreshToken))
+	if err != nil {
+		if stdErrors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.NewUnauthorizedError("invalid refresh token")
+		}
+		return nil, errors.Wrap(err, "finding refresh token")
+	}
+	if token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, errors.NewUnauthorizedError("invalid refresh token")
+	}
+
+	fresh := token.UsedAt == nil
+	if fresh {
+		if fresh, err = s.tokenRepo.MarkRefreshTokenUsed(ctx, token.ID); err != nil {
+			return nil, errors.Wrap(err, "using refresh token")
+		}
+	}
+	if !fresh {
+		log.Printf("refresh token reused for user %s, revoking family %s", token.UserID.Hex(), token.FamilyID.Hex())
+		if err := s.tokenRepo.RevokeFamily(ctx, token.FamilyID); err != nil {
+			return nil, errors.Wrap(err, "revoking refresh token family")
+		}
+		return nil, errors.NewUnauthorizedError("refresh token was already used, sign in again")
+	}
+
+	user, err := s.repo.GetUserByID(ctx, token.UserID.Hex())
+	if err != nil {
+		return nil, errors.Wrap(err, "getting user by ID")
+	}
+	if user == nil {
+		if err := s.tokenRepo.RevokeFamily(ctx, token.FamilyID); err != nil {
+			return nil, errors.Wrap(err, "revoking refresh token family")
+		}
+		return nil, errors.NewUnauthorizedError("invalid refresh token")
+	}
+
+	return s.issueTokens(ctx, user, token.FamilyID)
+}
+
+// Logout revokes the access token of the request and, when given, the family
+// of the user's refresh token. Unknown refresh tokens are ignored.
+func (s *UserService) Logout(ctx context.Context, claims tokens.Claims, dto *dtos.LogoutDTO) error {
+	userObjectID, err := primitive.ObjectIDFromHex(claims.UserID())
+	if err != nil {
+		return errors.NewUnauthorizedError("invalid user ID")
+	}
+
+	revoked := &models.RevokedToken{
+		ID:        claims.ID,
+		UserID:    userObjectID,
+		RevokedAt: time.Now(),
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if err := s.tokenRepo.RevokeAccessToken(ctx, revoked); err != nil {
+		return errors.Wrap(err, "revoking access token")
+	}
+
+	if dto.RefreshToken == "" {
+		return nil
+	}
+	token, err := s.tokenRepo.GetRefreshToken(ctx, tokens.HashRefreshToken(dto.RefreshToken))
+	if err != nil {
+		if stdErrors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		return errors.Wrap(err, "finding refresh token")
+	}
+	if token.UserID != userObjectID {
+		return nil
+	}
+	if err := s.tokenRepo.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return errors.Wrap(err, "revoking refresh token family")
+	}
+	return nil
+}
+
+// issueTokens signs an access token for the user and stores the refresh token
+// issued with it in the given family.
+func (s *UserService) issueTokens(ctx context.Context, user *models.User, familyID primitive.ObjectID) (*dtos.TokenPairDTO, error) {
+	access, err := s.token.GenerateAccessToken(tokens.Claims{
+		Role:             user.Role,
+		Email:            user.Email,
+		Username:         user.Username,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: user.ID.Hex()},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "generating access token")
+	}
+	refresh, err := s.token.GenerateRefreshToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "generating refresh token")
+	}
+
+	err = s.tokenRepo.CreateRefreshToken(ctx, &models.RefreshToken{
+		UserID:          user.ID,
+		FamilyID:        familyID,
+		TokenHash:       refresh.Hash,
+		AccessTokenID:   access.ID,
+		AccessExpiresAt: access.ExpiresAt,
+		CreatedAt:       time.Now(),
+		ExpiresAt:       refresh.ExpiresAt,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "storing refresh token")
+	}
+
+	return &dtos.TokenPairDTO{
+		Token:          access.Token,
+		TokenExpiresAt: access.ExpiresAt,
+		RefreshToken:   refresh.Token,
 	}, nil
 }
 
@@ -144,6 +291,11 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, user *dtos.Upda
 	if err := s.validator.ValidateStruct(user); err != nil {
 		return nil, errors.Wrap(err, "validating update request")
 	}
+	if user.Role != nil && *user.Role != existingUser.Role {
+		if p, ok := principal.FromContext(ctx); !ok || !p.IsAdmin() {
+			return nil, errors.NewError(errors.UnauthorizedType, http.StatusForbidden, "only admins can change roles")
+		}
+	}
 
 	utils.Copy(existingUser, user)
 	if err := s.repo.UpdateUser(ctx, id, existingUser); err != nil {
@@ -186,7 +338,7 @@ func (s *UserService) FindUser(ctx context.Context, login string) (*models.User,
 	}
 	user, err = s.repo.GetUserByUsername(ctx, login)
 
-	if err != nil {
+	if err != nil || user == nil {
 		return nil, errors.NewNotFoundError("user", login)
 	}
 
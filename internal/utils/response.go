@@ -2,6 +2,8 @@ package utils
 
 import (
 	"fmt"
+
+	"github.com/devbenho/luka-platform/pkg/database"
 )
 
 type Response struct {
@@ -79,3 +81,17 @@ func NewInternalErrorResponse(message string) *Response {
 		Type:    "INTERNAL_SERVER_ERROR",
 	}
 }
+
+// PagedResponse is the Response of list endpoints. Page tells where the
+// returned items sit in the whole listing and how to fetch the next ones.
+type PagedResponse struct {
+	Response
+	Page *database.Page `json:"page"`
+}
+
+func NewPagedResponse(status int, message string, data interface{}, page *database.Page) *PagedResponse {
+	return &PagedResponse{
+		Response: *NewSuccessResponse(status, message, data),
+		Page:     page,
+	}
+}
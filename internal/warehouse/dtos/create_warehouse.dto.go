@@ -0,0 +1,21 @@
+package dtos
+
+import (
+	"github.com/devbenho/luka-platform/internal/warehouse/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CreateWarehouseRequest struct {
+	Name     string             `json:"name" validate:"required"`
+	Location models.Location    `json:"location" validate:"required"`
+	StoreID  primitive.ObjectID `json:"store_id" validate:"required"`
+}
+
+func (r *CreateWarehouseRequest) ToWarehouse() *models.Warehouse {
+	return &models.Warehouse{
+		Name:     r.Name,
+		Location: r.Location,
+		StoreID:  r.StoreID,
+		Status:   "active",
+	}
+}
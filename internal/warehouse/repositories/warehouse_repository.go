@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/warehouse/models"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IWarehouseRepository interface {
+	CreateWarehouse(ctx context.Context, warehouse *models.Warehouse) (*models.Warehouse, error)
+	GetWarehouseByID(ctx context.Context, id primitive.ObjectID) (*models.Warehouse, error)
+	ListWarehousesByStore(ctx context.Context, storeID primitive.ObjectID) ([]models.Warehouse, error)
+}
+
+type WarehouseRepository struct {
+	db database.IDatabase
+}
+
+func NewWarehouseRepository(db database.IDatabase) IWarehouseRepository {
+	return &WarehouseRepository{
+		db: db,
+	}
+}
+
+func (r *WarehouseRepository) CreateWarehouse(ctx context.Context, warehouse *models.Warehouse) (*models.Warehouse, error) {
+	warehouse.ID = primitive.NewObjectID()
+	warehouse.CreatedAt = time.Now()
+	warehouse.UpdatedAt = time.Now()
+	if err := r.db.Create(ctx, "warehouses", warehouse); err != nil {
+		return nil, fmt.Errorf("failed to create warehouse in db: %w", err)
+	}
+	return warehouse, nil
+}
+
+func (r *WarehouseRepository) GetWarehouseByID(ctx context.Context, id primitive.ObjectID) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	filter := bson.M{"_id": id, "deleted_at": nil}
+	if err := r.db.FindOne(ctx, "warehouses", filter, &warehouse); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("warehouse not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get warehouse from db: %w", err)
+	}
+	return &warehouse, nil
+}
+
+func (r *WarehouseRepository) ListWarehousesByStore(ctx context.Context, storeID primitive.ObjectID) ([]models.Warehouse, error) {
+	warehouses := []models.Warehouse{}
+	filter := bson.M{"store_id": storeID, "deleted_at": nil}
+	if err := r.db.Find(ctx, "warehouses", filter, &warehouses); err != nil {
+		return nil, fmt.Errorf("failed to list warehouses from db: %w", err)
+	}
+	return warehouses, nil
+}
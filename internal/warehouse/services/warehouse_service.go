@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+
+	ownership "github.com/devbenho/luka-platform/internal/ownership/services"
+	"github.com/devbenho/luka-platform/internal/warehouse/dtos"
+	"github.com/devbenho/luka-platform/internal/warehouse/models"
+	"github.com/devbenho/luka-platform/internal/warehouse/repositories"
+	"github.com/devbenho/luka-platform/pkg/errors"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IWarehouseService interface {
+	CreateWarehouse(ctx context.Context, dto *dtos.CreateWarehouseRequest) (*models.Warehouse, error)
+	GetWarehouseByID(ctx context.Context, id string) (*models.Warehouse, error)
+	ListWarehousesByStore(ctx context.Context, storeID string) ([]models.Warehouse, error)
+}
+
+type WarehouseService struct {
+	repo      repositories.IWarehouseRepository
+	ownership ownership.IOwnershipService
+	validator *validation.Validator
+}
+
+func NewWarehouseService(repo repositories.IWarehouseRepository, ownership ownership.IOwnershipService, validator *validation.Validator) IWarehouseService {
+	return &WarehouseService{
+		repo:      repo,
+		ownership: ownership,
+		validator: validator,
+	}
+}
+
+func (s *WarehouseService) CreateWarehouse(ctx context.Context, dto *dtos.CreateWarehouseRequest) (*models.Warehouse, error) {
+	if err := s.validator.ValidateStruct(dto); err != nil {
+		if validationErrors, ok := err.(errors.ValidationErrors); ok {
+			return nil, validationErrors
+		}
+		return nil, err
+	}
+	if _, err := s.ownership.AuthorizeStore(ctx, dto.StoreID); err != nil {
+		return nil, err
+	}
+
+	warehouse, err := s.repo.CreateWarehouse(ctx, dto.ToWarehouse())
+	if err != nil {
+		return nil, errors.Wrap(err, "creating warehouse")
+	}
+	return warehouse, nil
+}
+
+func (s *WarehouseService) GetWarehouseByID(ctx context.Context, id string) (*models.Warehouse, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.NewBadRequestError("invalid warehouse ID")
+	}
+	return s.ownership.AuthorizeWarehouse(ctx, objID)
+}
+
+func (s *WarehouseService) ListWarehousesByStore(ctx context.Context, storeID string) ([]models.Warehouse, error) {
+	objID, err := primitive.ObjectIDFromHex(storeID)
+	if err != nil {
+		return nil, errors.NewBadRequestError("invalid store ID")
+	}
+	if _, err := s.ownership.AuthorizeStore(ctx, objID); err != nil {
+		return nil, err
+	}
+	warehouses, err := s.repo.ListWarehousesByStore(ctx, objID)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing warehouses")
+	}
+	return warehouses, nil
+}
@@ -0,0 +1,64 @@
+// Package cache keeps recently computed values in memory for a short while.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many writes pass between two sweeps of expired entries.
+const sweepEvery = 128
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTLCache is a map whose entries expire a fixed time after they were set. It
+// is safe for concurrent use.
+type TTLCache[K comparable, V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[K]entry[V]
+	writes  int
+}
+
+func NewTTLCache[K comparable, V any](ttl time.Duration) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		ttl:     ttl,
+		entries: make(map[K]entry[V]),
+	}
+}
+
+// Get returns the value stored under key unless it has expired.
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores value under key. A cache with no TTL stores nothing.
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.entries[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+	c.writes++
+	if c.writes%sweepEvery == 0 {
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+}
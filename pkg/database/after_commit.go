@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"sync"
+)
+
+type afterCommitKey struct{}
+
+// afterCommit holds the functions to run once a transaction has committed.
+type afterCommit struct {
+	mu    sync.Mutex
+	hooks []func(ctx context.Context)
+}
+
+// AfterCommit runs fn once the transaction ctx belongs to has committed. When
+// transactions are nested, that is the outermost one, since the inner ones
+// only join it. fn is dropped if the transaction aborts, and a retried
+// transaction only runs the functions queued by its last attempt. Outside a
+// transaction started by WithTransaction, fn runs at once.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	queue, ok := ctx.Value(afterCommitKey{}).(*afterCommit)
+	if !ok {
+		fn(ctx)
+		return
+	}
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	queue.hooks = append(queue.hooks, fn)
+}
+
+// withAfterCommit returns a context on which AfterCommit queues functions,
+// and the queue.
+func withAfterCommit(ctx context.Context) (context.Context, *afterCommit) {
+	queue := &afterCommit{}
+	return context.WithValue(ctx, afterCommitKey{}, queue), queue
+}
+
+// reset drops the functions queued by a transaction attempt that is retried.
+func (q *afterCommit) reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.hooks = nil
+}
+
+// run calls the queued functions in order with ctx, which must not carry the
+// committed transaction.
+func (q *afterCommit) run(ctx context.Context) {
+	q.mu.Lock()
+	hooks := q.hooks
+	q.hooks = nil
+	q.mu.Unlock()
+	for _, fn := range hooks {
+		fn(ctx)
+	}
+}
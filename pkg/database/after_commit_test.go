@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestAfterCommit(t *testing.T) {
+	var ran []string
+	queue := func(ctx context.Context, name string) {
+		AfterCommit(ctx, func(ctx context.Context) {
+			if ctx.Value(afterCommitKey{}) != nil {
+				t.Errorf("%s ran inside the transaction's context", name)
+			}
+			ran = append(ran, name)
+		})
+	}
+
+	t.Run("runs at once outside a transaction", func(t *testing.T) {
+		ran = nil
+		queue(context.Background(), "a")
+		if !reflect.DeepEqual(ran, []string{"a"}) {
+			t.Errorf("ran %v, want [a]", ran)
+		}
+	})
+
+	t.Run("waits for the commit", func(t *testing.T) {
+		ran = nil
+		ctx, afterCommit := withAfterCommit(context.Background())
+		queue(ctx, "a")
+		// A nested transaction joins the outer one and queues on it.
+		queue(mongo.NewSessionContext(ctx, nil), "b")
+		if len(ran) != 0 {
+			t.Fatalf("ran %v before the commit", ran)
+		}
+		afterCommit.run(context.Background())
+		if !reflect.DeepEqual(ran, []string{"a", "b"}) {
+			t.Errorf("ran %v, want [a b]", ran)
+		}
+		afterCommit.run(context.Background())
+		if len(ran) != 2 {
+			t.Errorf("ran %v, want each function once", ran)
+		}
+	})
+
+	t.Run("drops what a retried attempt queued", func(t *testing.T) {
+		ran = nil
+		ctx, afterCommit := withAfterCommit(context.Background())
+		queue(ctx, "first attempt")
+		afterCommit.reset()
+		queue(ctx, "second attempt")
+		afterCommit.run(context.Background())
+		if !reflect.DeepEqual(ran, []string{"second attempt"}) {
+			t.Errorf("ran %v, want [second attempt]", ran)
+		}
+	})
+}
@@ -18,13 +18,18 @@ type IDatabase interface {
 	Create(ctx context.Context, collection string, doc interface{}) error
 	CreateInBatches(ctx context.Context, collection string, docs []interface{}) error
 	Update(ctx context.Context, collection string, filter, update interface{}) error
+	UpdateMany(ctx context.Context, collection string, filter, update interface{}) error
+	FindOneAndUpdate(ctx context.Context, collection string, filter, update, result interface{}) error
+	FindOneAndUpsert(ctx context.Context, collection string, filter, update, result interface{}) error
 	Delete(ctx context.Context, collection string, filter interface{}) error
 	DeleteAll(ctx context.Context, collection string, filter interface{}) error
 	SoftDelete(ctx context.Context, collection string, filter interface{}) error
 	FindById(ctx context.Context, collection, id string, result interface{}) error
 	FindOne(ctx context.Context, collection string, filter, result interface{}) error
 	Find(ctx context.Context, collection string, filter, result interface{}) error
+	FindPage(ctx context.Context, collection string, query Query, result interface{}) (*Page, error)
 	Count(ctx context.Context, collection string, filter interface{}) (int64, error)
+	Aggregate(ctx context.Context, collection string, pipeline, result interface{}) error
 }
 
 type Database struct {
@@ -58,20 +63,33 @@ func (d *Database) GetDB() *mongo.Database {
 	return d.database
 }
 
+// WithTransaction runs function inside a transaction. When ctx already carries
+// a session the function joins that transaction instead of starting a new one,
+// so transactional operations can be composed. Functions queued with
+// AfterCommit run once the transaction started here has committed.
 func (d *Database) WithTransaction(ctx context.Context, function func(sessCtx mongo.SessionContext) error) error {
+	if session := mongo.SessionFromContext(ctx); session != nil {
+		return function(mongo.NewSessionContext(ctx, session))
+	}
+
 	session, err := d.client.StartSession()
 	if err != nil {
 		return err
 	}
 	defer session.EndSession(ctx)
 
+	txCtx, afterCommit := withAfterCommit(ctx)
 	callback := func(sessCtx mongo.SessionContext) (interface{}, error) {
+		afterCommit.reset()
 		err := function(sessCtx)
 		return nil, err
 	}
 
-	_, err = session.WithTransaction(ctx, callback)
-	return err
+	if _, err = session.WithTransaction(txCtx, callback); err != nil {
+		return err
+	}
+	afterCommit.run(ctx)
+	return nil
 }
 
 func (d *Database) Create(ctx context.Context, collection string, doc interface{}) error {
@@ -98,6 +116,35 @@ func (d *Database) Update(ctx context.Context, collection string, filter, update
 	return err
 }
 
+func (d *Database) UpdateMany(ctx context.Context, collection string, filter, update interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	_, err := d.database.Collection(collection).UpdateMany(ctx, filter, update)
+	return err
+}
+
+// FindOneAndUpdate applies update to the first document matching filter and
+// decodes the updated document into result. It returns mongo.ErrNoDocuments
+// when nothing matches, which makes it suitable for conditional updates.
+func (d *Database) FindOneAndUpdate(ctx context.Context, collection string, filter, update, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	return d.database.Collection(collection).FindOneAndUpdate(ctx, filter, update, opts).Decode(result)
+}
+
+// FindOneAndUpsert is FindOneAndUpdate that inserts the document when nothing
+// matches filter, which makes it suitable for counters.
+func (d *Database) FindOneAndUpsert(ctx context.Context, collection string, filter, update, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetUpsert(true)
+	return d.database.Collection(collection).FindOneAndUpdate(ctx, filter, update, opts).Decode(result)
+}
+
 func (d *Database) Delete(ctx context.Context, collection string, filter interface{}) error {
 	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
 	defer cancel()
@@ -166,3 +213,18 @@ func (d *Database) Count(ctx context.Context, collection string, filter interfac
 	count, err := d.database.Collection(collection).CountDocuments(ctx, filter)
 	return count, err
 }
+
+// Aggregate runs an aggregation pipeline and decodes every resulting document
+// into result, which must point to a slice.
+func (d *Database) Aggregate(ctx context.Context, collection string, pipeline, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	cursor, err := d.database.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, result)
+}
@@ -0,0 +1,248 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ErrInvalidCursor is returned by FindPage for cursors that are malformed or
+// were issued for a different sort.
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// PageRequest is how a client asks for one page of a listing: either an
+// offset into it or the cursor of the previous page.
+type PageRequest struct {
+	Limit        int64  `form:"limit" json:"limit" validate:"omitempty,min=1,max=100"`
+	Offset       int64  `form:"offset" json:"offset" validate:"omitempty,min=0"`
+	Cursor       string `form:"cursor" json:"cursor" validate:"excluded_with=Offset"`
+	IncludeTotal bool   `form:"include_total" json:"include_total"`
+}
+
+// Query selects, sorts and pages the documents read by FindPage. Documents are
+// always ordered by _id last, so that pages are stable when sort keys tie.
+type Query struct {
+	Filter     interface{}
+	Sort       bson.D
+	Projection interface{}
+	Page       PageRequest
+}
+
+// Page describes the page FindPage returned. NextCursor resumes the listing
+// after it and is empty on the last page; Total is only counted on request.
+type Page struct {
+	Limit      int64  `json:"limit"`
+	Offset     int64  `json:"offset,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// pageCursor is the decoded form of a cursor: the sort keys it was issued for
+// and the values the last document of the page had for them.
+type pageCursor struct {
+	Keys   []string        `bson:"k"`
+	Values []bson.RawValue `bson:"v"`
+}
+
+// FindPage reads one page of the documents matching query into result, which
+// must point to a slice.
+func (d *Database) FindPage(ctx context.Context, collection string, query Query, result interface{}) (*Page, error) {
+	ctx, cancel := context.WithTimeout(ctx, DatabaseTimeout)
+	defer cancel()
+
+	sort := withIDLast(query.Sort)
+	page := &Page{Limit: query.Page.Limit, Offset: query.Page.Offset}
+	if page.Limit <= 0 {
+		page.Limit = DefaultPageSize
+	}
+	if page.Limit > MaxPageSize {
+		page.Limit = MaxPageSize
+	}
+
+	filter := query.Filter
+	if filter == nil {
+		filter = bson.D{}
+	}
+	if query.Page.IncludeTotal {
+		total, err := d.database.Collection(collection).CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+	if query.Page.Cursor != "" {
+		after, err := decodeCursor(query.Page.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		filter = bson.D{{Key: "$and", Value: bson.A{filter, after}}}
+	}
+
+	// One more document than asked tells whether another page follows.
+	opts := options.Find().SetSort(sort).SetLimit(page.Limit + 1)
+	if page.Offset > 0 {
+		opts.SetSkip(page.Offset)
+	}
+	if query.Projection != nil {
+		opts.SetProjection(query.Projection)
+	}
+
+	cursor, err := d.database.Collection(collection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []bson.Raw
+	for cursor.Next(ctx) {
+		docs = append(docs, append(bson.Raw(nil), cursor.Current...))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	if int64(len(docs)) > page.Limit {
+		docs = docs[:page.Limit]
+		page.HasMore = true
+		if page.NextCursor, err = encodeCursor(docs[len(docs)-1], sort); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := decodeAll(docs, result); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// withIDLast appends _id to a sort that does not end with it.
+func withIDLast(sort bson.D) bson.D {
+	for _, key := range sort {
+		if key.Key == "_id" {
+			return sort
+		}
+	}
+	direction := interface{}(1)
+	if len(sort) > 0 {
+		direction = sort[len(sort)-1].Value
+	}
+	return append(append(bson.D{}, sort...), bson.E{Key: "_id", Value: direction})
+}
+
+func encodeCursor(doc bson.Raw, sort bson.D) (string, error) {
+	cursor := pageCursor{}
+	for _, key := range sort {
+		value, err := doc.LookupErr(strings.Split(key.Key, ".")...)
+		if err != nil {
+			// Documents without the key sort first, as if it were null.
+			value = bson.RawValue{Type: bson.TypeNull}
+		}
+		cursor.Keys = append(cursor.Keys, key.Key)
+		cursor.Values = append(cursor.Values, value)
+	}
+	data, err := bson.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode page cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor turns a cursor into the filter that skips every document up to
+// and including the one it was taken from: for sort keys k1..kn it matches
+// k1 past v1, or k1 equal and k2 past v2, and so on.
+func decodeCursor(value string, sort bson.D) (bson.D, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor pageCursor
+	if err := bson.Unmarshal(data, &cursor); err != nil || len(cursor.Keys) != len(sort) || len(cursor.Values) != len(sort) {
+		return nil, ErrInvalidCursor
+	}
+	for i, key := range sort {
+		if cursor.Keys[i] != key.Key {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	branches := bson.A{}
+	for i, key := range sort {
+		branch := bson.D{}
+		for j := 0; j < i; j++ {
+			branch = append(branch, bson.E{Key: sort[j].Key, Value: cursor.Values[j]})
+		}
+		past, ok := pastValue(key.Key, cursor.Values[i], descending(key.Value))
+		if !ok {
+			continue
+		}
+		branches = append(branches, append(branch, past...))
+	}
+	if len(branches) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return bson.D{{Key: "$or", Value: branches}}, nil
+}
+
+// pastValue matches the values of key that sort after value. Missing keys
+// sort as null: first in ascending order, last in descending order. Range
+// operators never match null, so it is handled on its own; ok is false when
+// nothing can sort after value.
+func pastValue(key string, value bson.RawValue, desc bool) (bson.D, bool) {
+	null := value.Type == bson.TypeNull || value.Type == bson.TypeUndefined
+	switch {
+	case null && desc:
+		return nil, false
+	case null:
+		return bson.D{{Key: key, Value: bson.D{{Key: "$ne", Value: nil}}}}, true
+	case desc:
+		return bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: key, Value: bson.D{{Key: "$lt", Value: value}}}},
+			bson.D{{Key: key, Value: nil}},
+		}}}, true
+	}
+	return bson.D{{Key: key, Value: bson.D{{Key: "$gt", Value: value}}}}, true
+}
+
+func descending(direction interface{}) bool {
+	switch d := direction.(type) {
+	case int:
+		return d < 0
+	case int32:
+		return d < 0
+	case int64:
+		return d < 0
+	case float64:
+		return d < 0
+	}
+	return false
+}
+
+// decodeAll unmarshals raw documents into the slice result points to.
+func decodeAll(docs []bson.Raw, result interface{}) error {
+	target := reflect.ValueOf(result)
+	if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("result must point to a slice, got %T", result)
+	}
+	slice := target.Elem()
+	items := reflect.MakeSlice(slice.Type(), 0, len(docs))
+	for _, doc := range docs {
+		item := reflect.New(slice.Type().Elem())
+		if err := bson.Unmarshal(doc, item.Interface()); err != nil {
+			return err
+		}
+		items = reflect.Append(items, item.Elem())
+	}
+	slice.Set(items)
+	return nil
+}
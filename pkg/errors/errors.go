@@ -16,6 +16,9 @@ const (
 	InvalidCredentials   ErrorType = "INVALID_CREDENTIALS"
 	UserAlreadyExists    ErrorType = "USER_ALREADY_EXISTS"
 	AdminCannotBeDeleted ErrorType = "ADMIN_CANNOT_BE_DELETED"
+	InsufficientStock    ErrorType = "INSUFFICIENT_STOCK"
+	InvalidCoupon        ErrorType = "INVALID_COUPON"
+	PaymentFailed        ErrorType = "PAYMENT_FAILED"
 )
 
 // AppError is the base error type for the application
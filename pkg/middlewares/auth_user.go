@@ -1,14 +1,31 @@
 package middleware
 
 import (
+	"context"
 	"log"
 	"net/http"
 
 	config "github.com/devbenho/luka-platform/configs"
+	"github.com/devbenho/luka-platform/pkg/principal"
 	"github.com/devbenho/luka-platform/pkg/tokens"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// TokenRevocations tells whether an access token was revoked before it
+// expired, e.g. on logout.
+type TokenRevocations interface {
+	IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+var tokenRevocations TokenRevocations
+
+// UseTokenRevocations makes the JWT middlewares refuse the access tokens
+// revoked in revocations. It must be called before requests are served.
+func UseTokenRevocations(revocations TokenRevocations) {
+	tokenRevocations = revocations
+}
+
 func JWTAuth() gin.HandlerFunc {
 	return JWT(config.GetConfig().JWT.Type)
 }
@@ -22,17 +39,68 @@ func JWT(tokenType string) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		log.Print(token)
-		payload, err := tokens.ValidateToken(token)
+		if !authenticate(c, token) {
+			return
+		}
+		c.Next()
+	}
+}
 
+// OptionalJWTAuth authenticates the request when it carries a valid token and
+// lets it through anonymously otherwise, for routes open to guests.
+func OptionalJWTAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("Authorization")
+		if token == "" {
+			c.Next()
+			return
+		}
+		if !authenticate(c, token) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticate validates the access token and stores its user in the gin
+// context and, as the principal services authorize against, in the request
+// context. It aborts the request when the token is invalid or revoked.
+func authenticate(c *gin.Context, token string) bool {
+	claims, err := tokens.ValidateToken(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, nil)
+		c.Abort()
+		return false
+	}
+	if tokenRevocations != nil {
+		revoked, err := tokenRevocations.IsAccessTokenRevoked(c.Request.Context(), claims.ID)
 		if err != nil {
+			log.Printf("checking revocation of access token %s: %v", claims.ID, err)
+			c.JSON(http.StatusInternalServerError, nil)
+			c.Abort()
+			return false
+		}
+		if revoked {
 			c.JSON(http.StatusUnauthorized, nil)
 			c.Abort()
-			return
+			return false
 		}
-		c.Set("userId", payload["Id"])
-		c.Set("role", payload["Role"])
-		c.Set("username", payload["username"])
-		c.Next()
 	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.UserID())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, nil)
+		c.Abort()
+		return false
+	}
+
+	c.Request = c.Request.WithContext(principal.NewContext(c.Request.Context(), principal.Principal{
+		UserID: userID,
+		Role:   claims.Role,
+	}))
+	c.Set("claims", claims)
+	c.Set("userId", claims.UserID())
+	c.Set("role", claims.Role)
+	c.Set("username", claims.Username)
+	return true
 }
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/devbenho/luka-platform/pkg/tokens"
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentUser returns the ID and role of the user authenticated by JWT.
+func CurrentUser(c *gin.Context) (userID string, role string, ok bool) {
+	value, exists := c.Get("userId")
+	if !exists {
+		return "", "", false
+	}
+	userID, ok = value.(string)
+	if !ok {
+		return "", "", false
+	}
+	if value, exists := c.Get("role"); exists {
+		role, _ = value.(string)
+	}
+	return userID, role, true
+}
+
+// CurrentClaims returns the claims of the access token the request was
+// authenticated with.
+func CurrentClaims(c *gin.Context) (*tokens.Claims, bool) {
+	value, exists := c.Get("claims")
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*tokens.Claims)
+	return claims, ok
+}
@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/devbenho/luka-platform/internal/utils"
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	idempotencyCollection    = "idempotency_keys"
+	maxIdempotencyKeyLength  = 255
+	// idempotencyLease is how long a key stays reserved by a request that
+	// has not completed. A server that dies mid-request leaves its record
+	// behind; once the lease runs out the key can be used again.
+	idempotencyLease = time.Minute
+)
+
+// idempotencyRecord remembers the response given to the first request sent
+// with a key. Until that request completes the record only reserves the key.
+type idempotencyRecord struct {
+	ID          string    `bson:"_id"`
+	UserID      string    `bson:"user_id"`
+	Key         string    `bson:"key"`
+	RequestHash string    `bson:"request_hash"`
+	Completed   bool      `bson:"completed"`
+	Status      int       `bson:"status,omitempty"`
+	ContentType string    `bson:"content_type,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
+
+// Idempotency makes retries of a request carrying an Idempotency-Key header
+// safe. The first request with a key runs normally and its response is kept
+// for ttl; repeats by the same user get that response back without running
+// the handler again, and reusing the key for a different request is refused.
+// Requests without the header, or from anonymous users, are not affected.
+// It must come after the middleware that authenticates the user, and relies
+// on the index EnsureIdempotencyIndexes creates to expire records.
+func Idempotency(db database.IDatabase, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userID, _, ok := CurrentUser(c)
+		if key == "" || !ok {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", "Idempotency-Key is too long"))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		now := time.Now()
+		record := &idempotencyRecord{
+			ID:          userID + ":" + key,
+			UserID:      userID,
+			Key:         key,
+			RequestHash: hashRequest(c.Request.Method, c.Request.URL.Path, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(idempotencyLease),
+		}
+		// Once the lease may have run out, the key can belong to another
+		// request, so the record is only touched while it is still ours.
+		owned := bson.M{"_id": record.ID, "created_at": record.CreatedAt}
+
+		claimed, err := claimIdempotencyKey(ctx, db, record)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, utils.NewInternalErrorResponse("Internal server error"))
+			c.Abort()
+			return
+		}
+		if !claimed {
+			replayIdempotentResponse(c, db, record)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			// Server errors and panics release the key so the request can be
+			// retried; anything else is final and kept for replays.
+			if !completed || recorder.Status() >= http.StatusInternalServerError {
+				if err := db.Delete(context.WithoutCancel(ctx), idempotencyCollection, owned); err != nil {
+					log.Printf("releasing idempotency key %s: %v", record.ID, err)
+				}
+			}
+		}()
+
+		c.Next()
+		completed = true
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		update := bson.M{"$set": bson.M{
+			"completed":    true,
+			"status":       recorder.Status(),
+			"content_type": recorder.Header().Get("Content-Type"),
+			"body":         recorder.body.Bytes(),
+			"expires_at":   now.Add(ttl),
+		}}
+		if err := db.Update(context.WithoutCancel(ctx), idempotencyCollection, owned, update); err != nil {
+			log.Printf("saving idempotent response %s: %v", record.ID, err)
+		}
+	}
+}
+
+// claimIdempotencyKey reserves the key for this request, reporting false when
+// an earlier request holds it: one that completed within the TTL, or one
+// still running within its lease. The TTL monitor only runs about once a
+// minute, so an expired record may still be found and is replaced.
+func claimIdempotencyKey(ctx context.Context, db database.IDatabase, record *idempotencyRecord) (bool, error) {
+	err := db.Create(ctx, idempotencyCollection, record)
+	if err == nil {
+		return true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return false, err
+	}
+
+	filter := bson.M{"_id": record.ID, "expires_at": bson.M{"$lte": time.Now()}}
+	var replaced idempotencyRecord
+	err = db.FindOneAndUpdate(ctx, idempotencyCollection, filter, bson.M{"$set": record}, &replaced)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func replayIdempotentResponse(c *gin.Context, db database.IDatabase, record *idempotencyRecord) {
+	var existing idempotencyRecord
+	if err := db.FindOne(c.Request.Context(), idempotencyCollection, bson.M{"_id": record.ID}, &existing); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewInternalErrorResponse("Internal server error"))
+		c.Abort()
+		return
+	}
+
+	switch {
+	case existing.RequestHash != record.RequestHash:
+		c.JSON(http.StatusUnprocessableEntity, utils.NewErrorResponse(http.StatusUnprocessableEntity, "Idempotency-Key reused", "the key was already used for a different request"))
+	case !existing.Completed:
+		c.JSON(http.StatusConflict, utils.NewErrorResponse(http.StatusConflict, "Request in progress", "a request with this Idempotency-Key is still being processed"))
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(existing.Status, existing.ContentType, existing.Body)
+	}
+	c.Abort()
+}
+
+// hashRequest fingerprints what a key was first used for: the path, including
+// the resource IDs in it, and the exact body.
+func hashRequest(method, route string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + route + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// EnsureIdempotencyIndexes creates the index that expires idempotency
+// records once their TTL or lease is over.
+func EnsureIdempotencyIndexes(ctx context.Context, db database.IDatabase) error {
+	ctx, cancel := context.WithTimeout(ctx, database.DatabaseTimeout)
+	defer cancel()
+
+	_, err := db.GetDB().Collection(idempotencyCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create idempotency key index: %w", err)
+	}
+	return nil
+}
+
+// responseRecorder keeps a copy of the response body as it is written.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devbenho/luka-platform/pkg/database"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeIdempotencyStore keeps idempotency records in memory and understands
+// the filters the middleware sends.
+type fakeIdempotencyStore struct {
+	database.IDatabase
+	records map[string]idempotencyRecord
+}
+
+func (s *fakeIdempotencyStore) Create(ctx context.Context, collection string, doc interface{}) error {
+	record := *doc.(*idempotencyRecord)
+	if _, exists := s.records[record.ID]; exists {
+		return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+	}
+	s.records[record.ID] = record
+	return nil
+}
+
+func (s *fakeIdempotencyStore) FindOne(ctx context.Context, collection string, filter, result interface{}) error {
+	record, exists := s.records[filter.(bson.M)["_id"].(string)]
+	if !exists {
+		return mongo.ErrNoDocuments
+	}
+	*result.(*idempotencyRecord) = record
+	return nil
+}
+
+// FindOneAndUpdate replaces a record whose lease or TTL is over.
+func (s *fakeIdempotencyStore) FindOneAndUpdate(ctx context.Context, collection string, filter, update, result interface{}) error {
+	id := filter.(bson.M)["_id"].(string)
+	record, exists := s.records[id]
+	if !exists || record.ExpiresAt.After(time.Now()) {
+		return mongo.ErrNoDocuments
+	}
+	record = *update.(bson.M)["$set"].(*idempotencyRecord)
+	s.records[id] = record
+	*result.(*idempotencyRecord) = record
+	return nil
+}
+
+// owned finds the record a request claimed, by ID and claim time.
+func (s *fakeIdempotencyStore) owned(filter interface{}) (idempotencyRecord, bool) {
+	f := filter.(bson.M)
+	record, exists := s.records[f["_id"].(string)]
+	return record, exists && record.CreatedAt.Equal(f["created_at"].(time.Time))
+}
+
+func (s *fakeIdempotencyStore) Update(ctx context.Context, collection string, filter, update interface{}) error {
+	record, ok := s.owned(filter)
+	if !ok {
+		return nil
+	}
+	set := update.(bson.M)["$set"].(bson.M)
+	record.Completed = set["completed"].(bool)
+	record.Status = set["status"].(int)
+	record.ContentType = set["content_type"].(string)
+	record.Body = set["body"].([]byte)
+	record.ExpiresAt = set["expires_at"].(time.Time)
+	s.records[record.ID] = record
+	return nil
+}
+
+func (s *fakeIdempotencyStore) Delete(ctx context.Context, collection string, filter interface{}) error {
+	if record, ok := s.owned(filter); ok {
+		delete(s.records, record.ID)
+	}
+	return nil
+}
+
+type idempotentRequest struct {
+	user string
+	path string
+	key  string
+	body string
+}
+
+func TestIdempotency(t *testing.T) {
+	first := idempotentRequest{user: "u1", path: "/orders/1", key: "k1", body: `{"qty":1}`}
+	with := func(change func(*idempotentRequest)) idempotentRequest {
+		r := first
+		change(&r)
+		return r
+	}
+
+	tests := []struct {
+		name string
+		// seed is stored before the requests are sent.
+		seed         *idempotencyRecord
+		failFirst    bool
+		requests     []idempotentRequest
+		wantStatus   []int
+		wantReplayed []bool
+		wantCalls    int
+	}{
+		{"repeat is replayed", nil, false, []idempotentRequest{first, first}, []int{201, 201}, []bool{false, true}, 1},
+		{"different body is refused", nil, false, []idempotentRequest{first, with(func(r *idempotentRequest) { r.body = `{"qty":2}` })}, []int{201, 422}, []bool{false, false}, 1},
+		{"different path is refused", nil, false, []idempotentRequest{first, with(func(r *idempotentRequest) { r.path = "/orders/2" })}, []int{201, 422}, []bool{false, false}, 1},
+		{"keys belong to one user", nil, false, []idempotentRequest{first, with(func(r *idempotentRequest) { r.user = "u2" })}, []int{201, 201}, []bool{false, false}, 2},
+		{"no key is not idempotent", nil, false, []idempotentRequest{with(func(r *idempotentRequest) { r.key = "" }), with(func(r *idempotentRequest) { r.key = "" })}, []int{201, 201}, []bool{false, false}, 2},
+		{"anonymous requests are not idempotent", nil, false, []idempotentRequest{with(func(r *idempotentRequest) { r.user = "" }), with(func(r *idempotentRequest) { r.user = "" })}, []int{201, 201}, []bool{false, false}, 2},
+		{"overlong key is refused", nil, false, []idempotentRequest{with(func(r *idempotentRequest) { r.key = strings.Repeat("k", 256) })}, []int{400}, []bool{false}, 0},
+		{"server error releases the key", nil, true, []idempotentRequest{first, first}, []int{500, 201}, []bool{false, false}, 2},
+		{"request in progress", &idempotencyRecord{ID: "u1:k1", RequestHash: hashRequest(http.MethodPost, "/orders/1", []byte(`{"qty":1}`)), ExpiresAt: time.Now().Add(time.Minute)}, false, []idempotentRequest{first}, []int{409}, []bool{false}, 0},
+		{"expired lease is taken over", &idempotencyRecord{ID: "u1:k1", RequestHash: hashRequest(http.MethodPost, "/orders/1", []byte(`{"qty":1}`)), ExpiresAt: time.Now().Add(-time.Second)}, false, []idempotentRequest{first, first}, []int{201, 201}, []bool{false, true}, 1},
+		{"expired response is not replayed", &idempotencyRecord{ID: "u1:k1", RequestHash: "other", Completed: true, Status: 200, ExpiresAt: time.Now().Add(-time.Second)}, false, []idempotentRequest{first}, []int{201}, []bool{false}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeIdempotencyStore{records: map[string]idempotencyRecord{}}
+			if tt.seed != nil {
+				store.records[tt.seed.ID] = *tt.seed
+			}
+
+			calls := 0
+			engine := gin.New()
+			engine.POST("/orders/:id", func(c *gin.Context) {
+				if user := c.GetHeader("X-Test-User"); user != "" {
+					c.Set("userId", user)
+				}
+			}, Idempotency(store, time.Hour), func(c *gin.Context) {
+				calls++
+				if tt.failFirst && calls == 1 {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+					return
+				}
+				c.JSON(http.StatusCreated, gin.H{"call": calls})
+			})
+
+			var firstBody string
+			for i, r := range tt.requests {
+				req := httptest.NewRequest(http.MethodPost, r.path, strings.NewReader(r.body))
+				req.Header.Set("X-Test-User", r.user)
+				if r.key != "" {
+					req.Header.Set(IdempotencyKeyHeader, r.key)
+				}
+				res := httptest.NewRecorder()
+				engine.ServeHTTP(res, req)
+
+				if res.Code != tt.wantStatus[i] {
+					t.Fatalf("request %d: status %d, want %d (%s)", i, res.Code, tt.wantStatus[i], res.Body.String())
+				}
+				replayed := res.Header().Get(IdempotentReplayedHeader) == "true"
+				if replayed != tt.wantReplayed[i] {
+					t.Errorf("request %d: replayed %t, want %t", i, replayed, tt.wantReplayed[i])
+				}
+				if replayed && res.Body.String() != firstBody {
+					t.Errorf("request %d: replayed %s, want %s", i, res.Body.String(), firstBody)
+				}
+				if res.Code == http.StatusCreated && firstBody == "" {
+					firstBody = res.Body.String()
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler ran %d time(s), want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHashRequest(t *testing.T) {
+	base := hashRequest(http.MethodPost, "/orders/1", []byte(`{"qty":1}`))
+	if again := hashRequest(http.MethodPost, "/orders/1", []byte(`{"qty":1}`)); again != base {
+		t.Fatalf("hashRequest() is not stable: %s != %s", again, base)
+	}
+
+	tests := []struct {
+		method, route, body string
+	}{
+		{http.MethodPut, "/orders/1", `{"qty":1}`},
+		{http.MethodPost, "/orders/2", `{"qty":1}`},
+		{http.MethodPost, "/orders/1", `{"qty":2}`},
+		{http.MethodPost, "/orders/1", `{"qty":1} `},
+		{http.MethodPost, "/orders/1", ``},
+	}
+	for _, tt := range tests {
+		name := fmt.Sprintf("%s %s %q", tt.method, tt.route, tt.body)
+		if hashRequest(tt.method, tt.route, []byte(tt.body)) == base {
+			t.Errorf("hashRequest(%s) collides with the original request", name)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"github.com/devbenho/luka-platform/internal/utils"
+	"github.com/devbenho/luka-platform/pkg/rbac"
 	"github.com/gin-gonic/gin"
 )
 
-// OwnerAuth checks if the authenticated user is the owner of the resource
+// OwnerAuth checks if the authenticated user is the owner of the resource.
+// Admins may act on any resource.
 func OwnerAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the authenticated user's ID
@@ -25,7 +27,7 @@ func OwnerAuth() gin.HandlerFunc {
 		}
 
 		// Check if the authenticated user is the owner of the resource
-		if authUserID != resourceID {
+		if role, _ := c.Get("role"); authUserID != resourceID && role != rbac.RoleAdmin {
 			c.JSON(403, utils.NewForbiddenResponse("You can only modify your own data"))
 			c.Abort()
 			return
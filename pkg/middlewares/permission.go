@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/devbenho/luka-platform/internal/utils"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission lets the request through only when the role of the
+// authenticated user grants every one of permissions. The role is the one of
+// the validated token, so it must come after JWTAuth.
+func RequirePermission(permissions ...rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, role, ok := CurrentUser(c)
+		if !ok {
+			c.JSON(401, utils.NewUnauthorizedResponse("User not authenticated"))
+			c.Abort()
+			return
+		}
+
+		if !rbac.Can(role, permissions...) {
+			c.JSON(403, utils.NewForbiddenResponse("You are not allowed to perform this action"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
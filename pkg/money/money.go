@@ -0,0 +1,291 @@
+// Package money represents monetary amounts as integer minor units (cents,
+// pence, ...) tagged with an ISO 4217 currency code, so that prices and totals
+// never drift through floating point rounding.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrUnknownCurrency  = errors.New("money: unknown currency")
+	ErrInvalidAmount    = errors.New("money: invalid amount")
+	ErrOverflow         = errors.New("money: amount out of range")
+	ErrDivisionByZero   = errors.New("money: division by zero")
+)
+
+// exponents lists the number of minor unit digits of the supported ISO 4217
+// currencies.
+var exponents = map[string]int{
+	"AED": 2, "AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2,
+	"DKK": 2, "DZD": 2, "EGP": 2, "EUR": 2, "GBP": 2, "HKD": 2, "INR": 2,
+	"JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3, "MAD": 2, "MXN": 2, "NGN": 2,
+	"NOK": 2, "NZD": 2, "OMR": 3, "PLN": 2, "QAR": 2, "SAR": 2, "SEK": 2,
+	"SGD": 2, "TND": 3, "TRY": 2, "USD": 2, "ZAR": 2,
+}
+
+// Money is an amount in the minor unit of its currency. The zero value has no
+// currency and is only valid as a placeholder.
+type Money struct {
+	Amount   int64  `bson:"amount"`
+	Currency string `bson:"currency"`
+}
+
+// New returns an amount of minor units in the given currency.
+func New(amount int64, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	if _, ok := exponents[currency]; !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Zero returns a zero amount in the given currency.
+func Zero(currency string) (Money, error) {
+	return New(0, currency)
+}
+
+// Parse reads a decimal amount in major units such as "12.34". It rejects
+// amounts with more decimals than the currency has minor unit digits instead
+// of silently rounding them.
+func Parse(amount, currency string) (Money, error) {
+	m, err := New(0, currency)
+	if err != nil {
+		return Money{}, err
+	}
+	exp := exponents[m.Currency]
+
+	s := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" || len(frac) > exp || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	digits := whole + frac + strings.Repeat("0", exp-len(frac))
+
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok || !value.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	m.Amount = value.Int64()
+	if negative {
+		m.Amount = -m.Amount
+	}
+	return m, nil
+}
+
+// Validate reports whether m carries a supported currency.
+func (m Money) Validate() error {
+	if _, ok := exponents[m.Currency]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownCurrency, m.Currency)
+	}
+	return nil
+}
+
+// Zero returns a zero amount in m's currency.
+func (m Money) Zero() Money {
+	return Money{Currency: m.Currency}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// SameCurrency reports whether m and o can be combined.
+func (m Money) SameCurrency(o Money) bool {
+	return m.Currency == o.Currency
+}
+
+// Add returns m + o. Amounts in different currencies cannot be added. A zero
+// value without currency adopts the currency of the other operand, so sums
+// can start from Money{}. Sums beyond the range of int64 fail with
+// ErrOverflow.
+func (m Money) Add(o Money) (Money, error) {
+	m, o, err := align(m, o)
+	if err != nil {
+		return Money{}, err
+	}
+	if (o.Amount > 0 && m.Amount > math.MaxInt64-o.Amount) || (o.Amount < 0 && m.Amount < math.MinInt64-o.Amount) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, o)
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o under the same rules as Add.
+func (m Money) Sub(o Money) (Money, error) {
+	m, o, err := align(m, o)
+	if err != nil {
+		return Money{}, err
+	}
+	if (o.Amount < 0 && m.Amount > math.MaxInt64+o.Amount) || (o.Amount > 0 && m.Amount < math.MinInt64+o.Amount) {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrOverflow, m, o)
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+// Mul returns m multiplied by a whole quantity, failing with ErrOverflow when
+// the product does not fit.
+func (m Money) Mul(quantity int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s * %d", ErrOverflow, m, quantity)
+	}
+	return Money{Amount: product.Int64(), Currency: m.Currency}, nil
+}
+
+// MulFraction returns m * numerator / denominator, rounded to the nearest
+// minor unit with halves rounded away from zero. It is meant for rates such
+// as taxes and discounts, e.g. MulFraction(1450, 10000) for 14.5%. A zero
+// denominator fails with ErrDivisionByZero and a result out of range with
+// ErrOverflow.
+func (m Money) MulFraction(numerator, denominator int64) (Money, error) {
+	if denominator == 0 {
+		return Money{}, fmt.Errorf("%w: %s * %d / 0", ErrDivisionByZero, m, numerator)
+	}
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(numerator))
+	den := big.NewInt(denominator)
+	if denominator < 0 {
+		product.Neg(product)
+		den.Neg(den)
+	}
+
+	quotient, remainder := new(big.Int).QuoRem(product, den, new(big.Int))
+	remainder.Abs(remainder).Mul(remainder, big.NewInt(2))
+	if remainder.Cmp(den) >= 0 {
+		if product.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	if !quotient.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s * %d / %d", ErrOverflow, m, numerator, denominator)
+	}
+	return Money{Amount: quotient.Int64(), Currency: m.Currency}, nil
+}
+
+// Cmp compares m and o, returning -1, 0 or +1.
+func (m Money) Cmp(o Money) (int, error) {
+	m, o, err := align(m, o)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Min returns the smaller of m and o.
+func Min(m, o Money) (Money, error) {
+	cmp, err := m.Cmp(o)
+	if err != nil {
+		return Money{}, err
+	}
+	if cmp > 0 {
+		return o, nil
+	}
+	return m, nil
+}
+
+// Sum adds up amounts that must all share one currency.
+func Sum(amounts ...Money) (Money, error) {
+	var total Money
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Decimal formats the amount in major units, e.g. "12.34".
+func (m Money) Decimal() string {
+	exp := exponents[m.Currency]
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(big.NewInt(amount)).String()
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+type jsonMoney struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// MarshalJSON encodes m as {"amount": "12.34", "currency": "USD"}. The amount
+// is a string so clients never parse it into a float.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.Currency})
+}
+
+// UnmarshalJSON accepts the amount in major units as either a string or a
+// number.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var raw jsonMoney
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	parsed, err := Parse(raw.Amount.String(), raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func align(m, o Money) (Money, Money, error) {
+	switch {
+	case m.Currency == o.Currency:
+	case m.Currency == "" && m.Amount == 0:
+		m.Currency = o.Currency
+	case o.Currency == "" && o.Amount == 0:
+		o.Currency = m.Currency
+	default:
+		return m, o, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return m, o, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func usd(amount int64) Money {
+	return Money{Amount: amount, Currency: "USD"}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     Money
+		err      error
+	}{
+		{"12.34", "usd", usd(1234), nil},
+		{"12.3", "USD", usd(1230), nil},
+		{"12", "USD", usd(1200), nil},
+		{"-0.05", "USD", usd(-5), nil},
+		{"1000", "JPY", Money{Amount: 1000, Currency: "JPY"}, nil},
+		{"1.234", "KWD", Money{Amount: 1234, Currency: "KWD"}, nil},
+		{"1.234", "USD", Money{}, ErrInvalidAmount},
+		{"1.5", "JPY", Money{}, ErrInvalidAmount},
+		{"", "USD", Money{}, ErrInvalidAmount},
+		{"1e3", "USD", Money{}, ErrInvalidAmount},
+		{"99999999999999999999", "USD", Money{}, ErrInvalidAmount},
+		{"1", "XXX", Money{}, ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.amount, tt.currency)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("Parse(%q, %q) = %v, %v; want %v, %v", tt.amount, tt.currency, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(Money, Money) (Money, error)
+		a, b Money
+		want Money
+		err  error
+	}{
+		{"add", Money.Add, usd(150), usd(250), usd(400), nil},
+		{"add to currencyless zero", Money.Add, Money{}, usd(5), usd(5), nil},
+		{"add currencies", Money.Add, usd(1), Money{Amount: 1, Currency: "EUR"}, Money{}, ErrCurrencyMismatch},
+		{"add overflow", Money.Add, usd(math.MaxInt64), usd(1), Money{}, ErrOverflow},
+		{"add underflow", Money.Add, usd(math.MinInt64), usd(-1), Money{}, ErrOverflow},
+		{"add to the limit", Money.Add, usd(math.MaxInt64 - 1), usd(1), usd(math.MaxInt64), nil},
+		{"sub", Money.Sub, usd(250), usd(300), usd(-50), nil},
+		{"sub currencies", Money.Sub, usd(1), Money{Amount: 1, Currency: "EUR"}, Money{}, ErrCurrencyMismatch},
+		{"sub overflow", Money.Sub, usd(math.MaxInt64), usd(-1), Money{}, ErrOverflow},
+		{"sub underflow", Money.Sub, usd(math.MinInt64), usd(1), Money{}, ErrOverflow},
+		{"sub to the limit", Money.Sub, usd(math.MinInt64 + 1), usd(1), usd(math.MinInt64), nil},
+	}
+	for _, tt := range tests {
+		got, err := tt.op(tt.a, tt.b)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("%s: got %v, %v; want %v, %v", tt.name, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		m        Money
+		quantity int64
+		want     Money
+		err      error
+	}{
+		{usd(199), 3, usd(597), nil},
+		{usd(199), 0, usd(0), nil},
+		{usd(-199), 2, usd(-398), nil},
+		{usd(math.MaxInt64 / 2), 3, Money{}, ErrOverflow},
+		{usd(math.MinInt64), -1, Money{}, ErrOverflow},
+	}
+	for _, tt := range tests {
+		got, err := tt.m.Mul(tt.quantity)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("%v.Mul(%d) = %v, %v; want %v, %v", tt.m, tt.quantity, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestMulFraction(t *testing.T) {
+	tests := []struct {
+		m           Money
+		numerator   int64
+		denominator int64
+		want        Money
+		err         error
+	}{
+		{usd(1000), 1450, 10000, usd(145), nil},
+		{usd(5), 1, 2, usd(3), nil},
+		{usd(-5), 1, 2, usd(-3), nil},
+		{usd(4), 1, 3, usd(1), nil},
+		{usd(5), 1, -2, usd(-3), nil},
+		{usd(1000), 1, 0, Money{}, ErrDivisionByZero},
+		{usd(math.MaxInt64), 3, 2, Money{}, ErrOverflow},
+		{usd(math.MaxInt64), 3, 3, usd(math.MaxInt64), nil},
+	}
+	for _, tt := range tests {
+		got, err := tt.m.MulFraction(tt.numerator, tt.denominator)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("%v.MulFraction(%d, %d) = %v, %v; want %v, %v", tt.m, tt.numerator, tt.denominator, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestCmpAndMin(t *testing.T) {
+	if cmp, err := usd(1).Cmp(usd(2)); err != nil || cmp != -1 {
+		t.Errorf("Cmp = %d, %v; want -1", cmp, err)
+	}
+	if _, err := usd(1).Cmp(Money{Amount: 1, Currency: "EUR"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Cmp across currencies = %v; want ErrCurrencyMismatch", err)
+	}
+	if got, err := Min(usd(7), usd(3)); err != nil || got != usd(3) {
+		t.Errorf("Min = %v, %v; want %v", got, err, usd(3))
+	}
+}
+
+func TestSum(t *testing.T) {
+	if got, err := Sum(usd(1), usd(2), usd(3)); err != nil || got != usd(6) {
+		t.Errorf("Sum = %v, %v; want %v", got, err, usd(6))
+	}
+	if _, err := Sum(usd(math.MaxInt64), usd(1)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Sum past the limit = %v; want ErrOverflow", err)
+	}
+}
+
+func TestZero(t *testing.T) {
+	if got := usd(1234).Zero(); got != usd(0) {
+		t.Errorf("Zero() = %v; want %v", got, usd(0))
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{usd(1234), "12.34"},
+		{usd(5), "0.05"},
+		{usd(-5), "-0.05"},
+		{Money{Amount: 1000, Currency: "JPY"}, "1000"},
+		{Money{Amount: 1, Currency: "KWD"}, "0.001"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Decimal(); got != tt.want {
+			t.Errorf("%#v.Decimal() = %q; want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	data, err := json.Marshal(usd(1234))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"amount":"12.34","currency":"USD"}` {
+		t.Fatalf("Marshal = %s", data)
+	}
+
+	var got Money
+	if err := json.Unmarshal(data, &got); err != nil || got != usd(1234) {
+		t.Fatalf("Unmarshal = %v, %v; want %v", got, err, usd(1234))
+	}
+	if err := json.Unmarshal([]byte(`{"amount":12.5,"currency":"USD"}`), &got); err != nil || got != usd(1250) {
+		t.Fatalf("Unmarshal number = %v, %v; want %v", got, err, usd(1250))
+	}
+	if err := json.Unmarshal([]byte(`{"amount":"1.001","currency":"USD"}`), &got); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("Unmarshal too precise = %v; want ErrInvalidAmount", err)
+	}
+}
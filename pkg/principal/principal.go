@@ -0,0 +1,34 @@
+// Package principal carries the authenticated user of a request through its
+// context, so that services can authorize what they are asked to do.
+package principal
+
+import (
+	"context"
+
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Principal is the user a request acts for.
+type Principal struct {
+	UserID primitive.ObjectID
+	Role   string
+}
+
+// IsAdmin reports whether the principal may act on any user's resources.
+func (p Principal) IsAdmin() bool {
+	return p.Role == rbac.RoleAdmin
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal ctx carries, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
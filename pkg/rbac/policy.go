@@ -0,0 +1,91 @@
+package rbac
+
+// Permissions routes require.
+const (
+	// Any stands for every permission, present and future.
+	Any Permission = "*"
+
+	UsersRead  Permission = "users:read"
+	UsersWrite Permission = "users:write"
+
+	StoresRead     Permission = "stores:read"
+	StoresWrite    Permission = "stores:write"
+	CategoriesRead Permission = "categories:read"
+	// CategoriesWrite edits the catalogue tree every store shares.
+	CategoriesWrite Permission = "categories:write"
+	ProductsRead    Permission = "products:read"
+	ProductsWrite   Permission = "products:write"
+	InventoryRead   Permission = "inventory:read"
+	InventoryWrite  Permission = "inventory:write"
+	WarehousesRead  Permission = "warehouses:read"
+	WarehousesWrite Permission = "warehouses:write"
+	ShippingWrite   Permission = "shipping:write"
+	AnalyticsRead   Permission = "analytics:read"
+
+	OrdersCreate Permission = "orders:create"
+	OrdersRead   Permission = "orders:read"
+	OrdersCancel Permission = "orders:cancel"
+	// OrdersUpdateStatus moves an order through fulfilment.
+	OrdersUpdateStatus Permission = "orders:update_status"
+	OrdersShip         Permission = "orders:ship"
+	InvoicesRead       Permission = "invoices:read"
+	PaymentsCreate     Permission = "payments:create"
+	PaymentsRead       Permission = "payments:read"
+	// PaymentsManage captures, refunds and voids payments.
+	PaymentsManage Permission = "payments:manage"
+	ReturnsCreate  Permission = "returns:create"
+	ReturnsRead    Permission = "returns:read"
+	// ReturnsManage approves, rejects and receives returns.
+	ReturnsManage Permission = "returns:manage"
+
+	// Coupons and tax rules apply across stores, so only admins write them.
+	CouponsRead  Permission = "coupons:read"
+	CouponsWrite Permission = "coupons:write"
+	TaxesRead    Permission = "taxes:read"
+	TaxesWrite   Permission = "taxes:write"
+)
+
+// catalogueReader can browse what stores sell.
+var catalogueReader = []Permission{
+	UsersRead, UsersWrite,
+	StoresRead, CategoriesRead, ProductsRead,
+}
+
+// policies grants each role its permissions. A permission allows the kind of
+// operation only; whether the user may touch a given resource, such as a
+// store they do not own, is checked by the services.
+var policies = map[string]map[Permission]bool{
+	RoleBuyer: grant(catalogueReader,
+		OrdersCreate, OrdersRead, OrdersCancel,
+		InvoicesRead,
+		PaymentsCreate, PaymentsRead,
+		ReturnsCreate, ReturnsRead,
+		CouponsRead,
+	),
+	RoleSeller: grant(catalogueReader,
+		StoresWrite, ProductsWrite,
+		InventoryRead, InventoryWrite,
+		WarehousesRead, WarehousesWrite,
+		ShippingWrite, AnalyticsRead,
+		OrdersRead, OrdersCancel, OrdersUpdateStatus, OrdersShip,
+		InvoicesRead,
+		PaymentsRead, PaymentsManage,
+		ReturnsRead, ReturnsManage,
+		CouponsRead, TaxesRead,
+	),
+	RoleSupplier: grant(catalogueReader,
+		InventoryRead, WarehousesRead,
+	),
+	RoleAdmin: grant(nil, Any),
+}
+
+func grant(base []Permission, permissions ...Permission) map[Permission]bool {
+	granted := make(map[Permission]bool, len(base)+len(permissions))
+	for _, permission := range base {
+		granted[permission] = true
+	}
+	for _, permission := range permissions {
+		granted[permission] = true
+	}
+	return granted
+}
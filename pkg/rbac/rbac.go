@@ -0,0 +1,35 @@
+// Package rbac decides what each role may do. Roles are granted permissions
+// in policies, and routes require the permissions their operation needs.
+package rbac
+
+// Roles a user can have.
+const (
+	RoleBuyer    = "buyer"
+	RoleSeller   = "seller"
+	RoleSupplier = "supplier"
+	RoleAdmin    = "admin"
+)
+
+// Permission allows one kind of operation on one kind of resource, written
+// "resource:action".
+type Permission string
+
+// IsRole reports whether role is one users can have.
+func IsRole(role string) bool {
+	_, ok := policies[role]
+	return ok
+}
+
+// Can reports whether role grants every one of permissions.
+func Can(role string, permissions ...Permission) bool {
+	granted, ok := policies[role]
+	if !ok {
+		return false
+	}
+	for _, permission := range permissions {
+		if !granted[permission] && !granted[Any] {
+			return false
+		}
+	}
+	return true
+}
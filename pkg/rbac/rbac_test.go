@@ -0,0 +1,58 @@
+package rbac
+
+import "testing"
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        string
+		permissions []Permission
+		want        bool
+	}{
+		{"buyer places orders", RoleBuyer, []Permission{OrdersCreate, PaymentsCreate}, true},
+		{"buyer reads own payments", RoleBuyer, []Permission{PaymentsRead}, true},
+		{"buyer cannot manage payments", RoleBuyer, []Permission{PaymentsManage}, false},
+		{"buyer cannot write inventory", RoleBuyer, []Permission{InventoryWrite}, false},
+		{"seller writes inventory", RoleSeller, []Permission{InventoryRead, InventoryWrite}, true},
+		{"seller manages returns", RoleSeller, []Permission{ReturnsManage}, true},
+		{"seller cannot edit the catalogue tree", RoleSeller, []Permission{CategoriesWrite}, false},
+		{"seller cannot write coupons", RoleSeller, []Permission{CouponsWrite}, false},
+		{"seller cannot write taxes", RoleSeller, []Permission{TaxesWrite}, false},
+		{"seller cannot place orders", RoleSeller, []Permission{OrdersCreate}, false},
+		{"supplier reads inventory", RoleSupplier, []Permission{InventoryRead}, true},
+		{"supplier cannot write inventory", RoleSupplier, []Permission{InventoryWrite}, false},
+		{"every permission is needed", RoleSupplier, []Permission{InventoryRead, InventoryWrite}, false},
+		{"admin has every permission", RoleAdmin, []Permission{CouponsWrite, TaxesWrite, CategoriesWrite}, true},
+		{"admin has permissions added later", RoleAdmin, []Permission{"reports:export"}, true},
+		{"unknown role has none", "guest", []Permission{ProductsRead}, false},
+		{"unknown role is denied with nothing required", "guest", nil, false},
+		{"no role has none", "", []Permission{ProductsRead}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Can(tt.role, tt.permissions...); got != tt.want {
+				t.Errorf("Can(%q, %v) = %t, want %t", tt.role, tt.permissions, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRole(t *testing.T) {
+	tests := []struct {
+		role string
+		want bool
+	}{
+		{RoleBuyer, true},
+		{RoleSeller, true},
+		{RoleSupplier, true},
+		{RoleAdmin, true},
+		{"system", false},
+		{"Admin", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsRole(tt.role); got != tt.want {
+			t.Errorf("IsRole(%q) = %t, want %t", tt.role, got, tt.want)
+		}
+	}
+}
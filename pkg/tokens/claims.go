@@ -0,0 +1,38 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims are the claims of an access token. The subject is the ID of the user
+// the token was issued to.
+type Claims struct {
+	Role     string `json:"role"`
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the ID of the user the token was issued to.
+func (c *Claims) UserID() string {
+	return c.Subject
+}
+
+// Validate checks the claims the registered ones leave out. The parser calls
+// it after checking expiry, issuer and audience.
+func (c *Claims) Validate() error {
+	if c.Subject == "" {
+		return errors.New("token has no subject")
+	}
+	if c.ID == "" {
+		return errors.New("token has no ID")
+	}
+	if !rbac.IsRole(c.Role) {
+		return fmt.Errorf("token has unknown role %q", c.Role)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms of access tokens.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const rsaKeyBits = 2048
+
+// SigningKey is a private key tokens are signed with, identified in their
+// "kid" header. A key is published in the JWKS as soon as it is known but only
+// signs from ActivatesAt on, which gives verifiers time to fetch it.
+type SigningKey struct {
+	ID          string
+	Algorithm   string
+	PrivateKey  crypto.Signer
+	ActivatesAt time.Time
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// keyRing orders keys by activation. The signing key is the last one active;
+// a key it replaced still verifies until the tokens it signed have expired.
+type keyRing []*SigningKey
+
+func newKeyRing(keys []*SigningKey) keyRing {
+	ring := append(keyRing{}, keys...)
+	sort.Slice(ring, func(i, j int) bool {
+		if ring[i].ActivatesAt.Equal(ring[j].ActivatesAt) {
+			return ring[i].ID < ring[j].ID
+		}
+		return ring[i].ActivatesAt.Before(ring[j].ActivatesAt)
+	})
+	return ring
+}
+
+func (r keyRing) signingKey(now time.Time) *SigningKey {
+	var signing *SigningKey
+	for _, key := range r {
+		if key.ActivatesAt.After(now) {
+			break
+		}
+		signing = key
+	}
+	return signing
+}
+
+// verifies reports whether tokens signed with the i-th key are still accepted.
+func (r keyRing) verifies(i int, now time.Time, tokenTTL time.Duration) bool {
+	if r[i].ActivatesAt.After(now) {
+		return false
+	}
+	return i == len(r)-1 || now.Before(r[i+1].ActivatesAt.Add(tokenTTL))
+}
+
+func (r keyRing) verificationKey(id string, now time.Time, tokenTTL time.Duration) *SigningKey {
+	for i, key := range r {
+		if key.ID == id && r.verifies(i, now, tokenTTL) {
+			return key
+		}
+	}
+	return nil
+}
+
+// published returns the keys verifiers should know: those that verify now and
+// those about to sign.
+func (r keyRing) published(now time.Time, tokenTTL time.Duration) []*SigningKey {
+	var keys []*SigningKey
+	for i, key := range r {
+		if key.ActivatesAt.After(now) || r.verifies(i, now, tokenTTL) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// generateKey creates a random key for the algorithm, named after the time it
+// activates.
+func generateKey(algorithm string, activatesAt time.Time) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s key: %w", algorithm, err)
+	}
+	suffix, err := randomString(4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key ID: %w", err)
+	}
+	return &SigningKey{
+		ID:          activatesAt.UTC().Format("20060102T150405Z") + "-" + suffix,
+		Algorithm:   algorithm,
+		PrivateKey:  private,
+		ActivatesAt: activatesAt,
+	}, nil
+}
+
+// loadKeys reads the PEM private keys of a directory. The file name without
+// its .pem extension is the key ID, and a key activates publishLead after the
+// file was last modified.
+func loadKeys(dir string, publishLead time.Duration) ([]*SigningKey, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	var keys []*SigningKey
+	for _, path := range paths {
+		key, err := loadKey(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat signing key %s: %w", path, err)
+		}
+		key.ActivatesAt = info.ModTime().Add(publishLead)
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing keys found in %s", dir)
+	}
+	return keys, nil
+}
+
+// loadKey reads an RSA or Ed25519 private key in PKCS #8, or an RSA key in
+// PKCS #1.
+func loadKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %w", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+	}
+
+	var parsed interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+
+	key := &SigningKey{ID: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < rsaKeyBits {
+			return nil, fmt.Errorf("signing key %s is shorter than %d bits", path, rsaKeyBits)
+		}
+		key.Algorithm, key.PrivateKey = AlgorithmRS256, private
+	case ed25519.PrivateKey:
+		key.Algorithm, key.PrivateKey = AlgorithmEdDSA, private
+	default:
+		return nil, fmt.Errorf("signing key %s is neither an RSA nor an Ed25519 key", path)
+	}
+	return key, nil
+}
@@ -1,57 +1,188 @@
 package tokens
 
 import (
-	"log"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	config "github.com/devbenho/luka-platform/configs"
-	"github.com/devbenho/luka-platform/internal/utils"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTPayload represents the payload stored in a JWT token
-type JWTPayload struct {
-	userId string
-	role   string
-	email  string
-}
+// ErrNoSigningKey is returned when no key is active to sign tokens with.
+var ErrNoSigningKey = errors.New("no active signing key")
 
-// TokenService provides methods for generating and parsing JWT tokens
+// TokenService provides methods for generating and parsing JWT tokens. Tokens
+// are signed with asymmetric keys, either read from a directory or generated
+// in memory for development, and rotated on a schedule: a new key is
+// published some time before it starts signing, and a replaced key keeps
+// verifying until the last token it signed has expired.
 type TokenService struct {
-	secret string
+	mu   sync.RWMutex
+	keys keyRing
+
+	algorithm   string
+	keysDir     string
+	issuer      string
+	audience    string
+	clockSkew   time.Duration
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	rotation    time.Duration
+	publishLead time.Duration
 }
 
-// NewTokenService creates a new TokenService with the given secret key
-func NewTokenService(secret string) *TokenService {
-	return &TokenService{
-		secret: secret,
+// NewTokenService creates a TokenService with the keys of cfg.JWT.KeysDir, or
+// with a generated key when no directory is configured outside production.
+func NewTokenService(cfg config.Config) (*TokenService, error) {
+	s := &TokenService{
+		algorithm:   cfg.JWT.Algorithm,
+		keysDir:     cfg.JWT.KeysDir,
+		issuer:      cfg.JWT.Issuer,
+		audience:    cfg.JWT.Audience,
+		clockSkew:   cfg.JWT.ClockSkew,
+		accessTTL:   cfg.JWT.AccessTTL,
+		refreshTTL:  cfg.JWT.RefreshTTL,
+		rotation:    cfg.JWT.KeyRotation,
+		publishLead: cfg.JWT.KeyPublishLead,
+	}
+
+	if s.keysDir == "" {
+		if cfg.App.Environment == "production" {
+			return nil, fmt.Errorf("JWT_KEYS_DIR is required in production")
+		}
+		// Generated keys only live as long as the process, so the first
+		// one signs right away.
+		key, err := generateKey(s.algorithm, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		s.keys = newKeyRing([]*SigningKey{key})
+		return s, nil
+	}
+
+	if err := s.Rotate(); err != nil {
+		return nil, err
 	}
+	return s, nil
 }
-func GenerateAccessToken(payload map[string]interface{}) string {
-	cfg := config.GetConfig()
-	payload["type"] = "access"
-	tokenContent := jwt.MapClaims{
-		"payload": payload,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+
+// RotationInterval is how often Rotate should run: the configured rotation
+// period for generated keys, and a reload every minute for keys from files.
+func (s *TokenService) RotationInterval() time.Duration {
+	if s.keysDir != "" {
+		return time.Minute
+	}
+	return s.rotation
+}
+
+// Rotate reloads the key directory or, for generated keys, publishes a new key
+// that starts signing after the publish lead. Keys that no longer verify any
+// token are dropped.
+func (s *TokenService) Rotate() error {
+	now := time.Now()
+	if s.keysDir != "" {
+		keys, err := loadKeys(s.keysDir, s.publishLead)
+		if err != nil {
+			return err
+		}
+		// The oldest key signs even before its lead has passed, so that a
+		// fresh deployment does not wait for its first key.
+		ring := newKeyRing(keys)
+		if ring[0].ActivatesAt.After(now) {
+			ring[0].ActivatesAt = now
+		}
+		s.mu.Lock()
+		s.keys = ring
+		s.mu.Unlock()
+		return nil
 	}
-	jwtToken := jwt.NewWithClaims(jwt.GetSigningMethod("HS256"), tokenContent)
-	token, err := jwtToken.SignedString([]byte(cfg.JWT.Secret))
+
+	key, err := generateKey(s.algorithm, now.Add(s.publishLead))
 	if err != nil {
-		log.Fatal("Failed to generate access token: ", err)
-		return ""
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = newKeyRing(append(s.keys.published(now, s.accessTTL), key))
+	return nil
+}
+
+// JWKS returns the public keys that verify our tokens, including the next key
+// to sign.
+func (s *TokenService) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range s.keys.published(time.Now(), s.accessTTL) {
+		jwks.Keys = append(jwks.Keys, publicJWK(key))
 	}
+	return jwks
+}
 
-	return token
+// AccessToken is a signed access token with the ID and expiry it was issued
+// with, so that it can be revoked before it expires.
+type AccessToken struct {
+	Token     string
+	ID        string
+	ExpiresAt time.Time
 }
 
-func ValidateToken(jwtToken string) (map[string]interface{}, error) {
-	cfg := config.GetConfig()
-	cleanJWT := strings.Replace(jwtToken, "Bearer ", "", -1)
-	tokenData := jwt.MapClaims{}
-	token, err := jwt.ParseWithClaims(cleanJWT, tokenData, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWT.Secret), nil
-	})
+// GenerateAccessToken signs a short-lived access token for the user the claims
+// name. The service sets the registered claims other than the subject; each
+// token gets a unique ID (jti) by which it can be revoked.
+func (s *TokenService) GenerateAccessToken(claims Claims) (*AccessToken, error) {
+	now := time.Now()
+	s.mu.RLock()
+	key := s.keys.signingKey(now)
+	s.mu.RUnlock()
+	if key == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	id, err := randomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	claims.ID = id
+	claims.Issuer = s.issuer
+	claims.Audience = jwt.ClaimStrings{s.audience}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(s.accessTTL))
+	if err := claims.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	jwtToken := jwt.NewWithClaims(key.method(), &claims)
+	jwtToken.Header["kid"] = key.ID
+	token, err := jwtToken.SignedString(key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return &AccessToken{Token: token, ID: id, ExpiresAt: claims.ExpiresAt.Time}, nil
+}
+
+// ValidateToken validates an access token, with or without its "Bearer "
+// prefix, and returns its claims. Clocks may disagree by the configured skew.
+func (s *TokenService) ValidateToken(jwtToken string) (*Claims, error) {
+	cleanJWT := strings.TrimPrefix(jwtToken, "Bearer ")
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(cleanJWT, claims, s.verificationKey,
+		jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithAudience(s.audience),
+		jwt.WithLeeway(s.clockSkew),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired())
 
 	if err != nil {
 		return nil, err
@@ -61,8 +192,83 @@ func ValidateToken(jwtToken string) (map[string]interface{}, error) {
 		return nil, jwt.ErrInvalidKey
 	}
 
-	var data map[string]interface{}
-	utils.Copy(&data, tokenData["payload"])
+	return claims, nil
+}
+
+// verificationKey finds the public key named by the token's kid header.
+func (s *TokenService) verificationKey(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	s.mu.RLock()
+	key := s.keys.verificationKey(id, time.Now(), s.accessTTL)
+	s.mu.RUnlock()
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", id)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("signing key %q does not sign with %s", id, token.Method.Alg())
+	}
+	return key.PrivateKey.Public(), nil
+}
+
+// RefreshToken is a new opaque refresh token and the hash under which it is
+// stored. The token itself is only ever given to the client.
+type RefreshToken struct {
+	Token     string
+	Hash      string
+	ExpiresAt time.Time
+}
+
+func (s *TokenService) GenerateRefreshToken() (*RefreshToken, error) {
+	token, err := randomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return &RefreshToken{
+		Token:     token,
+		Hash:      HashRefreshToken(token),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}, nil
+}
 
-	return data, nil
+var (
+	defaultMu      sync.RWMutex
+	defaultService *TokenService
+)
+
+// SetDefault makes service the one ValidateToken uses, as the JWT
+// middlewares do. It must be called before requests are served.
+func SetDefault(service *TokenService) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultService = service
+}
+
+// Default returns the service set with SetDefault.
+func Default() *TokenService {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultService
+}
+
+// ValidateToken validates an access token with the default service.
+func ValidateToken(jwtToken string) (*Claims, error) {
+	service := Default()
+	if service == nil {
+		return nil, ErrNoSigningKey
+	}
+	return service.ValidateToken(jwtToken)
+}
+
+// HashRefreshToken returns the hash a refresh token is looked up by.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
 }
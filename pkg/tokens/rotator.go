@@ -0,0 +1,44 @@
+package tokens
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// KeyRotator periodically rotates the signing keys of a TokenService.
+type KeyRotator struct {
+	service  *TokenService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewKeyRotator(service *TokenService, logger *zap.Logger) *KeyRotator {
+	return &KeyRotator{
+		service:  service,
+		interval: service.RotationInterval(),
+		logger:   logger,
+	}
+}
+
+// Run rotates until ctx is cancelled. It is meant to be started in its own
+// goroutine, and returns at once when rotation is disabled.
+func (r *KeyRotator) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.service.Rotate(); err != nil {
+				r.logger.Error("rotating signing keys", zap.Error(err))
+			}
+		}
+	}
+}
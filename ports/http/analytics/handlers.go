@@ -0,0 +1,72 @@
+package analytics
+
+import (
+	"net/http"
+
+	"github.com/devbenho/luka-platform/internal/analytics/dtos"
+	"github.com/devbenho/luka-platform/internal/analytics/services"
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/internal/utils"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	errors "github.com/devbenho/luka-platform/ports/http/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	service services.IAnalyticsService
+}
+
+func NewAnalyticsHandler(service services.IAnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service: service,
+	}
+}
+
+// @Summary Get store analytics
+// @Description Get the sales dashboard of a store: revenue and orders over time, top products, average order value, cancellation rate and stock alerts
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Store ID"
+// @Param from query string false "Start of the range (RFC 3339), defaults to 30 days before to"
+// @Param to query string false "End of the range (RFC 3339), defaults to now"
+// @Param interval query string false "Sales bucket size" Enums(day, week, month)
+// @Param top query int false "Number of top products, 1 to 50 (default 10)"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Security BearerAuth
+// @Router /stores/{id}/analytics [get]
+func (h *AnalyticsHandler) GetStoreAnalytics(c *gin.Context) {
+	storeID := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var storeAnalyticsQuery dtos.StoreAnalyticsQuery
+	if err := c.ShouldBindQuery(&storeAnalyticsQuery); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.GetStoreAnalytics(c.Request.Context(), storeID, actor, storeAnalyticsQuery)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Store analytics fetched successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+func actorFromContext(c *gin.Context) (orderModels.Actor, bool) {
+	userID, role, ok := middleware.CurrentUser(c)
+	if !ok {
+		return orderModels.Actor{}, false
+	}
+	actor, err := orderModels.NewActor(userID, role)
+	return actor, err == nil
+}
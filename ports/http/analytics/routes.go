@@ -0,0 +1,22 @@
+package analytics
+
+import (
+	configs "github.com/devbenho/luka-platform/configs"
+	"github.com/devbenho/luka-platform/internal/analytics/repositories"
+	"github.com/devbenho/luka-platform/internal/analytics/services"
+	storeRepo "github.com/devbenho/luka-platform/internal/store/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config) {
+	analyticsRepository := repositories.NewAnalyticsRepository(mongoDb)
+	storeRepository := storeRepo.NewStoreRepository(mongoDb)
+	analyticsService := services.NewAnalyticsService(analyticsRepository, storeRepository, validator, config.Analytics.CacheTTL)
+	analyticsHandler := NewAnalyticsHandler(analyticsService)
+
+	r.GET("/stores/:id/analytics", middleware.JWTAuth(), middleware.RequirePermission(rbac.AnalyticsRead), analyticsHandler.GetStoreAnalytics)
+}
@@ -0,0 +1,197 @@
+package cart
+
+import (
+	"net/http"
+
+	"github.com/devbenho/luka-platform/internal/cart/dtos"
+	"github.com/devbenho/luka-platform/internal/cart/models"
+	"github.com/devbenho/luka-platform/internal/cart/services"
+	"github.com/devbenho/luka-platform/internal/utils"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	errors "github.com/devbenho/luka-platform/ports/http/errors"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CartTokenHeader carries the token of a guest cart.
+const CartTokenHeader = "X-Cart-Token"
+
+type CartHandler struct {
+	service services.ICartService
+}
+
+func NewCartHandler(service services.ICartService) *CartHandler {
+	return &CartHandler{
+		service: service,
+	}
+}
+
+// @Summary Get the cart
+// @Description Get the cart of the signed-in user, or of the guest identified by X-Cart-Token
+// @Tags cart
+// @Produce json
+// @Param X-Cart-Token header string false "Guest cart token"
+// @Success 200 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /cart [get]
+func (h *CartHandler) Get(c *gin.Context) {
+	owner, ok := ownerFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	result, err := h.service.GetCart(c.Request.Context(), owner)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Cart fetched successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Add an item to the cart
+// @Description Add a product to the cart. Guests without a token get a new cart whose guest_token must be sent back in X-Cart-Token
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param X-Cart-Token header string false "Guest cart token"
+// @Param item body dtos.AddCartItemRequest true "Product and quantity"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /cart/items [post]
+func (h *CartHandler) AddItem(c *gin.Context) {
+	owner, ok := ownerFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var addCartItemRequest dtos.AddCartItemRequest
+	if err := c.ShouldBindJSON(&addCartItemRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.AddItem(c.Request.Context(), owner, addCartItemRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Item added to cart", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Update a cart item
+// @Description Change the quantity of a product in the cart; zero removes it
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param X-Cart-Token header string false "Guest cart token"
+// @Param productId path string true "Product ID"
+// @Param item body dtos.UpdateCartItemRequest true "New quantity"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /cart/items/{productId} [patch]
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	owner, ok := ownerFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var updateCartItemRequest dtos.UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&updateCartItemRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.UpdateItem(c.Request.Context(), owner, c.Param("productId"), updateCartItemRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Cart item updated", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Remove a cart item
+// @Description Remove a product from the cart
+// @Tags cart
+// @Produce json
+// @Param X-Cart-Token header string false "Guest cart token"
+// @Param productId path string true "Product ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /cart/items/{productId} [delete]
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	owner, ok := ownerFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	result, err := h.service.RemoveItem(c.Request.Context(), owner, c.Param("productId"))
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Cart item removed", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Check out the cart
+// @Description Place the signed-in user's cart as an order and empty the cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param checkout body dtos.CheckoutCartRequest true "Shipping details"
+// @Param Idempotency-Key header string false "Key making retries of this request safe"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /cart/checkout [post]
+func (h *CartHandler) Checkout(c *gin.Context) {
+	owner, ok := ownerFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var checkoutCartRequest dtos.CheckoutCartRequest
+	if err := c.ShouldBindJSON(&checkoutCartRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.Checkout(c.Request.Context(), owner, checkoutCartRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusCreated, "Order created successfully", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// ownerFromContext identifies the cart owner: the signed-in user if any,
+// otherwise the guest token from the request header, which may be empty.
+func ownerFromContext(c *gin.Context) (models.Owner, bool) {
+	if userID, _, ok := middleware.CurrentUser(c); ok {
+		id, err := primitive.ObjectIDFromHex(userID)
+		return models.Owner{UserID: id}, err == nil
+	}
+	return models.Owner{GuestToken: c.GetHeader(CartTokenHeader)}, true
+}
@@ -0,0 +1,35 @@
+package cart
+
+import (
+	configs "github.com/devbenho/luka-platform/configs"
+	cartRepo "github.com/devbenho/luka-platform/internal/cart/repositories"
+	cartSvc "github.com/devbenho/luka-platform/internal/cart/services"
+	inventorySvc "github.com/devbenho/luka-platform/internal/inventory/services"
+	orderSvc "github.com/devbenho/luka-platform/internal/orders/services"
+	productSvc "github.com/devbenho/luka-platform/internal/product/services"
+	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config, productService productSvc.IProductService, inventoryService inventorySvc.IInventoryService, orderService orderSvc.IOrderService) {
+	// Initialize repositories
+	cartRepository := cartRepo.NewCartRepository(mongoDb)
+	// Initialize services
+	cartService := cartSvc.NewCartService(mongoDb, cartRepository, productService, inventoryService, orderService, validator)
+
+	// Initialize handler
+	cartHandler := NewCartHandler(cartService)
+
+	// Define routes
+	cartRoute := r.Group("/cart")
+	{
+		cartRoute.GET("/", middleware.OptionalJWTAuth(), cartHandler.Get)
+		cartRoute.POST("/items", middleware.OptionalJWTAuth(), cartHandler.AddItem)
+		cartRoute.PATCH("/items/:productId", middleware.OptionalJWTAuth(), cartHandler.UpdateItem)
+		cartRoute.DELETE("/items/:productId", middleware.OptionalJWTAuth(), cartHandler.RemoveItem)
+		cartRoute.POST("/checkout", middleware.JWTAuth(), middleware.RequirePermission(rbac.OrdersCreate), middleware.Idempotency(mongoDb, config.Idempotency.TTL), cartHandler.Checkout)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/devbenho/luka-platform/internal/category/services"
 	"github.com/devbenho/luka-platform/pkg/database"
 	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
 	"github.com/devbenho/luka-platform/pkg/validation"
 	"github.com/gin-gonic/gin"
 )
@@ -17,9 +18,9 @@ func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validatio
 
 	categoriesRoute := r.Group("/categories")
 	{
-		categoriesRoute.POST("/", middleware.JWTAuth(), categoryHandler.Create)
-		categoriesRoute.PATCH("/:id", middleware.JWTAuth(), categoryHandler.Update)
-		categoriesRoute.GET("/:id", middleware.JWTAuth(), categoryHandler.GetById)
-		categoriesRoute.DELETE("/:id", middleware.JWTAuth(), categoryHandler.Delete)
+		categoriesRoute.POST("/", middleware.JWTAuth(), middleware.RequirePermission(rbac.CategoriesWrite), categoryHandler.Create)
+		categoriesRoute.PATCH("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.CategoriesWrite), categoryHandler.Update)
+		categoriesRoute.GET("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.CategoriesRead), categoryHandler.GetById)
+		categoriesRoute.DELETE("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.CategoriesWrite), categoryHandler.Delete)
 	}
 }
@@ -4,22 +4,31 @@ import (
 	configs "github.com/devbenho/luka-platform/configs"
 	"github.com/devbenho/luka-platform/internal/inventory/repositories"
 	"github.com/devbenho/luka-platform/internal/inventory/services"
+	ownership "github.com/devbenho/luka-platform/internal/ownership/services"
+	productRepo "github.com/devbenho/luka-platform/internal/product/repositories"
+	storeRepo "github.com/devbenho/luka-platform/internal/store/repositories"
+	warehouseRepo "github.com/devbenho/luka-platform/internal/warehouse/repositories"
 	"github.com/devbenho/luka-platform/pkg/database"
 	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
 	"github.com/devbenho/luka-platform/pkg/validation"
 	"github.com/gin-gonic/gin"
 )
 
 func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config) {
 	inventoryRepo := repositories.NewInventoryRepository(mongoDb)
-	inventorySvc := services.NewInventoryService(inventoryRepo, validator)
+	storeRepo := storeRepo.NewStoreRepository(mongoDb)
+	productRepo := productRepo.NewProductRepository(mongoDb)
+	warehouseRepo := warehouseRepo.NewWarehouseRepository(mongoDb)
+	ownershipSvc := ownership.NewOwnershipService(storeRepo, productRepo, warehouseRepo)
+	inventorySvc := services.NewInventoryService(inventoryRepo, ownershipSvc, validator)
 	inventoryHandler := NewInventoryHandler(inventorySvc)
 
 	inventoriesRoute := r.Group("/inventories")
 	{
-		inventoriesRoute.POST("/", middleware.JWTAuth(), inventoryHandler.Create)
-		inventoriesRoute.PATCH("/:id", middleware.JWTAuth(), inventoryHandler.Update)
-		inventoriesRoute.GET("/:id", middleware.JWTAuth(), inventoryHandler.GetById)
-		inventoriesRoute.DELETE("/:id", middleware.JWTAuth(), inventoryHandler.Delete)
+		inventoriesRoute.POST("/", middleware.JWTAuth(), middleware.RequirePermission(rbac.InventoryWrite), inventoryHandler.Create)
+		inventoriesRoute.PATCH("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.InventoryWrite), inventoryHandler.Update)
+		inventoriesRoute.GET("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.InventoryRead), inventoryHandler.GetById)
+		inventoriesRoute.DELETE("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.InventoryWrite), inventoryHandler.Delete)
 	}
 }
@@ -0,0 +1,91 @@
+package invoices
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/devbenho/luka-platform/internal/invoices/renderers"
+	"github.com/devbenho/luka-platform/internal/invoices/services"
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/internal/utils"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	errors "github.com/devbenho/luka-platform/ports/http/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler struct {
+	service services.IInvoiceService
+}
+
+func NewInvoiceHandler(service services.IInvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{
+		service: service,
+	}
+}
+
+// @Summary Get the invoice of an order
+// @Description Get the invoice issued when the order was processed, as a PDF (default), an HTML page or JSON
+// @Tags invoices
+// @Produce application/pdf
+// @Produce text/html
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param format query string false "Document format" Enums(pdf, html, json)
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Security BearerAuth
+// @Router /orders/{id}/invoice [get]
+func (h *InvoiceHandler) GetByOrder(c *gin.Context) {
+	orderID := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	format := c.DefaultQuery("format", "pdf")
+	if format != "pdf" && format != "html" && format != "json" {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", "format must be one of pdf, html or json"))
+		return
+	}
+
+	invoice, err := h.service.GetInvoiceByOrder(c.Request.Context(), orderID, actor)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	if format == "json" {
+		response := utils.NewSuccessResponse(http.StatusOK, "Invoice fetched successfully", invoice)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	var document bytes.Buffer
+	contentType := "application/pdf"
+	render := renderers.RenderPDF
+	if format == "html" {
+		contentType = "text/html; charset=utf-8"
+		render = renderers.RenderHTML
+	}
+	if err := render(&document, invoice); err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+invoice.Number+"."+format+`"`)
+	c.Data(http.StatusOK, contentType, document.Bytes())
+}
+
+func actorFromContext(c *gin.Context) (orderModels.Actor, bool) {
+	userID, role, ok := middleware.CurrentUser(c)
+	if !ok {
+		return orderModels.Actor{}, false
+	}
+	actor, err := orderModels.NewActor(userID, role)
+	return actor, err == nil
+}
@@ -0,0 +1,33 @@
+package invoices
+
+import (
+	configs "github.com/devbenho/luka-platform/configs"
+	"github.com/devbenho/luka-platform/internal/invoices/repositories"
+	"github.com/devbenho/luka-platform/internal/invoices/services"
+	orderRepo "github.com/devbenho/luka-platform/internal/orders/repositories"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	productRepo "github.com/devbenho/luka-platform/internal/product/repositories"
+	storeRepo "github.com/devbenho/luka-platform/internal/store/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config, ownershipService ownershipSvc.IOwnershipService) {
+	// Initialize repositories
+	invoiceRepository := repositories.NewInvoiceRepository(mongoDb)
+	orderRepository := orderRepo.NewOrderRepository(mongoDb)
+	storeRepository := storeRepo.NewStoreRepository(mongoDb)
+	productRepository := productRepo.NewProductRepository(mongoDb)
+
+	// Initialize service
+	invoiceService := services.NewInvoiceService(mongoDb, invoiceRepository, orderRepository, storeRepository, productRepository, ownershipService)
+
+	// Initialize handler
+	invoiceHandler := NewInvoiceHandler(invoiceService)
+
+	// Define routes
+	r.GET("/orders/:id/invoice", middleware.JWTAuth(), middleware.RequirePermission(rbac.InvoicesRead), invoiceHandler.GetByOrder)
+}
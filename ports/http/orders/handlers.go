@@ -7,6 +7,7 @@ import (
 	dtos "github.com/devbenho/luka-platform/internal/orders/order_dtos"
 	"github.com/devbenho/luka-platform/internal/orders/services"
 	"github.com/devbenho/luka-platform/internal/utils"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
 	errors "github.com/devbenho/luka-platform/ports/http/errors"
 	"github.com/gin-gonic/gin"
 )
@@ -22,22 +23,30 @@ func NewOrderHandler(service services.IOrderService) *OrderHandler {
 }
 
 // @Summary Create a new order
-// @Description Create a new order with the provided details
+// @Description Place a checkout, split into one order per store selling the items
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param order body dtos.CreateOrderRequest true "Order details"
+// @Param Idempotency-Key header string false "Key making retries of this request safe"
 // @Success 201 {object} utils.Response
 // @Failure 400 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Security BearerAuth
 // @Router /orders [post]
 func (h *OrderHandler) Create(c *gin.Context) {
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
 	var createOrderRequest dtos.CreateOrderRequest
 	if err := c.ShouldBindJSON(&createOrderRequest); err != nil {
 		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
 		return
 	}
+	createOrderRequest.CustomerID = actor.ID
 
 	result, err := h.service.CreateOrder(c.Request.Context(), createOrderRequest)
 	if err != nil {
@@ -56,7 +65,7 @@ func (h *OrderHandler) Create(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Order ID"
-// @Param status body models.OrderStatus true "New order status"
+// @Param status body dtos.UpdateOrderStatusRequest true "New order status"
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} utils.Response
 // @Failure 404 {object} utils.Response
@@ -65,13 +74,19 @@ func (h *OrderHandler) Create(c *gin.Context) {
 // @Router /orders/{id}/status [patch]
 func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 	id := c.Param("id")
-	var status models.OrderStatus
-	if err := c.ShouldBindJSON(&status); err != nil {
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var updateStatusRequest dtos.UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&updateStatusRequest); err != nil {
 		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid status", err.Error()))
 		return
 	}
 
-	err := h.service.UpdateOrderStatus(c.Request.Context(), id, status)
+	err := h.service.UpdateOrderStatus(c.Request.Context(), id, actor, updateStatusRequest)
 	if err != nil {
 		apiError := errors.MapErrorToHTTP(err)
 		c.JSON(apiError.Status, apiError)
@@ -82,47 +97,199 @@ func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// @Summary Cancel an order
+// @Description Cancel an order, return its items to stock and record the reason
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param cancellation body dtos.CancelOrderRequest true "Cancellation reason"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /orders/{id}/cancel [post]
+func (h *OrderHandler) Cancel(c *gin.Context) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var cancelOrderRequest dtos.CancelOrderRequest
+	if err := c.ShouldBindJSON(&cancelOrderRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	order, err := h.service.CancelOrder(c.Request.Context(), id, actor, cancelOrderRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Order cancelled successfully", order)
+	c.JSON(http.StatusOK, response)
+}
+
 // @Summary Get order by ID
 // @Description Get detailed information about a specific order
 // @Tags orders
 // @Produce json
 // @Param id path string true "Order ID"
+// @Param include query string false "Set to history to include the status history"
 // @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Failure 404 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Security BearerAuth
 // @Router /orders/{id} [get]
 func (h *OrderHandler) GetById(c *gin.Context) {
 	id := c.Param("id")
-	order, err := h.service.GetOrderByID(c.Request.Context(), id)
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	order, err := h.service.GetOrder(c.Request.Context(), id, actor)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	details := dtos.OrderDetailsResponse{Order: *order}
+	if c.Query("include") == "history" {
+		details.History, err = h.service.GetOrderHistory(c.Request.Context(), id, actor)
+		if err != nil {
+			apiError := errors.MapErrorToHTTP(err)
+			c.JSON(apiError.Status, apiError)
+			return
+		}
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Order fetched successfully", details)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get order status history
+// @Description Get every status change of an order, oldest first
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /orders/{id}/history [get]
+func (h *OrderHandler) History(c *gin.Context) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	history, err := h.service.GetOrderHistory(c.Request.Context(), id, actor)
 	if err != nil {
 		apiError := errors.MapErrorToHTTP(err)
 		c.JSON(apiError.Status, apiError)
 		return
 	}
 
-	response := utils.NewSuccessResponse(http.StatusOK, "Order fetched successfully", order)
+	response := utils.NewSuccessResponse(http.StatusOK, "Order history fetched successfully", history)
 	c.JSON(http.StatusOK, response)
 }
 
 // @Summary List orders
-// @Description Get a list of orders, optionally filtered by customer ID
+// @Description Get a page of orders: your own, or those of a store you own when filtering by store
 // @Tags orders
 // @Produce json
 // @Param customer_id query string false "Filter orders by customer ID"
-// @Success 200 {object} utils.Response
+// @Param store_id query string false "Filter orders by store ID"
+// @Param status query []string false "Filter by status; repeat or comma separate for several" collectionFormat(multi)
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created before (RFC 3339)"
+// @Param currency query string false "Currency of the total range; required with min_total, max_total or sort=total"
+// @Param min_total query string false "Minimum total in major units"
+// @Param max_total query string false "Maximum total in major units"
+// @Param sort query string false "Sort key" Enums(created_at, updated_at, total)
+// @Param order query string false "Sort direction" Enums(asc, desc)
+// @Param limit query int false "Page size, 1 to 100 (default 20)"
+// @Param offset query int false "Orders to skip; not combined with cursor"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param include_total query bool false "Count the matching orders"
+// @Success 200 {object} utils.PagedResponse
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Security BearerAuth
 // @Router /orders [get]
 func (h *OrderHandler) List(c *gin.Context) {
-	customerID := c.Query("customer_id")
-	orders, err := h.service.ListOrders(c.Request.Context(), customerID)
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var listOrdersQuery dtos.ListOrdersQuery
+	if err := c.ShouldBindQuery(&listOrdersQuery); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	orders, page, err := h.service.ListOrders(c.Request.Context(), actor, listOrdersQuery)
 	if err != nil {
 		apiError := errors.MapErrorToHTTP(err)
 		c.JSON(apiError.Status, apiError)
 		return
 	}
 
-	response := utils.NewSuccessResponse(http.StatusOK, "Orders fetched successfully", orders)
+	response := utils.NewPagedResponse(http.StatusOK, "Orders fetched successfully", orders, page)
 	c.JSON(http.StatusOK, response)
 }
+
+// @Summary Get checkout by ID
+// @Description Get a checkout together with the per-store orders it was split into
+// @Tags orders
+// @Produce json
+// @Param id path string true "Checkout ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /checkouts/{id} [get]
+func (h *OrderHandler) GetCheckout(c *gin.Context) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	checkout, err := h.service.GetCheckout(c.Request.Context(), id, actor)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Checkout fetched successfully", checkout)
+	c.JSON(http.StatusOK, response)
+}
+
+// actorFromContext returns the authenticated user set by the JWT middleware
+func actorFromContext(c *gin.Context) (models.Actor, bool) {
+	userID, role, ok := middleware.CurrentUser(c)
+	if !ok {
+		return models.Actor{}, false
+	}
+	actor, err := models.NewActor(userID, role)
+	return actor, err == nil
+}
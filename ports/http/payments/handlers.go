@@ -0,0 +1,279 @@
+package payments
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/internal/payments/dtos"
+	"github.com/devbenho/luka-platform/internal/payments/models"
+	"github.com/devbenho/luka-platform/internal/payments/services"
+	"github.com/devbenho/luka-platform/internal/payments/webhooks"
+	"github.com/devbenho/luka-platform/internal/utils"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	errors "github.com/devbenho/luka-platform/ports/http/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	service services.IPaymentService
+}
+
+func NewPaymentHandler(service services.IPaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		service: service,
+	}
+}
+
+// @Summary Pay for an order
+// @Description Authorize the order total on the buyer's payment method, optionally capturing it at once
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param payment body dtos.AuthorizePaymentRequest true "Payment method"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 402 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /orders/{id}/payments [post]
+func (h *PaymentHandler) Authorize(c *gin.Context) {
+	orderID := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var authorizePaymentRequest dtos.AuthorizePaymentRequest
+	if err := c.ShouldBindJSON(&authorizePaymentRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.AuthorizePayment(c.Request.Context(), orderID, actor, authorizePaymentRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusCreated, "Payment authorized successfully", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary List payments of an order
+// @Description Get every payment attempt made for an order
+// @Tags payments
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Security BearerAuth
+// @Router /orders/{id}/payments [get]
+func (h *PaymentHandler) ListByOrder(c *gin.Context) {
+	orderID := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	result, err := h.service.ListPaymentsByOrder(c.Request.Context(), orderID, actor)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Payments fetched successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get payment by ID
+// @Description Get a payment and its provider transactions
+// @Tags payments
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Security BearerAuth
+// @Router /payments/{id} [get]
+func (h *PaymentHandler) GetById(c *gin.Context) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	result, err := h.service.GetPaymentByID(c.Request.Context(), id, actor)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Payment fetched successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Capture a payment
+// @Description Take the authorized money and start processing the order
+// @Tags payments
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} utils.Response
+// @Failure 402 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /payments/{id}/capture [post]
+func (h *PaymentHandler) Capture(c *gin.Context) {
+	h.act(c, h.service.CapturePayment, "Payment captured successfully")
+}
+
+// @Summary Void a payment
+// @Description Release authorized money that was not captured
+// @Tags payments
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /payments/{id}/void [post]
+func (h *PaymentHandler) Void(c *gin.Context) {
+	h.act(c, h.service.VoidPayment, "Payment voided successfully")
+}
+
+// @Summary Refund a payment
+// @Description Give back some or all of the captured money
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param refund body dtos.RefundPaymentRequest false "Refund amount"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /payments/{id}/refund [post]
+func (h *PaymentHandler) Refund(c *gin.Context) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var refundPaymentRequest dtos.RefundPaymentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&refundPaymentRequest); err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+			return
+		}
+	}
+
+	result, err := h.service.RefundPayment(c.Request.Context(), id, actor, refundPaymentRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Payment refunded successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+type actionFunc func(ctx context.Context, id string, actor orderModels.Actor) (*models.Payment, error)
+
+func (h *PaymentHandler) act(c *gin.Context, action actionFunc, message string) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	result, err := action(c.Request.Context(), id, actor)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, message, result)
+	c.JSON(http.StatusOK, response)
+}
+
+func actorFromContext(c *gin.Context) (orderModels.Actor, bool) {
+	userID, role, ok := middleware.CurrentUser(c)
+	if !ok {
+		return orderModels.Actor{}, false
+	}
+	actor, err := orderModels.NewActor(userID, role)
+	return actor, err == nil
+}
+
+// maxWebhookPayload bounds the size of a webhook body. Provider events are a
+// few kilobytes; anything larger is refused before it is read into memory.
+const maxWebhookPayload = 1 << 20
+
+type WebhookHandler struct {
+	service services.IWebhookService
+}
+
+func NewWebhookHandler(service services.IWebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+	}
+}
+
+// @Summary Receive a payment webhook
+// @Description Verify, deduplicate and apply a signed event sent by a payment provider
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param provider path string true "Payment provider"
+// @Param X-Webhook-Signature header string true "t=<unix seconds>,v1=<hex HMAC-SHA256>"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 413 {object} utils.Response
+// @Failure 422 {object} utils.Response
+// @Router /webhooks/payments/{provider} [post]
+func (h *WebhookHandler) Receive(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookPayload)
+	payload, err := c.GetRawData()
+	var tooLarge *http.MaxBytesError
+	if stdErrors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, utils.NewErrorResponse(http.StatusRequestEntityTooLarge, "Payload too large", err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	event, duplicate, err := h.service.HandleEvent(c.Request.Context(), c.Param("provider"), payload, c.GetHeader(webhooks.SignatureHeader))
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	message := "Webhook processed successfully"
+	if duplicate {
+		message = "Webhook already processed"
+	}
+	response := utils.NewSuccessResponse(http.StatusOK, message, event)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,43 @@
+package payments
+
+import (
+	configs "github.com/devbenho/luka-platform/configs"
+	orderSvc "github.com/devbenho/luka-platform/internal/orders/services"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	paymentProviders "github.com/devbenho/luka-platform/internal/payments/providers"
+	paymentRepo "github.com/devbenho/luka-platform/internal/payments/repositories"
+	paymentSvc "github.com/devbenho/luka-platform/internal/payments/services"
+	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config, orderService orderSvc.IOrderService, ownershipService ownershipSvc.IOwnershipService, paymentProvider paymentProviders.IPaymentProvider) {
+	// Initialize repositories
+	paymentRepository := paymentRepo.NewPaymentRepository(mongoDb)
+	webhookEventRepository := paymentRepo.NewWebhookEventRepository(mongoDb)
+	// Initialize services
+	paymentService := paymentSvc.NewPaymentService(mongoDb, paymentRepository, paymentProvider, orderService, ownershipService, validator)
+	webhookService := paymentSvc.NewWebhookService(mongoDb, paymentRepository, webhookEventRepository, orderService, config.Payments.WebhookSecrets, config.Payments.WebhookTolerance)
+
+	// Initialize handler
+	paymentHandler := NewPaymentHandler(paymentService)
+	webhookHandler := NewWebhookHandler(webhookService)
+
+	// Define routes
+	r.POST("/orders/:id/payments", middleware.JWTAuth(), middleware.RequirePermission(rbac.PaymentsCreate), paymentHandler.Authorize)
+	r.GET("/orders/:id/payments", middleware.JWTAuth(), middleware.RequirePermission(rbac.PaymentsRead), paymentHandler.ListByOrder)
+
+	paymentsRoute := r.Group("/payments")
+	{
+		paymentsRoute.GET("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.PaymentsRead), paymentHandler.GetById)
+		paymentsRoute.POST("/:id/capture", middleware.JWTAuth(), middleware.RequirePermission(rbac.PaymentsManage), paymentHandler.Capture)
+		paymentsRoute.POST("/:id/refund", middleware.JWTAuth(), middleware.RequirePermission(rbac.PaymentsManage), paymentHandler.Refund)
+		paymentsRoute.POST("/:id/void", middleware.JWTAuth(), middleware.RequirePermission(rbac.PaymentsManage), paymentHandler.Void)
+	}
+
+	// Providers authenticate webhooks with a signature instead of a token.
+	r.POST("/webhooks/payments/:provider", webhookHandler.Receive)
+}
@@ -25,6 +25,7 @@ func NewProductHandler(service services.IProductService) *ProductHandler {
 // @Accept json
 // @Produce json
 // @Param product body dtos.CreateProductRequest true "Product Data"
+// @Param Idempotency-Key header string false "Key making retries of this request safe"
 // @Success 201 {object} utils.Response
 // @Failure 400 {object} utils.Response
 // @Failure 500 {object} utils.Response
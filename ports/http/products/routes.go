@@ -2,11 +2,14 @@ package products
 
 import (
 	configs "github.com/devbenho/luka-platform/configs"
+	ownership "github.com/devbenho/luka-platform/internal/ownership/services"
 	"github.com/devbenho/luka-platform/internal/product/repositories"
 	"github.com/devbenho/luka-platform/internal/product/services"
 	storeRepo "github.com/devbenho/luka-platform/internal/store/repositories"
+	warehouseRepo "github.com/devbenho/luka-platform/internal/warehouse/repositories"
 	"github.com/devbenho/luka-platform/pkg/database"
 	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
 	"github.com/devbenho/luka-platform/pkg/validation"
 	"github.com/gin-gonic/gin"
 )
@@ -14,14 +17,16 @@ import (
 func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config) {
 	productRepo := repositories.NewProductRepository(mongoDb)
 	storeRepo := storeRepo.NewStoreRepository(mongoDb)
-	productSvc := services.NewProductService(productRepo, storeRepo, validator)
+	warehouseRepo := warehouseRepo.NewWarehouseRepository(mongoDb)
+	ownershipSvc := ownership.NewOwnershipService(storeRepo, productRepo, warehouseRepo)
+	productSvc := services.NewProductService(productRepo, ownershipSvc, validator)
 	productHandler := NewProductHandler(productSvc)
 
 	productsRoute := r.Group("/products")
 	{
-		productsRoute.POST("/", middleware.JWTAuth(), productHandler.Create)
-		productsRoute.PATCH("/:id", middleware.JWTAuth(), productHandler.Update)
-		productsRoute.GET("/:id", middleware.JWTAuth(), productHandler.GetById)
-		productsRoute.DELETE("/:id", middleware.JWTAuth(), productHandler.Delete)
+		productsRoute.POST("/", middleware.JWTAuth(), middleware.RequirePermission(rbac.ProductsWrite), middleware.Idempotency(mongoDb, config.Idempotency.TTL), productHandler.Create)
+		productsRoute.PATCH("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.ProductsWrite), productHandler.Update)
+		productsRoute.GET("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.ProductsRead), productHandler.GetById)
+		productsRoute.DELETE("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.ProductsWrite), productHandler.Delete)
 	}
 }
@@ -0,0 +1,125 @@
+package promotions
+
+import (
+	"net/http"
+
+	"github.com/devbenho/luka-platform/internal/promotions/dtos"
+	"github.com/devbenho/luka-platform/internal/promotions/services"
+	"github.com/devbenho/luka-platform/internal/utils"
+	"github.com/devbenho/luka-platform/pkg/database"
+	errors "github.com/devbenho/luka-platform/ports/http/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type CouponHandler struct {
+	service services.IPromotionService
+}
+
+func NewCouponHandler(service services.IPromotionService) *CouponHandler {
+	return &CouponHandler{
+		service: service,
+	}
+}
+
+// @Summary Create a coupon
+// @Description Create a percentage, fixed amount or free shipping coupon code
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param coupon body dtos.CreateCouponRequest true "Coupon details"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /coupons [post]
+func (h *CouponHandler) Create(c *gin.Context) {
+	var createCouponRequest dtos.CreateCouponRequest
+	if err := c.ShouldBindJSON(&createCouponRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.CreateCoupon(c.Request.Context(), &createCouponRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusCreated, "Coupon created successfully", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary List coupons
+// @Description Get a page of coupons with their usage counts, newest first
+// @Tags promotions
+// @Produce json
+// @Param limit query int false "Page size, 1 to 100 (default 20)"
+// @Param offset query int false "Items to skip; not combined with cursor"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param include_total query bool false "Count all items"
+// @Success 200 {object} utils.PagedResponse
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /coupons [get]
+func (h *CouponHandler) List(c *gin.Context) {
+	var pageRequest database.PageRequest
+	if err := c.ShouldBindQuery(&pageRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	coupons, page, err := h.service.ListCoupons(c.Request.Context(), pageRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewPagedResponse(http.StatusOK, "Coupons fetched successfully", coupons, page)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get coupon by code
+// @Description Get a coupon by its code
+// @Tags promotions
+// @Produce json
+// @Param code path string true "Coupon code"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Security BearerAuth
+// @Router /coupons/{code} [get]
+func (h *CouponHandler) GetByCode(c *gin.Context) {
+	code := c.Param("code")
+	coupon, err := h.service.GetCouponByCode(c.Request.Context(), code)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Coupon fetched successfully", coupon)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Deactivate a coupon
+// @Description Stop a coupon from being used on new orders
+// @Tags promotions
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Success 200 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /coupons/{id}/deactivate [post]
+func (h *CouponHandler) Deactivate(c *gin.Context) {
+	id := c.Param("code")
+	if err := h.service.DeactivateCoupon(c.Request.Context(), id); err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Coupon deactivated successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
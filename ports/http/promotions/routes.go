@@ -0,0 +1,29 @@
+package promotions
+
+import (
+	configs "github.com/devbenho/luka-platform/configs"
+	"github.com/devbenho/luka-platform/internal/promotions/repositories"
+	"github.com/devbenho/luka-platform/internal/promotions/services"
+	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config) {
+	couponRepo := repositories.NewCouponRepository(mongoDb)
+	redemptionRepo := repositories.NewRedemptionRepository(mongoDb)
+	promotionSvc := services.NewPromotionService(couponRepo, redemptionRepo, validator)
+	couponHandler := NewCouponHandler(promotionSvc)
+
+	// Gin needs one wildcard name per path segment, so the coupon ID of the
+	// deactivate route shares the :code parameter.
+	couponsRoute := r.Group("/coupons")
+	{
+		couponsRoute.POST("/", middleware.JWTAuth(), middleware.RequirePermission(rbac.CouponsWrite), couponHandler.Create)
+		couponsRoute.GET("/", middleware.JWTAuth(), middleware.RequirePermission(rbac.CouponsRead), couponHandler.List)
+		couponsRoute.GET("/:code", middleware.JWTAuth(), middleware.RequirePermission(rbac.CouponsRead), couponHandler.GetByCode)
+		couponsRoute.POST("/:code/deactivate", middleware.JWTAuth(), middleware.RequirePermission(rbac.CouponsWrite), couponHandler.Deactivate)
+	}
+}
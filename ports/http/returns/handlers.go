@@ -0,0 +1,229 @@
+package returns
+
+import (
+	"context"
+	"net/http"
+
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/internal/returns/dtos"
+	"github.com/devbenho/luka-platform/internal/returns/models"
+	"github.com/devbenho/luka-platform/internal/returns/services"
+	"github.com/devbenho/luka-platform/internal/utils"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	errors "github.com/devbenho/luka-platform/ports/http/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type ReturnHandler struct {
+	service services.IReturnService
+}
+
+func NewReturnHandler(service services.IReturnService) *ReturnHandler {
+	return &ReturnHandler{
+		service: service,
+	}
+}
+
+// @Summary Request a return
+// @Description Open a return for some or all items of a delivered order
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param return body dtos.CreateReturnRequest true "Items to return"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /orders/{id}/returns [post]
+func (h *ReturnHandler) Create(c *gin.Context) {
+	orderID := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var createReturnRequest dtos.CreateReturnRequest
+	if err := c.ShouldBindJSON(&createReturnRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.CreateReturn(c.Request.Context(), orderID, actor, createReturnRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusCreated, "Return requested successfully", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary List returns of an order
+// @Description Get every return opened for an order
+// @Tags returns
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Security BearerAuth
+// @Router /orders/{id}/returns [get]
+func (h *ReturnHandler) ListByOrder(c *gin.Context) {
+	orderID := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	result, err := h.service.ListReturnsByOrder(c.Request.Context(), orderID, actor)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Returns fetched successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get return by ID
+// @Description Get a return request and its refund amount
+// @Tags returns
+// @Produce json
+// @Param id path string true "Return ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Security BearerAuth
+// @Router /returns/{id} [get]
+func (h *ReturnHandler) GetById(c *gin.Context) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	result, err := h.service.GetReturnByID(c.Request.Context(), id, actor)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Return fetched successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Approve a return
+// @Description Approve a requested return so the buyer can send the items back
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Return ID"
+// @Param review body dtos.ReviewReturnRequest false "Review note"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /returns/{id}/approve [post]
+func (h *ReturnHandler) Approve(c *gin.Context) {
+	h.review(c, h.service.ApproveReturn, "Return approved successfully")
+}
+
+// @Summary Reject a return
+// @Description Reject a requested return
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Return ID"
+// @Param review body dtos.ReviewReturnRequest false "Review note"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /returns/{id}/reject [post]
+func (h *ReturnHandler) Reject(c *gin.Context) {
+	h.review(c, h.service.RejectReturn, "Return rejected successfully")
+}
+
+// @Summary Receive a return
+// @Description Restock the returned items into a warehouse and update the order status
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Return ID"
+// @Param receipt body dtos.ReceiveReturnRequest true "Receiving warehouse"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /returns/{id}/receive [post]
+func (h *ReturnHandler) Receive(c *gin.Context) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var receiveReturnRequest dtos.ReceiveReturnRequest
+	if err := c.ShouldBindJSON(&receiveReturnRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.ReceiveReturn(c.Request.Context(), id, actor, receiveReturnRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Return received successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+type reviewFunc func(ctx context.Context, id string, actor orderModels.Actor, dto dtos.ReviewReturnRequest) (*models.ReturnRequest, error)
+
+func (h *ReturnHandler) review(c *gin.Context, review reviewFunc, message string) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var reviewReturnRequest dtos.ReviewReturnRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&reviewReturnRequest); err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+			return
+		}
+	}
+
+	result, err := review(c.Request.Context(), id, actor, reviewReturnRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, message, result)
+	c.JSON(http.StatusOK, response)
+}
+
+func actorFromContext(c *gin.Context) (orderModels.Actor, bool) {
+	userID, role, ok := middleware.CurrentUser(c)
+	if !ok {
+		return orderModels.Actor{}, false
+	}
+	actor, err := orderModels.NewActor(userID, role)
+	return actor, err == nil
+}
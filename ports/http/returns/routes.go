@@ -0,0 +1,38 @@
+package returns
+
+import (
+	configs "github.com/devbenho/luka-platform/configs"
+	"github.com/devbenho/luka-platform/internal/inventory/repositories"
+	orderSvc "github.com/devbenho/luka-platform/internal/orders/services"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	returnRepo "github.com/devbenho/luka-platform/internal/returns/repositories"
+	returnSvc "github.com/devbenho/luka-platform/internal/returns/services"
+	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config, orderService orderSvc.IOrderService, ownershipService ownershipSvc.IOwnershipService) {
+	// Initialize repositories
+	returnRepository := returnRepo.NewReturnRepository(mongoDb)
+	inventoryRepository := repositories.NewInventoryRepository(mongoDb)
+	// Initialize services
+	returnService := returnSvc.NewReturnService(mongoDb, returnRepository, inventoryRepository, orderService, ownershipService, validator)
+
+	// Initialize handler
+	returnHandler := NewReturnHandler(returnService)
+
+	// Define routes
+	r.POST("/orders/:id/returns", middleware.JWTAuth(), middleware.RequirePermission(rbac.ReturnsCreate), returnHandler.Create)
+	r.GET("/orders/:id/returns", middleware.JWTAuth(), middleware.RequirePermission(rbac.ReturnsRead), returnHandler.ListByOrder)
+
+	returnsRoute := r.Group("/returns")
+	{
+		returnsRoute.GET("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.ReturnsRead), returnHandler.GetById)
+		returnsRoute.POST("/:id/approve", middleware.JWTAuth(), middleware.RequirePermission(rbac.ReturnsManage), returnHandler.Approve)
+		returnsRoute.POST("/:id/reject", middleware.JWTAuth(), middleware.RequirePermission(rbac.ReturnsManage), returnHandler.Reject)
+		returnsRoute.POST("/:id/receive", middleware.JWTAuth(), middleware.RequirePermission(rbac.ReturnsManage), returnHandler.Receive)
+	}
+}
@@ -0,0 +1,166 @@
+package shipments
+
+import (
+	"net/http"
+
+	orderModels "github.com/devbenho/luka-platform/internal/orders/models"
+	"github.com/devbenho/luka-platform/internal/shipments/dtos"
+	"github.com/devbenho/luka-platform/internal/shipments/services"
+	"github.com/devbenho/luka-platform/internal/utils"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	errors "github.com/devbenho/luka-platform/ports/http/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type ShipmentHandler struct {
+	service services.IShipmentService
+}
+
+func NewShipmentHandler(service services.IShipmentService) *ShipmentHandler {
+	return &ShipmentHandler{
+		service: service,
+	}
+}
+
+// @Summary Ship an order
+// @Description Record the packages of a processed order and mark it as shipped
+// @Tags shipments
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param shipment body dtos.ShipOrderRequest true "Packages"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /orders/{id}/shipments [post]
+func (h *ShipmentHandler) Ship(c *gin.Context) {
+	orderID := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var shipOrderRequest dtos.ShipOrderRequest
+	if err := c.ShouldBindJSON(&shipOrderRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.ShipOrder(c.Request.Context(), orderID, actor, shipOrderRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusCreated, "Order shipped successfully", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary List shipments of an order
+// @Description Get every package an order was shipped in
+// @Tags shipments
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Security BearerAuth
+// @Router /orders/{id}/shipments [get]
+func (h *ShipmentHandler) ListByOrder(c *gin.Context) {
+	orderID := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	result, err := h.service.ListShipmentsByOrder(c.Request.Context(), orderID, actor)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Shipments fetched successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get shipment by ID
+// @Description Get a shipment and its tracking history
+// @Tags shipments
+// @Produce json
+// @Param id path string true "Shipment ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Security BearerAuth
+// @Router /shipments/{id} [get]
+func (h *ShipmentHandler) GetById(c *gin.Context) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	result, err := h.service.GetShipmentByID(c.Request.Context(), id, actor)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Shipment fetched successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Add a tracking event
+// @Description Append a carrier event to a shipment; delivering the last package delivers the order
+// @Tags shipments
+// @Accept json
+// @Produce json
+// @Param id path string true "Shipment ID"
+// @Param event body dtos.AddTrackingEventRequest true "Tracking event"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Security BearerAuth
+// @Router /shipments/{id}/events [post]
+func (h *ShipmentHandler) AddEvent(c *gin.Context) {
+	id := c.Param("id")
+	actor, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var addTrackingEventRequest dtos.AddTrackingEventRequest
+	if err := c.ShouldBindJSON(&addTrackingEventRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.AddTrackingEvent(c.Request.Context(), id, actor, addTrackingEventRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Tracking event added successfully", result)
+	c.JSON(http.StatusOK, response)
+}
+
+func actorFromContext(c *gin.Context) (orderModels.Actor, bool) {
+	userID, role, ok := middleware.CurrentUser(c)
+	if !ok {
+		return orderModels.Actor{}, false
+	}
+	actor, err := orderModels.NewActor(userID, role)
+	return actor, err == nil
+}
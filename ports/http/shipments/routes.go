@@ -0,0 +1,36 @@
+package shipments
+
+import (
+	configs "github.com/devbenho/luka-platform/configs"
+	orderSvc "github.com/devbenho/luka-platform/internal/orders/services"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	shipmentRepo "github.com/devbenho/luka-platform/internal/shipments/repositories"
+	shipmentSvc "github.com/devbenho/luka-platform/internal/shipments/services"
+	warehouseRepo "github.com/devbenho/luka-platform/internal/warehouse/repositories"
+	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config, orderService orderSvc.IOrderService, ownershipService ownershipSvc.IOwnershipService) {
+	// Initialize repositories
+	warehouseRepository := warehouseRepo.NewWarehouseRepository(mongoDb)
+	shipmentRepository := shipmentRepo.NewShipmentRepository(mongoDb)
+	// Initialize services
+	shipmentService := shipmentSvc.NewShipmentService(mongoDb, shipmentRepository, warehouseRepository, orderService, ownershipService, validator)
+
+	// Initialize handler
+	shipmentHandler := NewShipmentHandler(shipmentService)
+
+	// Define routes
+	r.POST("/orders/:id/shipments", middleware.JWTAuth(), middleware.RequirePermission(rbac.OrdersShip), shipmentHandler.Ship)
+	r.GET("/orders/:id/shipments", middleware.JWTAuth(), middleware.RequirePermission(rbac.OrdersRead), shipmentHandler.ListByOrder)
+
+	shipmentsRoute := r.Group("/shipments")
+	{
+		shipmentsRoute.GET("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.OrdersRead), shipmentHandler.GetById)
+		shipmentsRoute.POST("/:id/events", middleware.JWTAuth(), middleware.RequirePermission(rbac.OrdersShip), shipmentHandler.AddEvent)
+	}
+}
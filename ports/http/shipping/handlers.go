@@ -0,0 +1,99 @@
+package shipping
+
+import (
+	"net/http"
+
+	"github.com/devbenho/luka-platform/internal/shipping/dtos"
+	"github.com/devbenho/luka-platform/internal/shipping/services"
+	"github.com/devbenho/luka-platform/internal/utils"
+	errors "github.com/devbenho/luka-platform/ports/http/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type ShippingHandler struct {
+	service services.IShippingService
+}
+
+func NewShippingHandler(service services.IShippingService) *ShippingHandler {
+	return &ShippingHandler{
+		service: service,
+	}
+}
+
+// @Summary Create a shipping method
+// @Description Add a flat, weight-based or distance-based shipping method to a store
+// @Tags shipping
+// @Accept json
+// @Produce json
+// @Param id path string true "Store ID"
+// @Param method body dtos.CreateShippingMethodRequest true "Shipping method"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /stores/{id}/shipping-methods [post]
+func (h *ShippingHandler) Create(c *gin.Context) {
+	var createShippingMethodRequest dtos.CreateShippingMethodRequest
+	if err := c.ShouldBindJSON(&createShippingMethodRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.CreateMethod(c.Request.Context(), c.Param("id"), &createShippingMethodRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusCreated, "Shipping method created successfully", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary List shipping methods
+// @Description Get the shipping methods offered by a store
+// @Tags shipping
+// @Produce json
+// @Param id path string true "Store ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /stores/{id}/shipping-methods [get]
+func (h *ShippingHandler) List(c *gin.Context) {
+	methods, err := h.service.ListMethods(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Shipping methods fetched successfully", methods)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Delete a shipping method
+// @Description Delete a shipping method by ID
+// @Tags shipping
+// @Produce json
+// @Param id path string true "Shipping method ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /shipping-methods/{id} [delete]
+func (h *ShippingHandler) Delete(c *gin.Context) {
+	if err := h.service.DeleteMethod(c.Request.Context(), c.Param("id")); err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Shipping method deleted successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,23 @@
+package shipping
+
+import (
+	configs "github.com/devbenho/luka-platform/configs"
+	ownershipSvc "github.com/devbenho/luka-platform/internal/ownership/services"
+	"github.com/devbenho/luka-platform/internal/shipping/repositories"
+	"github.com/devbenho/luka-platform/internal/shipping/services"
+	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config, ownershipService ownershipSvc.IOwnershipService) {
+	shippingMethodRepo := repositories.NewShippingMethodRepository(mongoDb)
+	shippingSvc := services.NewShippingService(shippingMethodRepo, ownershipService, validator)
+	shippingHandler := NewShippingHandler(shippingSvc)
+
+	r.POST("/stores/:id/shipping-methods", middleware.JWTAuth(), middleware.RequirePermission(rbac.ShippingWrite), shippingHandler.Create)
+	r.GET("/stores/:id/shipping-methods", shippingHandler.List)
+	r.DELETE("/shipping-methods/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.ShippingWrite), shippingHandler.Delete)
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/devbenho/luka-platform/pkg/slug"
 	errors "github.com/devbenho/luka-platform/ports/http/errors"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type StoreHandler struct {
@@ -29,42 +28,25 @@ func NewStoreHandler(service services.IStoreService) *StoreHandler {
 // @Accept json
 // @Produce json
 // @Param store body dtos.CreateStoreRequest true "Store details"
+// @Param Idempotency-Key header string false "Key making retries of this request safe"
 // @Success 201 {object} utils.Response
 // @Failure 400 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Security BearerAuth
 // @Router /stores [post]
 func (h *StoreHandler) Create(c *gin.Context) {
-	value, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, utils.NewErrorResponse(http.StatusUnauthorized, "Unauthorized", "Unauthorized"))
-		return
-	}
-
-	userIdStr, ok := value.(string)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Invalid user ID", "Invalid user ID"))
-		return
-	}
-
-	userId, err := primitive.ObjectIDFromHex(userIdStr)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Invalid user ID format", err.Error()))
-		return
-	}
-
 	var createStoreRequest dtos.CreateStoreRequest
 
 	if err := c.ShouldBindJSON(&createStoreRequest); err != nil {
 		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
 		return
 	}
-	createStoreRequest.OwnerId = userId
 	createStoreRequest.Slug = slug.GenerateSlug(createStoreRequest.Name)
 
 	result, err := h.service.CreateStore(c.Request.Context(), &createStoreRequest)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Failed to register user", err.Error()))
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
 		return
 	}
 
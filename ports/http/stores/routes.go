@@ -2,24 +2,31 @@ package stores
 
 import (
 	configs "github.com/devbenho/luka-platform/configs"
+	ownership "github.com/devbenho/luka-platform/internal/ownership/services"
+	productRepo "github.com/devbenho/luka-platform/internal/product/repositories"
 	"github.com/devbenho/luka-platform/internal/store/repositories"
 	"github.com/devbenho/luka-platform/internal/store/services"
+	warehouseRepo "github.com/devbenho/luka-platform/internal/warehouse/repositories"
 	"github.com/devbenho/luka-platform/pkg/database"
 	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
 	"github.com/devbenho/luka-platform/pkg/validation"
 	"github.com/gin-gonic/gin"
 )
 
 func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config) {
 	storeRepo := repositories.NewStoreRepository(mongoDb)
-	storeSvc := services.NewStoreService(storeRepo, validator)
+	productRepo := productRepo.NewProductRepository(mongoDb)
+	warehouseRepo := warehouseRepo.NewWarehouseRepository(mongoDb)
+	ownershipSvc := ownership.NewOwnershipService(storeRepo, productRepo, warehouseRepo)
+	storeSvc := services.NewStoreService(storeRepo, ownershipSvc, validator)
 	storeHandler := NewStoreHandler(storeSvc)
 
 	storesRoute := r.Group("/stores")
 	{
-		storesRoute.POST("/", middleware.JWTAuth(), storeHandler.Create)
-		storesRoute.PATCH("/:id", middleware.JWTAuth(), storeHandler.Update)
-		storesRoute.GET("/:id", middleware.JWTAuth(), storeHandler.GetById)
-		storesRoute.DELETE("/:id", middleware.JWTAuth(), storeHandler.Delete)
+		storesRoute.POST("/", middleware.JWTAuth(), middleware.RequirePermission(rbac.StoresWrite), middleware.Idempotency(mongoDb, config.Idempotency.TTL), storeHandler.Create)
+		storesRoute.PATCH("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.StoresWrite), storeHandler.Update)
+		storesRoute.GET("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.StoresRead), storeHandler.GetById)
+		storesRoute.DELETE("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.StoresWrite), storeHandler.Delete)
 	}
 }
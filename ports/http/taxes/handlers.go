@@ -0,0 +1,104 @@
+package taxes
+
+import (
+	"net/http"
+
+	"github.com/devbenho/luka-platform/internal/tax/dtos"
+	"github.com/devbenho/luka-platform/internal/tax/services"
+	"github.com/devbenho/luka-platform/internal/utils"
+	"github.com/devbenho/luka-platform/pkg/database"
+	errors "github.com/devbenho/luka-platform/ports/http/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type TaxRuleHandler struct {
+	service services.ITaxRuleService
+}
+
+func NewTaxRuleHandler(service services.ITaxRuleService) *TaxRuleHandler {
+	return &TaxRuleHandler{
+		service: service,
+	}
+}
+
+// @Summary Create a tax rule
+// @Description Create a tax rate for a country, optionally narrowed to a region or category
+// @Tags taxes
+// @Accept json
+// @Produce json
+// @Param rule body dtos.CreateTaxRuleRequest true "Tax rule"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /tax-rules [post]
+func (h *TaxRuleHandler) Create(c *gin.Context) {
+	var createTaxRuleRequest dtos.CreateTaxRuleRequest
+	if err := c.ShouldBindJSON(&createTaxRuleRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.CreateRule(c.Request.Context(), &createTaxRuleRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusCreated, "Tax rule created successfully", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary List tax rules
+// @Description Get a page of the configured tax rules, newest first
+// @Tags taxes
+// @Produce json
+// @Param limit query int false "Page size, 1 to 100 (default 20)"
+// @Param offset query int false "Items to skip; not combined with cursor"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param include_total query bool false "Count all items"
+// @Success 200 {object} utils.PagedResponse
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /tax-rules [get]
+func (h *TaxRuleHandler) List(c *gin.Context) {
+	var pageRequest database.PageRequest
+	if err := c.ShouldBindQuery(&pageRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	rules, page, err := h.service.ListRules(c.Request.Context(), pageRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewPagedResponse(http.StatusOK, "Tax rules fetched successfully", rules, page)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Delete a tax rule
+// @Description Delete a tax rule by ID
+// @Tags taxes
+// @Produce json
+// @Param id path string true "Tax rule ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /tax-rules/{id} [delete]
+func (h *TaxRuleHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.service.DeleteRule(c.Request.Context(), id); err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Tax rule deleted successfully", nil)
+	c.JSON(http.StatusOK, response)
+}
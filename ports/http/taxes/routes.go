@@ -0,0 +1,25 @@
+package taxes
+
+import (
+	configs "github.com/devbenho/luka-platform/configs"
+	"github.com/devbenho/luka-platform/internal/tax/repositories"
+	"github.com/devbenho/luka-platform/internal/tax/services"
+	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config) {
+	taxRuleRepo := repositories.NewTaxRuleRepository(mongoDb)
+	taxRuleSvc := services.NewTaxRuleService(taxRuleRepo, validator)
+	taxRuleHandler := NewTaxRuleHandler(taxRuleSvc)
+
+	taxRulesRoute := r.Group("/tax-rules")
+	{
+		taxRulesRoute.POST("/", middleware.JWTAuth(), middleware.RequirePermission(rbac.TaxesWrite), taxRuleHandler.Create)
+		taxRulesRoute.GET("/", middleware.JWTAuth(), middleware.RequirePermission(rbac.TaxesRead), taxRuleHandler.List)
+		taxRulesRoute.DELETE("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.TaxesWrite), taxRuleHandler.Delete)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	dtos "github.com/devbenho/luka-platform/internal/user/dtos/users"
 	"github.com/devbenho/luka-platform/internal/user/services"
 	"github.com/devbenho/luka-platform/internal/utils"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/ports/http/errors"
 	"github.com/gin-gonic/gin"
 )
 
@@ -75,6 +77,10 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	if authDTO.CartToken == "" {
+		authDTO.CartToken = c.GetHeader("X-Cart-Token")
+	}
+
 	response, err := h.service.Login(c.Request.Context(), &authDTO)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, utils.NewErrorResponse(http.StatusUnauthorized, "Failed to login", err.Error()))
@@ -85,6 +91,69 @@ func (h *UserHandler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// Refresh exchanges a refresh token for a new token pair
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access and refresh token. Each refresh token can be used once; reusing one signs its session out everywhere.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param token body dtos.RefreshTokenDTO true "Refresh token"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /auth/refresh [post]
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var refreshDTO dtos.RefreshTokenDTO
+	if err := c.ShouldBindJSON(&refreshDTO); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	pair, err := h.service.Refresh(c.Request.Context(), &refreshDTO)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.NewSuccessResponse(http.StatusOK, "Tokens refreshed successfully", pair))
+}
+
+// Logout revokes the caller's access token and refresh token
+// @Summary Logout a user
+// @Description Revoke the access token of the request and, when given, the refresh token of the session
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param token body dtos.LogoutDTO false "Refresh token to revoke"
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Security BearerAuth
+// @Router /auth/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	claims, ok := middleware.CurrentClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.NewUnauthorizedResponse("User not authenticated"))
+		return
+	}
+
+	var logoutDTO dtos.LogoutDTO
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&logoutDTO); err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+			return
+		}
+	}
+
+	if err := h.service.Logout(c.Request.Context(), *claims, &logoutDTO); err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.NewSuccessResponse(http.StatusOK, "User logged out successfully", nil))
+}
+
 // GetUserByID handles requests to fetch user details by ID
 // @Summary Get user by ID
 // @Description Get user details by ID
@@ -131,7 +200,8 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 	updatedUser, err := h.service.UpdateUser(c.Request.Context(), id, &updateUserRequest)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.NewErrorResponse(http.StatusInternalServerError, "Failed to update user", err.Error()))
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
 		return
 	}
 
@@ -2,30 +2,38 @@ package users
 
 import (
 	configs "github.com/devbenho/luka-platform/configs"
+	cartRepo "github.com/devbenho/luka-platform/internal/cart/repositories"
+	cartSvc "github.com/devbenho/luka-platform/internal/cart/services"
+	inventorySvc "github.com/devbenho/luka-platform/internal/inventory/services"
 	"github.com/devbenho/luka-platform/internal/user/repositories"
 	"github.com/devbenho/luka-platform/internal/user/services"
 	"github.com/devbenho/luka-platform/pkg/database"
 	"github.com/devbenho/luka-platform/pkg/hasher"
 	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
 	"github.com/devbenho/luka-platform/pkg/tokens"
 	"github.com/devbenho/luka-platform/pkg/validation"
 	"github.com/gin-gonic/gin"
 )
 
-func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config) {
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config, inventoryService inventorySvc.IInventoryService) {
 	userRepo := repositories.NewUserRepository(mongoDb)
-	userSvc := services.NewUserService(validator, tokens.NewTokenService(config.JWT.Secret), userRepo, hasher.NewHasher())
+	tokenRepo := repositories.NewTokenRepository(mongoDb)
+	cartMerger := cartSvc.NewCartMerger(mongoDb, cartRepo.NewCartRepository(mongoDb), inventoryService)
+	userSvc := services.NewUserService(validator, tokens.Default(), userRepo, tokenRepo, hasher.NewHasher(), cartMerger)
 	userHandler := NewUserHandler(userSvc)
 
 	authRoute := r.Group("/auth")
 	{
 		authRoute.POST("/register", userHandler.Register)
 		authRoute.POST("/login", userHandler.Login)
+		authRoute.POST("/refresh", userHandler.Refresh)
+		authRoute.POST("/logout", middleware.JWTAuth(), userHandler.Logout)
 	}
 	userRoute := r.Group("/users")
 	{
-		userRoute.GET("/:id", middleware.JWTAuth(), userHandler.GetUserByID)
-		userRoute.PUT("/:id", middleware.JWTAuth(), middleware.OwnerAuth(), userHandler.UpdateUser)
-		userRoute.DELETE("/:id", middleware.JWTAuth(), middleware.OwnerAuth(), userHandler.DeleteUser)
+		userRoute.GET("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.UsersRead), userHandler.GetUserByID)
+		userRoute.PUT("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.UsersWrite), middleware.OwnerAuth(), userHandler.UpdateUser)
+		userRoute.DELETE("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.UsersWrite), middleware.OwnerAuth(), userHandler.DeleteUser)
 	}
 }
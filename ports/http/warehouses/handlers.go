@@ -0,0 +1,72 @@
+package warehouses
+
+import (
+	"net/http"
+
+	"github.com/devbenho/luka-platform/internal/utils"
+	"github.com/devbenho/luka-platform/internal/warehouse/dtos"
+	"github.com/devbenho/luka-platform/internal/warehouse/services"
+	errors "github.com/devbenho/luka-platform/ports/http/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type WarehouseHandler struct {
+	service services.IWarehouseService
+}
+
+func NewWarehouseHandler(service services.IWarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{
+		service: service,
+	}
+}
+
+// @Summary Create a warehouse
+// @Description Register a store warehouse and its location
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param warehouse body dtos.CreateWarehouseRequest true "Warehouse"
+// @Success 201 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /warehouses [post]
+func (h *WarehouseHandler) Create(c *gin.Context) {
+	var createWarehouseRequest dtos.CreateWarehouseRequest
+	if err := c.ShouldBindJSON(&createWarehouseRequest); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewErrorResponse(http.StatusBadRequest, "Invalid input", err.Error()))
+		return
+	}
+
+	result, err := h.service.CreateWarehouse(c.Request.Context(), &createWarehouseRequest)
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusCreated, "Warehouse created successfully", result)
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary Get a warehouse
+// @Description Get a warehouse by ID
+// @Tags warehouses
+// @Produce json
+// @Param id path string true "Warehouse ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Security BearerAuth
+// @Router /warehouses/{id} [get]
+func (h *WarehouseHandler) GetByID(c *gin.Context) {
+	warehouse, err := h.service.GetWarehouseByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apiError := errors.MapErrorToHTTP(err)
+		c.JSON(apiError.Status, apiError)
+		return
+	}
+
+	response := utils.NewSuccessResponse(http.StatusOK, "Warehouse fetched successfully", warehouse)
+	c.JSON(http.StatusOK, response)
+}
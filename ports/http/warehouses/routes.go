@@ -0,0 +1,30 @@
+package warehouses
+
+import (
+	configs "github.com/devbenho/luka-platform/configs"
+	ownership "github.com/devbenho/luka-platform/internal/ownership/services"
+	productRepo "github.com/devbenho/luka-platform/internal/product/repositories"
+	storeRepo "github.com/devbenho/luka-platform/internal/store/repositories"
+	"github.com/devbenho/luka-platform/internal/warehouse/repositories"
+	"github.com/devbenho/luka-platform/internal/warehouse/services"
+	"github.com/devbenho/luka-platform/pkg/database"
+	middleware "github.com/devbenho/luka-platform/pkg/middlewares"
+	"github.com/devbenho/luka-platform/pkg/rbac"
+	"github.com/devbenho/luka-platform/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, mongoDb database.IDatabase, validator *validation.Validator, config configs.Config) {
+	warehouseRepo := repositories.NewWarehouseRepository(mongoDb)
+	storeRepo := storeRepo.NewStoreRepository(mongoDb)
+	productRepo := productRepo.NewProductRepository(mongoDb)
+	ownershipSvc := ownership.NewOwnershipService(storeRepo, productRepo, warehouseRepo)
+	warehouseSvc := services.NewWarehouseService(warehouseRepo, ownershipSvc, validator)
+	warehouseHandler := NewWarehouseHandler(warehouseSvc)
+
+	warehousesRoute := r.Group("/warehouses")
+	{
+		warehousesRoute.POST("/", middleware.JWTAuth(), middleware.RequirePermission(rbac.WarehousesWrite), warehouseHandler.Create)
+		warehousesRoute.GET("/:id", middleware.JWTAuth(), middleware.RequirePermission(rbac.WarehousesRead), warehouseHandler.GetByID)
+	}
+}
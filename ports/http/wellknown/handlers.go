@@ -0,0 +1,33 @@
+package wellknown
+
+import (
+	"net/http"
+
+	"github.com/devbenho/luka-platform/pkg/tokens"
+	"github.com/gin-gonic/gin"
+)
+
+// jwksMaxAge is how long verifiers may cache the key set. New keys are
+// published well before they sign, so it must stay below JWT_KEY_PUBLISH_LEAD.
+const jwksMaxAge = "max-age=300"
+
+type WellKnownHandler struct {
+	tokenService *tokens.TokenService
+}
+
+func NewWellKnownHandler(tokenService *tokens.TokenService) *WellKnownHandler {
+	return &WellKnownHandler{
+		tokenService: tokenService,
+	}
+}
+
+// @Summary Get the JSON Web Key Set
+// @Description Get the public keys access tokens are signed with, including the next key to sign. The set is returned as is, without the response envelope, as RFC 7517 verifiers expect.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} tokens.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *WellKnownHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, "+jwksMaxAge)
+	c.JSON(http.StatusOK, h.tokenService.JWKS())
+}
@@ -0,0 +1,14 @@
+package wellknown
+
+import (
+	"github.com/devbenho/luka-platform/pkg/tokens"
+	"github.com/gin-gonic/gin"
+)
+
+// Routes serves the well-known documents other services discover us by. r
+// must be mounted at /.well-known, outside the API prefix.
+func Routes(r *gin.RouterGroup, tokenService *tokens.TokenService) {
+	wellKnownHandler := NewWellKnownHandler(tokenService)
+
+	r.GET("/jwks.json", wellKnownHandler.GetJWKS)
+}